@@ -0,0 +1,31 @@
+package v1alpha1
+
+// CompositeSpec combines the secrets of several SecretClasses in one volume,
+// e.g. a keytab, krb5.conf and TLS certificates for a Kerberized TLS service.
+// Every SecretClass is checked against the cross-namespace policy of the volume as if it was mounted directly.
+type CompositeSpec struct {
+	// SecretClasses whose secrets are combined, files of different SecretClasses must not have the same path.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	SecretClasses []CompositeSecretClassSpec `json:"secretClasses"`
+}
+
+type CompositeSecretClassSpec struct {
+	// Name of the SecretClass.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Subdirectory of the volume the files of the SecretClass are written to, relative to the volume.
+	// +kubebuilder:validation:Optional
+	Directory string `json:"directory,omitempty"`
+
+	// Prefix of the file names of the SecretClass, e.g. `kafka-` for `kafka-tls.crt`.
+	// +kubebuilder:validation:Optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Format of the secrets of the SecretClass, overriding the format of the volume.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=tls-pem;tls-p12;tls-truststore;kerberos
+	Format string `json:"format,omitempty"`
+}
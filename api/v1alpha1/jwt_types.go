@@ -0,0 +1,68 @@
+package v1alpha1
+
+// JWTSpec issues JSON Web Tokens for workloads, signed by a key set of the SecretClass.
+// The volume gets a token whose claims identify the pod namespace, service account and scope of the volume,
+// services verify tokens with the JWKS document published to a ConfigMap.
+type JWTSpec struct {
+	// Value of the `iss` claim of the tokens.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Issuer string `json:"issuer"`
+
+	// Values of the `aud` claim of the tokens.
+	// +kubebuilder:validation:Optional
+	Audiences []string `json:"audiences,omitempty"`
+
+	// Lifetime of the tokens. The token of a volume is not refreshed, pods are restarted when 80% of
+	// the token lifetime has passed, e.g. about every 19h with the default of 24h.
+	// Short lifetimes restart pods often, every 48m with a lifetime of 1h.
+	// Use time.ParseDuration to parse the string
+	// Default is 24h
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="24h"
+	TokenLifeTime string `json:"tokenLifeTime,omitempty"`
+
+	// Configures the key set used to sign the tokens.
+	// +kubebuilder:validation:Required
+	SigningKeys *JWTSigningKeysSpec `json:"signingKeys"`
+
+	// Reference to the ConfigMap where the public keys are published as JWKS document in the `jwks.json` key.
+	// Defaults to `<secretclass>-jwks` in the namespace of the signing keys secret.
+	// +kubebuilder:validation:Optional
+	JWKSConfigMap *ConfigMapSpec `json:"jwksConfigMap,omitempty"`
+}
+
+type JWTSigningKeysSpec struct {
+	// Generate a signing key when the secret has none, and rotate the signing key when it reaches the key lifetime.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	AutoGenerate bool `json:"autoGenerate,omitempty"`
+
+	// Signing algorithm of generated keys.
+	// Default is ES256
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=ES256;RS256;EdDSA
+	// +kubebuilder:default="ES256"
+	Algorithm string `json:"algorithm,omitempty"`
+
+	// A new signing key is generated when the newest key is older than this,
+	// previous keys stay published until the tokens they signed expired.
+	// Use time.ParseDuration to parse the string
+	// Default is 720h (30 days)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="720h"
+	KeyLifeTime string `json:"keyLifeTime,omitempty"`
+
+	// A generated key is published in the JWKS document for this period before it signs tokens,
+	// so verifiers caching the JWKS document know the key before they get a token signed by it.
+	// Set it longer than verifiers cache the JWKS document, it must be shorter than the key lifetime.
+	// Use time.ParseDuration to parse the string
+	// Default is 24h, or half the key lifetime when the key lifetime is shorter than 48h
+	// +kubebuilder:validation:Optional
+	PrePublicationPeriod string `json:"prePublicationPeriod,omitempty"`
+
+	// Reference to a Secret where the signing keys are stored, as PKCS #8 PEM private keys in `<kid>.key` keys,
+	// with their creation time in `<kid>.created` keys.
+	// +kubebuilder:validation:Required
+	Secret *SecretSpec `json:"secret"`
+}
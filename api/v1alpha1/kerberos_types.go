@@ -1,13 +1,234 @@
 package v1alpha1
 
+// +kubebuilder:validation:XValidation:rule="!has(self.encryptionTypes) || size(self.encryptionTypes) == 0 || !has(self.admin.heimdal)",message="encryptionTypes is not supported with a heimdal admin server"
 type KerberosKeytabSpec struct {
 	Admin             *AdminServerSpec  `json:"admin"`
 	AdminPrincipal    string            `json:"adminPrincipal"`
 	AdminKeytabSecret *KeytabSecretSpec `json:"adminKeytabSecret"`
 	KDC               string            `json:"kdc"`
 
+	// Additional KDCs of the realm, clients try them in order when `kdc` is unavailable.
+	// +kubebuilder:validation:Optional
+	AdditionalKDCs []string `json:"additionalKdcs,omitempty"`
+
 	// +kubebuilder:validation:Pattern=`^[-.a-zA-Z0-9]+$`
 	RealmName string `json:"realmName"`
+
+	// Encryption types of the keys in the keytab, e.g. `aes256-cts-hmac-sha1-96`.
+	// MIT kadmin creates new and rotated keys with these encryption types,
+	// keys of other encryption types are removed from delivered keytabs.
+	// Not supported with a heimdal admin server, set `default_keys` in the kadmin server configuration instead.
+	// Also used as `permitted_enctypes` of the generated krb5.conf, unless `krb5Conf.permittedEncryptionTypes` is set.
+	// If empty, all keys created by the KDC are delivered.
+	// +kubebuilder:validation:Optional
+	EncryptionTypes []string `json:"encryptionTypes,omitempty"`
+
+	// Customizes the krb5.conf delivered with the keytab.
+	// +kubebuilder:validation:Optional
+	Krb5Conf *Krb5ConfSpec `json:"krb5Conf,omitempty"`
+
+	// Caches extracted keys, so kadmin is only contacted for new principals.
+	// If not set, keys are extracted from kadmin for every volume.
+	// +kubebuilder:validation:Optional
+	KeytabCache *KeytabCacheSpec `json:"keytabCache,omitempty"`
+
+	// Configures the principals in the keytab.
+	// If not set, the keytab contains `${service}/${hostname}` for every kerberos service name of the volume
+	// and every hostname of the volume scope.
+	// +kubebuilder:validation:Optional
+	Principals *PrincipalsSpec `json:"principals,omitempty"`
+
+	// Attributes of the principals created by the backend.
+	// They are also applied to existing principals whenever their keys are extracted.
+	// If not set, principals get the defaults of the KDC.
+	// +kubebuilder:validation:Optional
+	PrincipalAttributes *PrincipalAttributesSpec `json:"principalAttributes,omitempty"`
+
+	// Rotates the keys of the principals periodically.
+	// If not set, the keys of a principal never change.
+	// +kubebuilder:validation:Optional
+	KeyRotation *KeyRotationSpec `json:"keyRotation,omitempty"`
+
+	// Tracks the principals created for pods, and deletes them from the KDC once no pod or service uses them.
+	// Principals which already existed in the KDC when a volume first used them are never tracked or deleted.
+	// If not set, principals are never deleted.
+	// +kubebuilder:validation:Optional
+	PrincipalGC *PrincipalGCSpec `json:"principalGc,omitempty"`
+}
+
+type PrincipalsSpec struct {
+	// Templates of the principals in the keytab, e.g. `${service}/${hostname}`, `hdfs/_HOST` or `${serviceAccount}`.
+	// Variables: `${realm}`, `${namespace}`, `${pod}`, `${serviceAccount}`, `${node}`,
+	// `${service}` for every kerberos service name of the volume, `${hostname}` or `_HOST` for every hostname of the volume scope.
+	// The realm is appended to principals without realm.
+	// If empty, `${service}/${hostname}` is used.
+	// +kubebuilder:validation:Optional
+	Templates []string `json:"templates,omitempty"`
+
+	// Allows volumes to replace the templates with the `secrets.kubedoop.dev/kerberosPrincipals` annotation.
+	// A volume can then request the keys of any principal in the realm,
+	// only enable it when every namespace allowed to use the SecretClass is trusted.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	AllowVolumeOverride bool `json:"allowVolumeOverride,omitempty"`
+
+	// Excludes hostnames which are ip addresses or derived from pod ip addresses,
+	// e.g. `10-244-0-5.default.pod.cluster.local`, because they change whenever the pod is recreated.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	ExcludeIPHostnames bool `json:"excludeIpHostnames,omitempty"`
+
+	// Regular expressions of hostnames excluded from the principals, matching the whole hostname.
+	// +kubebuilder:validation:Optional
+	ExcludeHostnames []string `json:"excludeHostnames,omitempty"`
+}
+
+type PrincipalAttributesSpec struct {
+	// The kadmin policy of the principals, e.g. a password policy.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^[-_.a-zA-Z0-9]+$`
+	Policy string `json:"policy,omitempty"`
+
+	// Principal flags in MIT kadmin syntax, `+flag` sets a flag and `-flag` clears it,
+	// e.g. `+requires_preauth` or `-allow_tix`.
+	// For heimdal, the flags are translated to the matching heimdal attributes.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:items:Pattern=`^[+-][a-z_]+$`
+	Flags []string `json:"flags,omitempty"`
+
+	// Maximum ticket life of the principals.
+	// Use time.ParseDuration to parse the string
+	// +kubebuilder:validation:Optional
+	MaxLife string `json:"maxLife,omitempty"`
+
+	// Maximum renewable life of tickets of the principals.
+	// Use time.ParseDuration to parse the string
+	// +kubebuilder:validation:Optional
+	MaxRenewLife string `json:"maxRenewLife,omitempty"`
+}
+
+type KeyRotationSpec struct {
+	// Reference to the Secret where the current and previous keys of the principals are stored,
+	// with the time each key version was created.
+	// The Secret contains service keys, restrict read access to it as for the admin keytab.
+	// Defaults to `<secretclass>-keys` in the namespace of the admin keytab secret.
+	// +kubebuilder:validation:Optional
+	Secret *SecretSpec `json:"secret,omitempty"`
+
+	// Keys older than this are replaced with new random keys when a volume is mounted,
+	// pods are restarted before their keys reach this age to pick up the new keys.
+	// Use time.ParseDuration to parse the string
+	// Default is 720h (30 days)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="720h"
+	KeyAge string `json:"keyAge,omitempty"`
+
+	// Previous keys stay in delivered keytabs for this long after rotation, so tickets issued
+	// with the previous keys can still be decrypted. It should be longer than the maximum ticket lifetime.
+	// Use time.ParseDuration to parse the string
+	// Default is 24h
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="24h"
+	GracePeriod string `json:"gracePeriod,omitempty"`
+}
+
+type PrincipalGCSpec struct {
+	// Reference to the ConfigMap where created principals and the pods and services using them are tracked.
+	// Defaults to `<secretclass>-principals` in the namespace of the admin keytab secret.
+	// +kubebuilder:validation:Optional
+	ConfigMap *ConfigMapSpec `json:"configMap,omitempty"`
+
+	// Principals are deleted when no pod or service has used them for this long.
+	// Use time.ParseDuration to parse the string
+	// Default is 24h
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="24h"
+	GracePeriod string `json:"gracePeriod,omitempty"`
+
+	// Only reports the principals which would be deleted, without deleting them.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+type KeytabCacheSpec struct {
+	// Reference to the Secret where the extracted keys are cached.
+	// The Secret contains service keys, restrict read access to it as for the admin keytab.
+	// Defaults to `<secretclass>-keytab-cache` in the namespace of the admin keytab secret.
+	// +kubebuilder:validation:Optional
+	Secret *SecretSpec `json:"secret,omitempty"`
+
+	// Cached keys are used without contacting kadmin until they are older than this, then they are
+	// extracted from kadmin again, to pick up keys changed outside the operator, and removed from the
+	// Secret whenever other keys are cached.
+	// Use time.ParseDuration to parse the string
+	// Default is 24h
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="24h"
+	MaxAge string `json:"maxAge,omitempty"`
+}
+
+type Krb5ConfSpec struct {
+	// The KDC holding the master copy of the realm database, used to retry password failures.
+	// +kubebuilder:validation:Optional
+	MasterKDC string `json:"masterKdc,omitempty"`
+
+	// The server where password changes are performed, e.g. `kdc.example.com:464`.
+	// +kubebuilder:validation:Optional
+	KpasswdServer string `json:"kpasswdServer,omitempty"`
+
+	// Encryption types permitted for session keys, e.g. `aes256-cts-hmac-sha1-96`.
+	// If empty, the kerberos library default is used.
+	// +kubebuilder:validation:Optional
+	PermittedEncryptionTypes []string `json:"permittedEncryptionTypes,omitempty"`
+
+	// Default lifetime of initial tickets, in krb5 duration format, e.g. `24h` or `1d`.
+	// +kubebuilder:validation:Optional
+	TicketLifetime string `json:"ticketLifetime,omitempty"`
+
+	// Default renewable lifetime of initial tickets, in krb5 duration format, e.g. `7d`.
+	// +kubebuilder:validation:Optional
+	RenewLifetime string `json:"renewLifetime,omitempty"`
+
+	// Whether to look up KDCs using DNS SRV records.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=true
+	DNSLookupKDC *bool `json:"dnsLookupKdc,omitempty"`
+
+	// Messages larger than this limit are sent with TCP. `1` means always use TCP.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=1
+	UDPPreferenceLimit *int `json:"udpPreferenceLimit,omitempty"`
+
+	// Additional realms, e.g. for cross-realm trust.
+	// +kubebuilder:validation:Optional
+	AdditionalRealms []RealmSpec `json:"additionalRealms,omitempty"`
+
+	// Additional `[domain_realm]` mappings.
+	// +kubebuilder:validation:Optional
+	DomainRealms []DomainRealmSpec `json:"domainRealms,omitempty"`
+}
+
+type RealmSpec struct {
+	// +kubebuilder:validation:Pattern=`^[-.a-zA-Z0-9]+$`
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	KDCs []string `json:"kdcs"`
+
+	// +kubebuilder:validation:Optional
+	AdminServer string `json:"adminServer,omitempty"`
+}
+
+type DomainRealmSpec struct {
+	// Host name or domain, a domain is prefixed with `.`, e.g. `.example.com`.
+	// +kubebuilder:validation:Required
+	Domain string `json:"domain"`
+
+	// +kubebuilder:validation:Required
+	Realm string `json:"realm"`
 }
 
 type KeytabSecretSpec struct {
@@ -18,10 +239,18 @@ type KeytabSecretSpec struct {
 	Namespace string `json:"namespace"`
 }
 
+// AdminServerSpec configures the kadmin server used to manage principals.
+// Exactly one of `mit` or `heimdal` must be set.
+// +kubebuilder:validation:XValidation:rule="has(self.mit) != has(self.heimdal)",message="exactly one of mit or heimdal must be set"
 type AdminServerSpec struct {
 	// MIT kerberos admin server.
-	// +kubebuilder:validation:Required
-	MIT *MITSpec `json:"mit"`
+	// +kubebuilder:validation:Optional
+	MIT *MITSpec `json:"mit,omitempty"`
+
+	// Heimdal kerberos admin server.
+	// The default csi-plugin image only ships MIT kerberos, heimdal needs an image with the Heimdal kadmin binary.
+	// +kubebuilder:validation:Optional
+	Heimdal *HeimdalSpec `json:"heimdal,omitempty"`
 
 	// MS-AD
 }
@@ -31,3 +260,9 @@ type MITSpec struct {
 	// +kubebuilder:validation:Required
 	KadminServer string `json:"kadminServer"`
 }
+
+type HeimdalSpec struct {
+	// The hostname of the kadmin server.
+	// +kubebuilder:validation:Required
+	KadminServer string `json:"kadminServer"`
+}
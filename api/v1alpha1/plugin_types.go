@@ -0,0 +1,46 @@
+package v1alpha1
+
+// PluginSpec gets the secrets from an out-of-process backend plugin over gRPC,
+// see the `pkg/plugin` Go SDK and the `pkg/plugin/v1alpha1/backend.proto` protocol.
+// +kubebuilder:validation:XValidation:rule="self.endpoint.startsWith('unix:') || has(self.tls)",message="tls is required for endpoints other than unix sockets"
+type PluginSpec struct {
+	// gRPC target of the plugin, e.g. `unix:///var/run/secret-operator/plugins/vault.sock` for a sidecar
+	// of the csi pods, or `dns:///vault-plugin.secret-operator.svc:9000` for a service.
+	// The csi controller calls the plugin to choose the nodes of volumes, and the csi node plugin to get the secrets.
+	// Endpoints other than unix sockets require tls, the plugin must authenticate the caller, e.g. by the client certificate,
+	// as it returns the secrets to every client reaching the endpoint.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Endpoint string `json:"endpoint"`
+
+	// Parameters passed to the plugin with every request.
+	// +kubebuilder:validation:Optional
+	Parameters map[string]string `json:"parameters,omitempty"`
+
+	// Timeout of a request to the plugin.
+	// Use time.ParseDuration to parse the string
+	// Default is 30s
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="30s"
+	Timeout string `json:"timeout,omitempty"`
+
+	// Connect to the plugin with TLS, verifying its certificate with the trust roots of the ConfigMap.
+	// Without it, the secrets are sent in plaintext, which is only allowed for unix sockets.
+	// +kubebuilder:validation:Optional
+	TLS *PluginTLSSpec `json:"tls,omitempty"`
+}
+
+type PluginTLSSpec struct {
+	// Reference to a ConfigMap containing the PEM trust roots of the plugin certificate in the `ca.crt` key.
+	// +kubebuilder:validation:Required
+	CAConfigMap *ConfigMapSpec `json:"caConfigMap"`
+
+	// Name verified against the plugin certificate, defaults to the host of the endpoint.
+	// +kubebuilder:validation:Optional
+	ServerName string `json:"serverName,omitempty"`
+
+	// Reference to a Secret with the client certificate and key in the `tls.crt` and `tls.key` keys,
+	// presented to the plugin so it can authenticate the csi driver, e.g. with plugin.ServerTLS of the Go SDK.
+	// +kubebuilder:validation:Optional
+	ClientCertSecret *SecretSpec `json:"clientCertSecret,omitempty"`
+}
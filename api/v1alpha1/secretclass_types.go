@@ -32,12 +32,22 @@ type BackendSpec struct {
 	K8sSearch *K8sSearchSpec `json:"k8sSearch,omitempty"`
 	// +kubebuilder:validation:Optional
 	KerberosKeytab *KerberosKeytabSpec `json:"kerberosKeytab,omitempty"`
+	// +kubebuilder:validation:Optional
+	SSHCA *SSHCASpec `json:"sshCa,omitempty"`
+	// +kubebuilder:validation:Optional
+	JWT *JWTSpec `json:"jwt,omitempty"`
+	// +kubebuilder:validation:Optional
+	Composite *CompositeSpec `json:"composite,omitempty"`
+	// +kubebuilder:validation:Optional
+	Plugin *PluginSpec `json:"plugin,omitempty"`
 }
 
 type AutoTlsSpec struct {
 	// Reference to a ConfigMap or Secret containing the trust root.
-	// When the key suffix is `.crt`, the value is a base64 encoded DER certificate.
-	// When the key suffix is `.der`, the value is a binary DER certificate.
+	// When the key suffix is `.crt` or `.pem`, the value is a PEM bundle of one or more certificates.
+	// When the key suffix is `.der`, the value is one or more binary DER certificates.
+	// When the key suffix is `.p7b` or `.p7c`, the value is a PKCS#7 certificate bundle, PEM or DER encoded.
+	// Keys with other suffixes are skipped, certificates in several keys are only added once.
 	// +kubebuilder:validation:Optional
 	AdditionalTrustRoots []AdditionalTrustRootSpec `json:"additionalTrustRoots,omitempty"`
 
@@ -50,6 +60,23 @@ type AutoTlsSpec struct {
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default="360h"
 	MaxCertificateLifeTime string `json:"maxCertificateLifeTime,omitempty"`
+
+	// Publishes the trust anchors to ConfigMaps in the selected namespaces, for consumers without a volume,
+	// e.g. ingress controllers or clients in other clusters.
+	// Requires the trust bundle controller of the csi controller.
+	// +kubebuilder:validation:Optional
+	TrustBundle *TrustBundleSpec `json:"trustBundle,omitempty"`
+}
+
+type TrustBundleSpec struct {
+	// Name of the ConfigMaps, the PEM bundle is the `ca.crt` key.
+	// Default is `<secretclass>-trust-bundle`
+	// +kubebuilder:validation:Optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+
+	// Namespaces the ConfigMaps are published in, an empty selector selects all namespaces.
+	// +kubebuilder:validation:Required
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector"`
 }
 
 type AdditionalTrustRootSpec struct {
@@ -70,6 +97,7 @@ type ConfigMapSpec struct {
 	Namespace string `json:"namespace"`
 }
 
+// +kubebuilder:validation:XValidation:rule="!has(self.keyEncryption) || !has(self.keyEncryption.secret) || self.keyEncryption.secret.namespace != self.secret.namespace",message="the key encryption key secret must be in another namespace than the CA secret"
 type CASpec struct {
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default=false
@@ -87,6 +115,41 @@ type CASpec struct {
 
 	// +kubebuilder:validation:Optional
 	KeyGeneration *KeyGenerationSpec `json:"keyGeneration,omitempty"`
+
+	// Encrypts the private keys of the certificate authorities in the secret with a key encryption key,
+	// they are only decrypted in memory. Existing plain keys are encrypted on the next write of the secret,
+	// which requires autoGenerate.
+	// +kubebuilder:validation:Optional
+	KeyEncryption *KeyEncryptionSpec `json:"keyEncryption,omitempty"`
+}
+
+// KeyEncryptionSpec configures the key encryption key, one of secret or kmsPlugin.
+type KeyEncryptionSpec struct {
+	// Reference to a Secret with a 32 byte AES-256 key encryption key in its `kek` key.
+	// The secret is created with a random key when it does not exist,
+	// it must be in another namespace than the CA secret.
+	// To replace the key, move it to a `kek.<n>` key, e.g. `kek.1`, and set a new `kek`. Previous keys only decrypt,
+	// the private keys are encrypted again with the new key when they are loaded, then the previous keys can be removed.
+	// +kubebuilder:validation:Optional
+	Secret *SecretSpec `json:"secret,omitempty"`
+
+	// KMS plugin implementing the Kubernetes KMS v2 API, e.g. the plugin of a cloud KMS.
+	// +kubebuilder:validation:Optional
+	KMSPlugin *KMSPluginSpec `json:"kmsPlugin,omitempty"`
+}
+
+type KMSPluginSpec struct {
+	// gRPC endpoint of the plugin, e.g. `unix:///var/run/secret-operator/plugins/kms.sock`
+	// with the plugin as a sidecar of the csi pods.
+	// +kubebuilder:validation:Required
+	Endpoint string `json:"endpoint"`
+
+	// Timeout of a request to the plugin.
+	// Use time.ParseDuration to parse the string
+	// Default is 10s
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="10s"
+	Timeout string `json:"timeout,omitempty"`
 }
 
 type KeyGenerationSpec struct {
@@ -111,6 +174,116 @@ type K8sSearchSpec struct {
 	// One of the `Name` for namespace or `Pod` for the same namespace with pod.
 	// +kubebuilder:validation:Required
 	SearchNamespace *SearchNamespaceSpec `json:"searchNamespace"`
+
+	// Kinds of objects searched by the labels of a volume, Secret and/or ConfigMap.
+	// ConfigMaps are meant for non-sensitive data, e.g. JAAS templates or truststores,
+	// both their data and binaryData are delivered.
+	// Default is Secret
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:items:Enum=Secret;ConfigMap
+	// +kubebuilder:default={Secret}
+	Kinds []string `json:"kinds,omitempty"`
+
+	// How to handle multiple objects matching the labels of a volume.
+	// `Fail` fails the volume when more than one object matches.
+	// `Merge` merges the data of all matching objects, when objects have the same key the value is taken from
+	// the object with the most scope labels, then the highest `secrets.kubedoop.dev/priority` annotation,
+	// then the first object by name.
+	// Default is Merge
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Fail;Merge
+	// +kubebuilder:default="Merge"
+	MultipleMatches string `json:"multipleMatches,omitempty"`
+
+	// Label keys matched against the secret class and the scope of a volume.
+	// Use it to search objects labeled by other tools.
+	// +kubebuilder:validation:Optional
+	ScopeLabels *ScopeLabelsSpec `json:"scopeLabels,omitempty"`
+
+	// Additional label selector the objects must match, combined with the scope labels.
+	// Values of matchLabels and matchExpressions are templates, supported variables are
+	// `${class}`, `${namespace}`, `${pod}`, `${serviceAccount}`, `${node}`, `${service}`, `${listener}`
+	// and `${podLabels.<key>}` for the value of a pod label, e.g. `${podLabels.app.kubernetes.io/instance}`.
+	// `${service}` and `${listener}` are the scoped services and listeners of the volume,
+	// a matchExpressions value using them is expanded once for each of them.
+	// +kubebuilder:validation:Optional
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+
+	// Generates a secret with random values when no object matches a volume, e.g. a password per pod.
+	// The secret is created in the search namespace with the labels of the search, so later volumes find it.
+	// Secrets generated for the pod or service scope are owned by the pod or service, and deleted with it,
+	// they require the search namespace to be the pod namespace and at most one service in the scope.
+	// Secrets of other scopes are kept until they are deleted manually.
+	// Requires Secret in kinds.
+	// +kubebuilder:validation:Optional
+	Generate *GenerateSpec `json:"generate,omitempty"`
+}
+
+type GenerateSpec struct {
+	// Keys of the generated secret.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	Keys []GeneratedKeySpec `json:"keys"`
+}
+
+type GeneratedKeySpec struct {
+	// Name of the key in the secret.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[-._a-zA-Z0-9]+$`
+	Name string `json:"name"`
+
+	// Number of characters of the random value.
+	// Default is 32
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=4096
+	// +kubebuilder:default=32
+	Length int `json:"length,omitempty"`
+
+	// Characters of the random value, one of `Alphanumeric`, `Numeric`, `Hex` or `Printable`,
+	// `Printable` is alphanumeric with punctuation.
+	// Default is Alphanumeric
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Alphanumeric;Numeric;Hex;Printable
+	// +kubebuilder:default="Alphanumeric"
+	Charset string `json:"charset,omitempty"`
+
+	// Custom characters of the random value, overrides charset.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MinLength=2
+	Characters string `json:"characters,omitempty"`
+}
+
+// ScopeLabelsSpec configures the label keys matched against a volume.
+// An unset key uses the default, an empty key disables matching the secret class or the scope,
+// e.g. when the objects are selected by the label selector only.
+type ScopeLabelsSpec struct {
+	// Label key with the secret class name.
+	// Default is `secrets.kubedoop.dev/class`
+	// +kubebuilder:validation:Optional
+	Class *string `json:"class,omitempty"`
+
+	// Label key with the pod name, for volumes with pod scope.
+	// Default is `secrets.kubedoop.dev/pod`
+	// +kubebuilder:validation:Optional
+	Pod *string `json:"pod,omitempty"`
+
+	// Label key with the comma separated service names, for volumes with service scope.
+	// Default is `secrets.kubedoop.dev/service`
+	// +kubebuilder:validation:Optional
+	Service *string `json:"service,omitempty"`
+
+	// Label key with the node name, for volumes with node scope or a node scoped listener.
+	// Default is `secrets.kubedoop.dev/node`
+	// +kubebuilder:validation:Optional
+	Node *string `json:"node,omitempty"`
+
+	// Prefix of the label keys with the listener names, for volumes with listener-volume scope.
+	// The index of the listener volume in the scope is appended, starting from 1.
+	// Default is `secrets.stackable.tech/listener.`
+	// +kubebuilder:validation:Optional
+	ListenerPrefix *string `json:"listenerPrefix,omitempty"`
 }
 
 type SearchNamespaceSpec struct {
@@ -0,0 +1,47 @@
+package v1alpha1
+
+// SSHCASpec issues OpenSSH certificates signed by an SSH certificate authority.
+// The volume gets a host key with a host certificate for the scoped addresses of the pod,
+// and a known_hosts file trusting the certificate authority.
+// The files of the volume are readable by the pod, sshd refuses such host keys, so copy them
+// to a private location with mode 0600 before starting sshd.
+type SSHCASpec struct {
+	// Configures the SSH certificate authority used to sign the certificates.
+	// +kubebuilder:validation:Required
+	CA *SSHCAKeySpec `json:"ca"`
+
+	// Use time.ParseDuration to parse the string
+	// Default is 168h (7 days)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="168h"
+	CertificateLifeTime string `json:"certificateLifeTime,omitempty"`
+
+	// Also issue a user key with a user certificate, whose principal is the namespace and service account
+	// of the pod, `<namespace>.<serviceaccount>`.
+	// Hosts map the principals to local users with AuthorizedPrincipalsFile in sshd_config, e.g.
+	// `AuthorizedPrincipalsFile /etc/ssh/principals/%u` where the file of a user lists `team-a.backup`,
+	// so only the `backup` service account of the `team-a` namespace logs in as that user.
+	// Without AuthorizedPrincipalsFile, sshd only accepts a principal equal to the login user name.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	UserCertificate bool `json:"userCertificate,omitempty"`
+}
+
+type SSHCAKeySpec struct {
+	// Generate the certificate authority key when the secret has none.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	AutoGenerate bool `json:"autoGenerate,omitempty"`
+
+	// Type of the generated certificate authority key, host and user keys have the same type.
+	// Default is Ed25519
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Ed25519;ECDSA;RSA
+	// +kubebuilder:default="Ed25519"
+	KeyType string `json:"keyType,omitempty"`
+
+	// Reference to a Secret where the certificate authority key is stored,
+	// as OpenSSH private key in the `ssh_ca` key.
+	// +kubebuilder:validation:Required
+	Secret *SecretSpec `json:"secret"`
+}
@@ -58,6 +58,11 @@ func (in *AdminServerSpec) DeepCopyInto(out *AdminServerSpec) {
 		*out = new(MITSpec)
 		**out = **in
 	}
+	if in.Heimdal != nil {
+		in, out := &in.Heimdal, &out.Heimdal
+		*out = new(HeimdalSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdminServerSpec.
@@ -85,6 +90,11 @@ func (in *AutoTlsSpec) DeepCopyInto(out *AutoTlsSpec) {
 		*out = new(CASpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.TrustBundle != nil {
+		in, out := &in.TrustBundle, &out.TrustBundle
+		*out = new(TrustBundleSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoTlsSpec.
@@ -115,6 +125,26 @@ func (in *BackendSpec) DeepCopyInto(out *BackendSpec) {
 		*out = new(KerberosKeytabSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.SSHCA != nil {
+		in, out := &in.SSHCA, &out.SSHCA
+		*out = new(SSHCASpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.JWT != nil {
+		in, out := &in.JWT, &out.JWT
+		*out = new(JWTSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Composite != nil {
+		in, out := &in.Composite, &out.Composite
+		*out = new(CompositeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Plugin != nil {
+		in, out := &in.Plugin, &out.Plugin
+		*out = new(PluginSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendSpec.
@@ -140,6 +170,11 @@ func (in *CASpec) DeepCopyInto(out *CASpec) {
 		*out = new(KeyGenerationSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.KeyEncryption != nil {
+		in, out := &in.KeyEncryption, &out.KeyEncryption
+		*out = new(KeyEncryptionSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CASpec.
@@ -152,6 +187,41 @@ func (in *CASpec) DeepCopy() *CASpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CompositeSecretClassSpec) DeepCopyInto(out *CompositeSecretClassSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompositeSecretClassSpec.
+func (in *CompositeSecretClassSpec) DeepCopy() *CompositeSecretClassSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CompositeSecretClassSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CompositeSpec) DeepCopyInto(out *CompositeSpec) {
+	*out = *in
+	if in.SecretClasses != nil {
+		in, out := &in.SecretClasses, &out.SecretClasses
+		*out = make([]CompositeSecretClassSpec, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompositeSpec.
+func (in *CompositeSpec) DeepCopy() *CompositeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CompositeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConfigMapSpec) DeepCopyInto(out *ConfigMapSpec) {
 	*out = *in
@@ -167,6 +237,121 @@ func (in *ConfigMapSpec) DeepCopy() *ConfigMapSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DomainRealmSpec) DeepCopyInto(out *DomainRealmSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainRealmSpec.
+func (in *DomainRealmSpec) DeepCopy() *DomainRealmSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DomainRealmSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GenerateSpec) DeepCopyInto(out *GenerateSpec) {
+	*out = *in
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]GeneratedKeySpec, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GenerateSpec.
+func (in *GenerateSpec) DeepCopy() *GenerateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GenerateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GeneratedKeySpec) DeepCopyInto(out *GeneratedKeySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GeneratedKeySpec.
+func (in *GeneratedKeySpec) DeepCopy() *GeneratedKeySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GeneratedKeySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HeimdalSpec) DeepCopyInto(out *HeimdalSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HeimdalSpec.
+func (in *HeimdalSpec) DeepCopy() *HeimdalSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HeimdalSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JWTSigningKeysSpec) DeepCopyInto(out *JWTSigningKeysSpec) {
+	*out = *in
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		*out = new(SecretSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JWTSigningKeysSpec.
+func (in *JWTSigningKeysSpec) DeepCopy() *JWTSigningKeysSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(JWTSigningKeysSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JWTSpec) DeepCopyInto(out *JWTSpec) {
+	*out = *in
+	if in.Audiences != nil {
+		in, out := &in.Audiences, &out.Audiences
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SigningKeys != nil {
+		in, out := &in.SigningKeys, &out.SigningKeys
+		*out = new(JWTSigningKeysSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.JWKSConfigMap != nil {
+		in, out := &in.JWKSConfigMap, &out.JWKSConfigMap
+		*out = new(ConfigMapSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JWTSpec.
+func (in *JWTSpec) DeepCopy() *JWTSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(JWTSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *K8sSearchSpec) DeepCopyInto(out *K8sSearchSpec) {
 	*out = *in
@@ -175,6 +360,26 @@ func (in *K8sSearchSpec) DeepCopyInto(out *K8sSearchSpec) {
 		*out = new(SearchNamespaceSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Kinds != nil {
+		in, out := &in.Kinds, &out.Kinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ScopeLabels != nil {
+		in, out := &in.ScopeLabels, &out.ScopeLabels
+		*out = new(ScopeLabelsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Generate != nil {
+		in, out := &in.Generate, &out.Generate
+		*out = new(GenerateSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new K8sSearchSpec.
@@ -187,6 +392,21 @@ func (in *K8sSearchSpec) DeepCopy() *K8sSearchSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KMSPluginSpec) DeepCopyInto(out *KMSPluginSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KMSPluginSpec.
+func (in *KMSPluginSpec) DeepCopy() *KMSPluginSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KMSPluginSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KerberosKeytabSpec) DeepCopyInto(out *KerberosKeytabSpec) {
 	*out = *in
@@ -200,6 +420,46 @@ func (in *KerberosKeytabSpec) DeepCopyInto(out *KerberosKeytabSpec) {
 		*out = new(KeytabSecretSpec)
 		**out = **in
 	}
+	if in.AdditionalKDCs != nil {
+		in, out := &in.AdditionalKDCs, &out.AdditionalKDCs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.EncryptionTypes != nil {
+		in, out := &in.EncryptionTypes, &out.EncryptionTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Krb5Conf != nil {
+		in, out := &in.Krb5Conf, &out.Krb5Conf
+		*out = new(Krb5ConfSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KeytabCache != nil {
+		in, out := &in.KeytabCache, &out.KeytabCache
+		*out = new(KeytabCacheSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Principals != nil {
+		in, out := &in.Principals, &out.Principals
+		*out = new(PrincipalsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PrincipalAttributes != nil {
+		in, out := &in.PrincipalAttributes, &out.PrincipalAttributes
+		*out = new(PrincipalAttributesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KeyRotation != nil {
+		in, out := &in.KeyRotation, &out.KeyRotation
+		*out = new(KeyRotationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PrincipalGC != nil {
+		in, out := &in.PrincipalGC, &out.PrincipalGC
+		*out = new(PrincipalGCSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KerberosKeytabSpec.
@@ -212,6 +472,31 @@ func (in *KerberosKeytabSpec) DeepCopy() *KerberosKeytabSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyEncryptionSpec) DeepCopyInto(out *KeyEncryptionSpec) {
+	*out = *in
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		*out = new(SecretSpec)
+		**out = **in
+	}
+	if in.KMSPlugin != nil {
+		in, out := &in.KMSPlugin, &out.KMSPlugin
+		*out = new(KMSPluginSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyEncryptionSpec.
+func (in *KeyEncryptionSpec) DeepCopy() *KeyEncryptionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyEncryptionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KeyGenerationSpec) DeepCopyInto(out *KeyGenerationSpec) {
 	*out = *in
@@ -232,6 +517,46 @@ func (in *KeyGenerationSpec) DeepCopy() *KeyGenerationSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyRotationSpec) DeepCopyInto(out *KeyRotationSpec) {
+	*out = *in
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		*out = new(SecretSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyRotationSpec.
+func (in *KeyRotationSpec) DeepCopy() *KeyRotationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyRotationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeytabCacheSpec) DeepCopyInto(out *KeytabCacheSpec) {
+	*out = *in
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		*out = new(SecretSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeytabCacheSpec.
+func (in *KeytabCacheSpec) DeepCopy() *KeytabCacheSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KeytabCacheSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KeytabSecretSpec) DeepCopyInto(out *KeytabSecretSpec) {
 	*out = *in
@@ -247,6 +572,48 @@ func (in *KeytabSecretSpec) DeepCopy() *KeytabSecretSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Krb5ConfSpec) DeepCopyInto(out *Krb5ConfSpec) {
+	*out = *in
+	if in.PermittedEncryptionTypes != nil {
+		in, out := &in.PermittedEncryptionTypes, &out.PermittedEncryptionTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DNSLookupKDC != nil {
+		in, out := &in.DNSLookupKDC, &out.DNSLookupKDC
+		*out = new(bool)
+		**out = **in
+	}
+	if in.UDPPreferenceLimit != nil {
+		in, out := &in.UDPPreferenceLimit, &out.UDPPreferenceLimit
+		*out = new(int)
+		**out = **in
+	}
+	if in.AdditionalRealms != nil {
+		in, out := &in.AdditionalRealms, &out.AdditionalRealms
+		*out = make([]RealmSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DomainRealms != nil {
+		in, out := &in.DomainRealms, &out.DomainRealms
+		*out = make([]DomainRealmSpec, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Krb5ConfSpec.
+func (in *Krb5ConfSpec) DeepCopy() *Krb5ConfSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(Krb5ConfSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MITSpec) DeepCopyInto(out *MITSpec) {
 	*out = *in
@@ -262,6 +629,58 @@ func (in *MITSpec) DeepCopy() *MITSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PluginSpec) DeepCopyInto(out *PluginSpec) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(PluginTLSSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginSpec.
+func (in *PluginSpec) DeepCopy() *PluginSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PluginTLSSpec) DeepCopyInto(out *PluginTLSSpec) {
+	*out = *in
+	if in.CAConfigMap != nil {
+		in, out := &in.CAConfigMap, &out.CAConfigMap
+		*out = new(ConfigMapSpec)
+		**out = **in
+	}
+	if in.ClientCertSecret != nil {
+		in, out := &in.ClientCertSecret, &out.ClientCertSecret
+		*out = new(SecretSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginTLSSpec.
+func (in *PluginTLSSpec) DeepCopy() *PluginTLSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginTLSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodSpec) DeepCopyInto(out *PodSpec) {
 	*out = *in
@@ -277,6 +696,71 @@ func (in *PodSpec) DeepCopy() *PodSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrincipalAttributesSpec) DeepCopyInto(out *PrincipalAttributesSpec) {
+	*out = *in
+	if in.Flags != nil {
+		in, out := &in.Flags, &out.Flags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrincipalAttributesSpec.
+func (in *PrincipalAttributesSpec) DeepCopy() *PrincipalAttributesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PrincipalAttributesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrincipalGCSpec) DeepCopyInto(out *PrincipalGCSpec) {
+	*out = *in
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(ConfigMapSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrincipalGCSpec.
+func (in *PrincipalGCSpec) DeepCopy() *PrincipalGCSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PrincipalGCSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrincipalsSpec) DeepCopyInto(out *PrincipalsSpec) {
+	*out = *in
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludeHostnames != nil {
+		in, out := &in.ExcludeHostnames, &out.ExcludeHostnames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrincipalsSpec.
+func (in *PrincipalsSpec) DeepCopy() *PrincipalsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PrincipalsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RSASpec) DeepCopyInto(out *RSASpec) {
 	*out = *in
@@ -292,6 +776,106 @@ func (in *RSASpec) DeepCopy() *RSASpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RealmSpec) DeepCopyInto(out *RealmSpec) {
+	*out = *in
+	if in.KDCs != nil {
+		in, out := &in.KDCs, &out.KDCs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RealmSpec.
+func (in *RealmSpec) DeepCopy() *RealmSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RealmSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SSHCAKeySpec) DeepCopyInto(out *SSHCAKeySpec) {
+	*out = *in
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		*out = new(SecretSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SSHCAKeySpec.
+func (in *SSHCAKeySpec) DeepCopy() *SSHCAKeySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SSHCAKeySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SSHCASpec) DeepCopyInto(out *SSHCASpec) {
+	*out = *in
+	if in.CA != nil {
+		in, out := &in.CA, &out.CA
+		*out = new(SSHCAKeySpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SSHCASpec.
+func (in *SSHCASpec) DeepCopy() *SSHCASpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SSHCASpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScopeLabelsSpec) DeepCopyInto(out *ScopeLabelsSpec) {
+	*out = *in
+	if in.Class != nil {
+		in, out := &in.Class, &out.Class
+		*out = new(string)
+		**out = **in
+	}
+	if in.Pod != nil {
+		in, out := &in.Pod, &out.Pod
+		*out = new(string)
+		**out = **in
+	}
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(string)
+		**out = **in
+	}
+	if in.Node != nil {
+		in, out := &in.Node, &out.Node
+		*out = new(string)
+		**out = **in
+	}
+	if in.ListenerPrefix != nil {
+		in, out := &in.ListenerPrefix, &out.ListenerPrefix
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScopeLabelsSpec.
+func (in *ScopeLabelsSpec) DeepCopy() *ScopeLabelsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ScopeLabelsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SearchNamespaceSpec) DeepCopyInto(out *SearchNamespaceSpec) {
 	*out = *in
@@ -432,3 +1016,23 @@ func (in *SecretSpec) DeepCopy() *SecretSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrustBundleSpec) DeepCopyInto(out *TrustBundleSpec) {
+	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrustBundleSpec.
+func (in *TrustBundleSpec) DeepCopy() *TrustBundleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TrustBundleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -21,24 +21,35 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	listenerv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/listeners/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
 	secretv1alpha1 "github.com/zncdatadev/secret-operator/api/v1alpha1"
+	"github.com/zncdatadev/secret-operator/internal/controller"
 	"github.com/zncdatadev/secret-operator/internal/csi"
+	"github.com/zncdatadev/secret-operator/internal/csi/backend/ca"
+	// Register the secret-operator metrics, served by the manager on the metrics-bind-address.
+	_ "github.com/zncdatadev/secret-operator/internal/metrics"
 	"github.com/zncdatadev/secret-operator/internal/util/version"
+	"github.com/zncdatadev/secret-operator/pkg/kerberos"
+	"github.com/zncdatadev/secret-operator/pkg/util"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -65,6 +76,15 @@ func main() {
 	var enableHTTP2 bool
 	var tlsOpts []func(*tls.Config)
 	var versionInfo bool
+	var kadminWorkers int
+	var enablePrincipalGC bool
+	var principalGCInterval time.Duration
+	var enableTrustBundle bool
+	var trustBundleInterval time.Duration
+	var enableCAMetrics bool
+	var caMetricsInterval time.Duration
+	var clusterDomain string
+	var heimdalKadmin string
 	flag.StringVar(&endpoint, "endpoint", "unix://tmp/csi.sock", "CSI endpoint")
 	flag.StringVar(&nodeID, "nodeid", "", "node id")
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
@@ -78,6 +98,29 @@ func main() {
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
 	flag.BoolVar(&versionInfo, "version", false, "Prints the version information")
+	flag.IntVar(&kadminWorkers, "kadmin-workers", kerberos.DefaultKadminWorkers,
+		"The maximum number of kadmin sessions running concurrently.")
+	flag.BoolVar(&enablePrincipalGC, "enable-principal-gc", false,
+		"If set, kerberos principals no longer used by any pod or service are deleted, "+
+			"for SecretClasses with principal gc configured. Enable it with leader election on one deployment only.")
+	flag.DurationVar(&principalGCInterval, "principal-gc-interval", controller.DefaultPrincipalGCInterval,
+		"The interval between two kerberos principal garbage collections of a SecretClass.")
+	flag.BoolVar(&enableTrustBundle, "enable-trust-bundle", false,
+		"If set, the trust anchors of AutoTls SecretClasses with a trust bundle are published to ConfigMaps. "+
+			"Enable it with leader election on one deployment only.")
+	flag.DurationVar(&trustBundleInterval, "trust-bundle-interval", controller.DefaultTrustBundleInterval,
+		"The interval between two trust bundle publications of a SecretClass.")
+	flag.BoolVar(&enableCAMetrics, "enable-ca-metrics", false,
+		"If set, the expiry of the certificate authorities of AutoTls SecretClasses is exported as metric. "+
+			"Enable it with leader election on one deployment only.")
+	flag.DurationVar(&caMetricsInterval, "ca-metrics-interval", controller.DefaultCAMetricsInterval,
+		"The interval between two exports of the certificate authority expiry of a SecretClass.")
+	flag.StringVar(&clusterDomain, "cluster-domain", "",
+		"The domain of the kubernetes cluster dns, e.g. cluster.local. If empty, the "+util.ClusterDomainEnv+
+			" environment variable or the search domains in /etc/resolv.conf are used.")
+	flag.StringVar(&heimdalKadmin, "heimdal-kadmin", kerberos.DefaultHeimdalKadminCommand,
+		"The name or path of the Heimdal kadmin binary, for SecretClasses with a heimdal admin server. "+
+			"The default image only ships MIT kerberos, a custom image with the Heimdal client tools is required.")
 
 	opts := zap.Options{
 		Development: true,
@@ -93,6 +136,12 @@ func main() {
 	}
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+
+	kerberos.SetKadminWorkers(kadminWorkers)
+	kerberos.SetHeimdalKadminCommand(heimdalKadmin)
+	util.SetClusterDomain(util.DiscoverClusterDomain(clusterDomain))
+	setupLog.Info("using cluster domain", "clusterDomain", util.GetClusterDomain())
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -145,12 +194,75 @@ func main() {
 		// if you are doing or is intended to do any operation such as perform cleanups
 		// after the manager stops then its usage might be unsafe.
 		// LeaderElectionReleaseOnCancel: true,
+
+		// Only trust bundle ConfigMaps are cached, for the watch of the trust bundle controller.
+		// Other ConfigMaps are read from the api server instead of caching all ConfigMaps of the cluster,
+		// see the ConfigMap metadata cache below.
+		Cache: cache.Options{
+			ByObject: map[client.Object]cache.ByObject{
+				&corev1.ConfigMap{}: {Label: labels.SelectorFromSet(labels.Set{controller.LabelTrustBundle: "true"})},
+			},
+		},
+		Client: client.Options{
+			Cache: &client.CacheOptions{DisableFor: []client.Object{&corev1.ConfigMap{}}},
+		},
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
+	// Additional trust roots of AutoTls SecretClasses are read on every volume. Only the metadata of ConfigMaps
+	// is cached, a trust root ConfigMap is read from the api server again when its resource version changes.
+	configMapMetadataCache, err := cache.New(mgr.GetConfig(), cache.Options{
+		HTTPClient:       mgr.GetHTTPClient(),
+		Scheme:           mgr.GetScheme(),
+		Mapper:           mgr.GetRESTMapper(),
+		DefaultTransform: cache.TransformStripManagedFields(),
+	})
+	if err != nil {
+		setupLog.Error(err, "unable to create configmap metadata cache")
+		os.Exit(1)
+	}
+	if err := mgr.Add(configMapMetadataCache); err != nil {
+		setupLog.Error(err, "unable to add configmap metadata cache")
+		os.Exit(1)
+	}
+	ca.SetConfigMapMetadataReader(configMapMetadataCache)
+
+	if enablePrincipalGC {
+		if err = (&controller.PrincipalGCReconciler{
+			Client:   mgr.GetClient(),
+			Scheme:   mgr.GetScheme(),
+			Interval: principalGCInterval,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "PrincipalGC")
+			os.Exit(1)
+		}
+	}
+
+	if enableTrustBundle {
+		if err = (&controller.TrustBundleReconciler{
+			Client:   mgr.GetClient(),
+			Scheme:   mgr.GetScheme(),
+			Interval: trustBundleInterval,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "TrustBundle")
+			os.Exit(1)
+		}
+	}
+
+	if enableCAMetrics {
+		if err = (&controller.CAMetricsReconciler{
+			Client:   mgr.GetClient(),
+			Scheme:   mgr.GetScheme(),
+			Interval: caMetricsInterval,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "CAMetrics")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -39,6 +39,8 @@ import (
 
 	secretv1alpha1 "github.com/zncdatadev/secret-operator/api/v1alpha1"
 	"github.com/zncdatadev/secret-operator/internal/controller"
+	// Register the secret-operator metrics, served by the manager on the metrics-bind-address.
+	_ "github.com/zncdatadev/secret-operator/internal/metrics"
 	"github.com/zncdatadev/secret-operator/internal/util/version"
 	// +kubebuilder:scaffold:imports
 )
@@ -0,0 +1,107 @@
+/*
+Copyright 2024 zncdatadev.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	secretvs1alpha1 "github.com/zncdatadev/secret-operator/api/v1alpha1"
+	"github.com/zncdatadev/secret-operator/internal/csi/backend/ca"
+	"github.com/zncdatadev/secret-operator/internal/metrics"
+)
+
+const (
+	DefaultCAMetricsInterval = 10 * time.Minute
+)
+
+// CAMetricsReconciler periodically exports the expiry of the certificate authorities of every AutoTls SecretClass
+// from its CA secret, so one metric series per certificate authority is exported by the leader, independent of
+// the volumes mounted on the nodes. The CA secret is only read, certificate authorities are rotated by the nodes.
+type CAMetricsReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Interval between two exports of a SecretClass.
+	Interval time.Duration
+}
+
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+func (r *CAMetricsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	secretClass := &secretvs1alpha1.SecretClass{}
+	if err := r.Get(ctx, req.NamespacedName, secretClass); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		metrics.SetCACertificateNotAfter(req.Name, nil)
+		return ctrl.Result{}, nil
+	}
+
+	backend := secretClass.Spec.Backend
+	if backend == nil || backend.AutoTls == nil || backend.AutoTls.CA == nil || backend.AutoTls.CA.Secret == nil {
+		metrics.SetCACertificateNotAfter(secretClass.Name, nil)
+		return ctrl.Result{}, nil
+	}
+
+	caSecretSpec := backend.AutoTls.CA.Secret
+	caSecret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Name: caSecretSpec.Name, Namespace: caSecretSpec.Namespace}, caSecret); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		// the secret is created with the first volume of the SecretClass
+		logger.V(1).Info("ca secret does not exist yet", "name", caSecretSpec.Name, "namespace", caSecretSpec.Namespace)
+		metrics.SetCACertificateNotAfter(secretClass.Name, nil)
+		return ctrl.Result{RequeueAfter: r.interval()}, nil
+	}
+
+	notAfter, err := ca.CertificateAuthoritiesNotAfter(caSecret)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	metrics.SetCACertificateNotAfter(secretClass.Name, notAfter)
+	logger.V(1).Info("exported certificate authority expiry", "certificateAuthorities", len(notAfter))
+
+	return ctrl.Result{RequeueAfter: r.interval()}, nil
+}
+
+func (r *CAMetricsReconciler) interval() time.Duration {
+	if r.Interval <= 0 {
+		return DefaultCAMetricsInterval
+	}
+	return r.Interval
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *CAMetricsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("ca-metrics").
+		For(&secretvs1alpha1.SecretClass{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
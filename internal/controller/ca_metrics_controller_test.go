@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	secretvs1alpha1 "github.com/zncdatadev/secret-operator/api/v1alpha1"
+	"github.com/zncdatadev/secret-operator/internal/csi/backend/ca"
+	"github.com/zncdatadev/secret-operator/internal/metrics"
+)
+
+func TestCAMetricsReconcile(t *testing.T) {
+	notAfter := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	authority, err := ca.NewSelfSignedCertificateAuthority(notAfter, nil, nil, 2048)
+	if err != nil {
+		t.Fatalf("NewSelfSignedCertificateAuthority() error = %v", err)
+	}
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = secretvs1alpha1.AddToScheme(scheme)
+
+	secretClass := &secretvs1alpha1.SecretClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "tls-metrics"},
+		Spec: secretvs1alpha1.SecretClassSpec{Backend: &secretvs1alpha1.BackendSpec{
+			AutoTls: &secretvs1alpha1.AutoTlsSpec{
+				CA: &secretvs1alpha1.CASpec{Secret: &secretvs1alpha1.SecretSpec{Name: "tls-ca", Namespace: "default"}},
+			},
+		}},
+	}
+	caSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "tls-ca", Namespace: "default"},
+		Data: map[string][]byte{
+			// the private key is not read, it may be sealed
+			"0.ca.crt": authority.CertificatePEM(),
+			"0.ca.key": []byte("sealed"),
+			// certificates without a private key are not certificate authorities of the secret class
+			"extra.crt": authority.CertificatePEM(),
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secretClass, caSecret).Build()
+	r := &CAMetricsReconciler{Client: c, Scheme: scheme}
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: secretClass.Name}}
+
+	result, err := r.Reconcile(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if result.RequeueAfter != DefaultCAMetricsInterval {
+		t.Errorf("Reconcile() requeueAfter = %v, want %v", result.RequeueAfter, DefaultCAMetricsInterval)
+	}
+	gauge := metrics.CACertificateNotAfter.WithLabelValues(secretClass.Name, authority.SerialNumber())
+	if got := testutil.ToFloat64(gauge); got != float64(notAfter.Unix()) {
+		t.Errorf("ca certificate not after = %v, want %v", got, notAfter.Unix())
+	}
+
+	// the series of a deleted secret class are removed
+	if err := c.Delete(context.Background(), secretClass); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := r.Reconcile(context.Background(), request); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if got := testutil.CollectAndCount(metrics.CACertificateNotAfter); got != 0 {
+		t.Errorf("ca certificate not after series = %d, want 0 after the secret class was deleted", got)
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2024 zncdatadev.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	secretvs1alpha1 "github.com/zncdatadev/secret-operator/api/v1alpha1"
+	"github.com/zncdatadev/secret-operator/internal/csi/backend"
+)
+
+const (
+	DefaultPrincipalGCInterval = 10 * time.Minute
+)
+
+// PrincipalGCReconciler periodically deletes the kerberos principals which are no longer used
+// by any pod or service, for every SecretClass with `kerberosKeytab.principalGc` set.
+// It talks to kadmin, so it must run in the csi driver image which ships the kerberos client tools.
+type PrincipalGCReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Interval between two collections of a SecretClass.
+	Interval time.Duration
+}
+
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch
+
+func (r *PrincipalGCReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	secretClass := &secretvs1alpha1.SecretClass{}
+	if err := r.Get(ctx, req.NamespacedName, secretClass); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !principalGCEnabled(secretClass) {
+		return ctrl.Result{}, nil
+	}
+
+	gc, err := backend.NewPrincipalGarbageCollector(r.Client, secretClass)
+	if err != nil {
+		logger.Error(err, "invalid principal gc configuration")
+		return ctrl.Result{}, nil
+	}
+
+	deleted, err := gc.Collect(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	logger.V(1).Info("collected orphaned principals", "principals", deleted, "dryRun", secretClass.Spec.Backend.KerberosKeytab.PrincipalGC.DryRun)
+
+	return ctrl.Result{RequeueAfter: r.interval()}, nil
+}
+
+func (r *PrincipalGCReconciler) interval() time.Duration {
+	if r.Interval <= 0 {
+		return DefaultPrincipalGCInterval
+	}
+	return r.Interval
+}
+
+func principalGCEnabled(secretClass *secretvs1alpha1.SecretClass) bool {
+	backend := secretClass.Spec.Backend
+	return backend != nil && backend.KerberosKeytab != nil && backend.KerberosKeytab.PrincipalGC != nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PrincipalGCReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("principal-gc").
+		For(&secretvs1alpha1.SecretClass{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
@@ -0,0 +1,289 @@
+/*
+Copyright 2024 zncdatadev.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/zncdatadev/operator-go/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	secretvs1alpha1 "github.com/zncdatadev/secret-operator/api/v1alpha1"
+	"github.com/zncdatadev/secret-operator/internal/csi/backend"
+	"github.com/zncdatadev/secret-operator/internal/csi/backend/ca"
+)
+
+const (
+	DefaultTrustBundleInterval = time.Hour
+
+	// LabelTrustBundle marks the ConfigMaps of trust bundles, their SecretClass is the
+	// `secrets.kubedoop.dev/class` annotation.
+	LabelTrustBundle = "secrets.kubedoop.dev/trust-bundle"
+
+	TrustBundleCAKey = "ca.crt"
+)
+
+// TrustBundleReconciler publishes the trust anchors of every AutoTls SecretClass with `autoTls.trustBundle` set,
+// the certificate authorities including rotated ones and the additional trust roots, to ConfigMaps in the
+// selected namespaces. Certificate authorities are removed from the bundle once expired, certificates are not
+// issued beyond the expiry of their certificate authority.
+// New certificate authorities are published within the interval, long before they issue certificates.
+type TrustBundleReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Interval between two publications of a SecretClass.
+	Interval time.Duration
+}
+
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+
+func (r *TrustBundleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	secretClass := &secretvs1alpha1.SecretClass{}
+	if err := r.Get(ctx, req.NamespacedName, secretClass); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, r.deleteConfigMaps(ctx, req.Name, nil)
+	}
+
+	if !trustBundleEnabled(secretClass) {
+		return ctrl.Result{}, r.deleteConfigMaps(ctx, secretClass.Name, nil)
+	}
+	autotls := secretClass.Spec.Backend.AutoTls
+	configMapName := trustBundleConfigMapName(secretClass)
+
+	selector, err := metav1.LabelSelectorAsSelector(autotls.TrustBundle.NamespaceSelector)
+	if err != nil {
+		logger.Error(err, "invalid trust bundle namespace selector")
+		return ctrl.Result{}, nil
+	}
+
+	certManager, err := backend.NewAutoTlsCertificateManager(ctx, r.Client, secretClass.Name, autotls)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	trustAnchors, err := certManager.GetTrustAnchors(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	bundle, nextExpiry := trustBundle(trustAnchors, time.Now())
+	if len(bundle) == 0 {
+		return ctrl.Result{}, errors.New("no valid trust anchors for the trust bundle")
+	}
+
+	namespaces := &corev1.NamespaceList{}
+	if err := r.List(ctx, namespaces, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return ctrl.Result{}, err
+	}
+	selected := make(map[string]bool, len(namespaces.Items))
+	for _, namespace := range namespaces.Items {
+		if namespace.DeletionTimestamp != nil {
+			continue
+		}
+		selected[namespace.Name] = true
+		if err := r.publish(ctx, secretClass.Name, types.NamespacedName{Name: configMapName, Namespace: namespace.Name}, bundle); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// remove the bundles of unselected namespaces, or with a former name
+	if err := r.deleteConfigMaps(ctx, secretClass.Name, func(configMap *corev1.ConfigMap) bool {
+		return selected[configMap.Namespace] && configMap.Name == configMapName
+	}); err != nil {
+		return ctrl.Result{}, err
+	}
+	logger.V(1).Info("published trust bundle", "namespaces", len(selected), "nextExpiry", nextExpiry)
+
+	requeueAfter := r.interval()
+	if untilExpiry := time.Until(nextExpiry); untilExpiry < requeueAfter {
+		requeueAfter = untilExpiry + time.Second
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// publish creates or updates the trust bundle ConfigMap, ConfigMaps not created by the operator are left untouched.
+func (r *TrustBundleReconciler) publish(ctx context.Context, class string, key types.NamespacedName, bundle []byte) error {
+	logger := log.FromContext(ctx)
+
+	return retry.OnError(retry.DefaultRetry, func(err error) bool {
+		return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+	}, func() error {
+		configMap := &corev1.ConfigMap{}
+		if err := r.Get(ctx, key, configMap); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			configMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      key.Name,
+					Namespace: key.Namespace,
+					Labels: map[string]string{
+						constants.LabelKubernetesManagedBy: "secret-operator",
+						LabelTrustBundle:                   "true",
+					},
+					Annotations: map[string]string{
+						constants.AnnotationSecretsClass: class,
+					},
+				},
+				Data: map[string]string{TrustBundleCAKey: string(bundle)},
+			}
+			if err := r.Create(ctx, configMap); err != nil {
+				return err
+			}
+			logger.V(1).Info("created trust bundle", "name", key.Name, "namespace", key.Namespace)
+			return nil
+		}
+
+		if !isTrustBundleOf(configMap, class) {
+			logger.Info("configmap exists and is not a trust bundle of the secret class, skip it", "name", key.Name, "namespace", key.Namespace)
+			return nil
+		}
+		if configMap.Data[TrustBundleCAKey] == string(bundle) {
+			return nil
+		}
+
+		configMap.Data = map[string]string{TrustBundleCAKey: string(bundle)}
+		if err := r.Update(ctx, configMap); err != nil {
+			return err
+		}
+		logger.Info("updated trust bundle", "name", key.Name, "namespace", key.Namespace)
+		return nil
+	})
+}
+
+// deleteConfigMaps deletes the trust bundle ConfigMaps of the secret class, except those to keep.
+func (r *TrustBundleReconciler) deleteConfigMaps(ctx context.Context, class string, keep func(*corev1.ConfigMap) bool) error {
+	configMaps := &corev1.ConfigMapList{}
+	if err := r.List(ctx, configMaps, client.MatchingLabels{LabelTrustBundle: "true"}); err != nil {
+		return err
+	}
+
+	for i := range configMaps.Items {
+		configMap := &configMaps.Items[i]
+		if !isTrustBundleOf(configMap, class) || (keep != nil && keep(configMap)) {
+			continue
+		}
+		if err := r.Delete(ctx, configMap); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		log.FromContext(ctx).Info("deleted trust bundle", "name", configMap.Name, "namespace", configMap.Namespace)
+	}
+	return nil
+}
+
+func (r *TrustBundleReconciler) interval() time.Duration {
+	if r.Interval <= 0 {
+		return DefaultTrustBundleInterval
+	}
+	return r.Interval
+}
+
+func isTrustBundleOf(configMap *corev1.ConfigMap, class string) bool {
+	return configMap.Labels[LabelTrustBundle] == "true" && configMap.Annotations[constants.AnnotationSecretsClass] == class
+}
+
+func trustBundleEnabled(secretClass *secretvs1alpha1.SecretClass) bool {
+	backend := secretClass.Spec.Backend
+	return backend != nil && backend.AutoTls != nil && backend.AutoTls.TrustBundle != nil
+}
+
+func trustBundleConfigMapName(secretClass *secretvs1alpha1.SecretClass) string {
+	if name := secretClass.Spec.Backend.AutoTls.TrustBundle.ConfigMapName; name != "" {
+		return name
+	}
+	return secretClass.Name + "-trust-bundle"
+}
+
+// trustBundle returns the PEM bundle of the unexpired trust anchors ordered by expiry, so the bundle only changes
+// when the trust anchors do, and the time the first of them expires.
+func trustBundle(trustAnchors []*ca.Certificate, now time.Time) ([]byte, time.Time) {
+	valid := slices.DeleteFunc(slices.Clone(trustAnchors), func(cert *ca.Certificate) bool {
+		return !cert.Certificate.NotAfter.After(now)
+	})
+	if len(valid) == 0 {
+		return nil, time.Time{}
+	}
+
+	slices.SortFunc(valid, func(a, b *ca.Certificate) int {
+		if c := a.Certificate.NotAfter.Compare(b.Certificate.NotAfter); c != 0 {
+			return c
+		}
+		return bytes.Compare(a.Certificate.Raw, b.Certificate.Raw)
+	})
+
+	var bundle []byte
+	for _, cert := range valid {
+		bundle = append(bundle, cert.CertificatePEM()...)
+	}
+	return bundle, valid[0].Certificate.NotAfter
+}
+
+// trustBundleSecretClasses returns the SecretClasses with a trust bundle, to publish them to new namespaces.
+func (r *TrustBundleReconciler) trustBundleSecretClasses(ctx context.Context, _ client.Object) []reconcile.Request {
+	secretClasses := &secretvs1alpha1.SecretClassList{}
+	if err := r.List(ctx, secretClasses); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list secret classes")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range secretClasses.Items {
+		if trustBundleEnabled(&secretClasses.Items[i]) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: secretClasses.Items[i].Name}})
+		}
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// The manager should restrict its ConfigMap cache to LabelTrustBundle, the watch only needs trust bundles.
+func (r *TrustBundleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("trust-bundle").
+		For(&secretvs1alpha1.SecretClass{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.trustBundleSecretClasses),
+			builder.WithPredicates(predicate.LabelChangedPredicate{})).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+			// restore trust bundles changed or deleted by others
+			class := obj.GetAnnotations()[constants.AnnotationSecretsClass]
+			if obj.GetLabels()[LabelTrustBundle] != "true" || class == "" {
+				return nil
+			}
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: class}}}
+		})).
+		Complete(r)
+}
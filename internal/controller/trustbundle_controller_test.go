@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/zncdatadev/secret-operator/internal/csi/backend/ca"
+)
+
+func newTestTrustAnchor(t *testing.T, notAfter time.Time) *ca.Certificate {
+	t.Helper()
+	authority, err := ca.NewSelfSignedCertificateAuthority(notAfter, nil, nil, 2048)
+	if err != nil {
+		t.Fatalf("NewSelfSignedCertificateAuthority() error = %v", err)
+	}
+	return authority.PublicCertificate()
+}
+
+func TestTrustBundle(t *testing.T) {
+	now := time.Now()
+	rotated := newTestTrustAnchor(t, now.Add(48*time.Hour))
+	current := newTestTrustAnchor(t, now.Add(24*time.Hour))
+	additional := newTestTrustAnchor(t, now.Add(72*time.Hour))
+
+	bundle, nextExpiry := trustBundle([]*ca.Certificate{rotated, additional, current}, now)
+
+	var got []string
+	for rest := bundle; ; {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+		got = append(got, string(block.Bytes))
+	}
+	want := []string{string(current.Certificate.Raw), string(rotated.Certificate.Raw), string(additional.Certificate.Raw)}
+	if len(got) != len(want) {
+		t.Fatalf("trustBundle() certificates = %d, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("trustBundle() certificate %d is not ordered by expiry", i)
+		}
+	}
+	if !nextExpiry.Equal(current.Certificate.NotAfter) {
+		t.Errorf("trustBundle() nextExpiry = %v, want %v", nextExpiry, current.Certificate.NotAfter)
+	}
+
+	// the expired certificate authority is removed
+	bundle, nextExpiry = trustBundle([]*ca.Certificate{rotated, additional, current}, now.Add(36*time.Hour))
+	if block, _ := pem.Decode(bundle); block == nil || string(block.Bytes) != string(rotated.Certificate.Raw) {
+		t.Errorf("trustBundle() first certificate is not the rotated certificate authority")
+	}
+	if !nextExpiry.Equal(rotated.Certificate.NotAfter) {
+		t.Errorf("trustBundle() nextExpiry = %v, want %v", nextExpiry, rotated.Certificate.NotAfter)
+	}
+
+	if bundle, _ := trustBundle([]*ca.Certificate{current}, now.Add(36*time.Hour)); len(bundle) != 0 {
+		t.Errorf("trustBundle() of expired trust anchors = %q, want empty", bundle)
+	}
+}
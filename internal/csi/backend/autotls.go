@@ -12,9 +12,11 @@ import (
 	"github.com/zncdatadev/secret-operator/pkg/util"
 	"github.com/zncdatadev/secret-operator/pkg/volume"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	pkcs12 "software.sslmate.com/src/go-pkcs12"
 
 	secretsv1alpha1 "github.com/zncdatadev/secret-operator/api/v1alpha1"
 	"github.com/zncdatadev/secret-operator/internal/csi/backend/ca"
+	"github.com/zncdatadev/secret-operator/internal/metrics"
 )
 
 const (
@@ -38,6 +40,7 @@ type AutoTlsBackend struct {
 	podInfo                *pod_info.PodInfo
 	volumeContext          *volume.SecretVolumeContext
 	maxCertificateLifeTime time.Duration
+	class                  string
 
 	ca          *secretsv1alpha1.CASpec
 	certManager ca.CertificateManager
@@ -52,6 +55,36 @@ func NewAutoTlsBackend(config *BackendConfig) (IBackend, error) {
 		return nil, err
 	}
 
+	certManager, err := NewAutoTlsCertificateManager(config.ctx, config.Client, config.SecretClass.Name, autotls)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AutoTlsBackend{
+		client:                 config.Client,
+		podInfo:                config.PodInfo,
+		volumeContext:          config.VolumeContext,
+		maxCertificateLifeTime: maxCertificateLifeTime,
+		class:                  config.SecretClass.Name,
+		ca:                     autotls.CA,
+
+		certManager: certManager,
+	}, nil
+}
+
+// NewAutoTlsCertificateManager returns the certificate manager of an AutoTls SecretClass, cached across volumes,
+// it also serves the trust anchors to the trust bundle controller.
+func NewAutoTlsCertificateManager(
+	ctx context.Context,
+	c client.Client,
+	class string,
+	autotls *secretsv1alpha1.AutoTlsSpec,
+) (ca.CertificateManager, error) {
+	maxCertificateLifeTime, err := time.ParseDuration(autotls.MaxCertificateLifeTime)
+	if err != nil {
+		return nil, err
+	}
+
 	// ca certificate life time come from secret class
 	// it is used to calculate the ca certificate lifetime when generate the ca certificate
 	caCertificateLifeTime, err := time.ParseDuration(autotls.CA.CACertificateLifeTime)
@@ -65,29 +98,19 @@ func NewAutoTlsBackend(config *BackendConfig) (IBackend, error) {
 		rsaKeyLength = autotls.CA.KeyGeneration.RSA.Length
 	}
 
-	certManager, err := ca.NewCertificateManager(
-		config.ctx,
-		config.Client,
-		maxCertificateLifeTime,
-		caCertificateLifeTime,
-		autotls.CA.AutoGenerate,
-		autotls.CA.Secret,
-		autotls.AdditionalTrustRoots,
-		rsaKeyLength,
-	)
-	if err != nil {
-		return nil, err
+	if autotls.CA.Secret == nil {
+		return nil, fmt.Errorf("ca secret is nil in autoTls backend")
 	}
 
-	return &AutoTlsBackend{
-		client:                 config.Client,
-		podInfo:                config.PodInfo,
-		volumeContext:          config.VolumeContext,
-		maxCertificateLifeTime: maxCertificateLifeTime,
-		ca:                     autotls.CA,
-
-		certManager: certManager,
-	}, nil
+	return ca.GetCertificateManager(ctx, c, class, &ca.CertificateManagerConfig{
+		MaxCertificateLifeTime: maxCertificateLifeTime,
+		CACertificateLifeTime:  caCertificateLifeTime,
+		AutoGenerate:           autotls.CA.AutoGenerate,
+		CASecret:               *autotls.CA.Secret,
+		AdditionalTrustRoots:   autotls.AdditionalTrustRoots,
+		RSAKeyLength:           rsaKeyLength,
+		KeyEncryption:          autotls.CA.KeyEncryption,
+	})
 }
 
 // use AutoTlsCertLifetime and AutoTlsCertJitterFactor to calculate the certificate lifetime
@@ -202,6 +225,10 @@ func (k *AutoTlsBackend) GetQualifiedNodeNames(ctx context.Context) ([]string, e
 }
 
 func (a *AutoTlsBackend) GetSecretData(ctx context.Context) (*util.SecretContent, error) {
+	if a.certificateFormat() == volume.SecretFormatTLSTruststore {
+		return a.getTruststoreData(ctx)
+	}
+
 	addresses, err := a.getAddresses(ctx)
 	if err != nil {
 		return nil, err
@@ -219,6 +246,7 @@ func (a *AutoTlsBackend) GetSecretData(ctx context.Context) (*util.SecretContent
 	if err != nil {
 		return nil, err
 	}
+	metrics.CertificatesIssued.WithLabelValues(string(AutoTlsType), a.class).Inc()
 
 	logger.V(1).Info("signed certificate", "notAfter", notAfter, "addresses", addresses, "certLife", certLife, "certSerialNumber", cert.SerialNumber())
 
@@ -244,3 +272,48 @@ func (a *AutoTlsBackend) GetSecretData(ctx context.Context) (*util.SecretContent
 func (a *AutoTlsBackend) getAddresses(ctx context.Context) ([]pod_info.Address, error) {
 	return a.podInfo.GetScopedAddresses(ctx)
 }
+
+// getTruststoreData returns only the trust anchors, as `ca.crt` and `truststore.p12`, for clients trusting the
+// certificates of the SecretClass. Nothing is signed, so the volume does not need a scope.
+func (a *AutoTlsBackend) getTruststoreData(ctx context.Context) (*util.SecretContent, error) {
+	trustAnchors, err := a.certManager.GetTrustAnchors(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	caCerts := make([]*x509.Certificate, 0, len(trustAnchors))
+	pemCACerts := make([]string, 0, len(trustAnchors))
+	for _, caCert := range trustAnchors {
+		caCerts = append(caCerts, caCert.Certificate)
+		pemCACerts = append(pemCACerts, string(caCert.CertificatePEM()))
+	}
+
+	truststore, err := pkcs12.Modern.EncodeTrustStore(caCerts, a.volumeContext.TlsPKCS12Password)
+	if err != nil {
+		return nil, err
+	}
+
+	content := &util.SecretContent{
+		Data: map[string]string{
+			PEMCaCertFileName:     strings.Join(pemCACerts, "\n"),
+			TruststoreP12FileName: string(truststore),
+		},
+	}
+
+	// Certificates are signed by a certificate authority missing in the truststore once the newest one
+	// expires within the max certificate lifetime, the pod is restarted before to get the new one.
+	restarterBuffer := a.volumeContext.AutoTlsCertRestartBuffer
+	if restarterBuffer == 0 {
+		restarterBuffer = DefaultCertBuffer
+	}
+	restartAt := a.certManager.NewestCertificateAuthorityNotAfter().Add(-a.maxCertificateLifeTime - restarterBuffer)
+	if restartAt.After(time.Now()) {
+		content.ExpiresTime = &restartAt
+	} else {
+		logger.V(0).Info("newest certificate authority expires within the max certificate lifetime, truststore is not refreshed",
+			"class", a.volumeContext.Class, "maxCertificateLifeTime", a.maxCertificateLifeTime)
+	}
+
+	logger.V(1).Info("got truststore", "trustAnchors", len(trustAnchors), "expiresTime", content.ExpiresTime)
+	return content, nil
+}
@@ -0,0 +1,79 @@
+package backend
+
+import (
+	"context"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	pkcs12 "software.sslmate.com/src/go-pkcs12"
+
+	"github.com/zncdatadev/secret-operator/internal/csi/backend/ca"
+	"github.com/zncdatadev/secret-operator/pkg/pod_info"
+	"github.com/zncdatadev/secret-operator/pkg/volume"
+)
+
+type testCertificateManager struct {
+	ca *ca.CertificateAuthority
+}
+
+func (t *testCertificateManager) GetTrustAnchors(ctx context.Context) ([]*ca.Certificate, error) {
+	return []*ca.Certificate{{Certificate: t.ca.Certificate}}, nil
+}
+
+func (t *testCertificateManager) NewestCertificateAuthorityNotAfter() time.Time {
+	return t.ca.Certificate.NotAfter
+}
+
+func (t *testCertificateManager) SignServerCertificate(addresses []pod_info.Address, notAfter time.Time) (*ca.Certificate, error) {
+	return t.ca.SignServerCertificate(addresses, notAfter)
+}
+
+func (t *testCertificateManager) SignClientCertificate(addresses []pod_info.Address, notAfter time.Time) (*ca.Certificate, error) {
+	return t.ca.SignClientCertificate(addresses, notAfter)
+}
+
+func TestAutoTlsTruststore(t *testing.T) {
+	caNotAfter := time.Now().Add(365 * 24 * time.Hour).Truncate(time.Second)
+	authority, err := ca.NewSelfSignedCertificateAuthority(caNotAfter, nil, nil, 2048)
+	if err != nil {
+		t.Fatalf("NewSelfSignedCertificateAuthority() error = %v", err)
+	}
+
+	maxCertificateLifeTime := 15 * 24 * time.Hour
+	backend := &AutoTlsBackend{
+		// no pod info, a truststore does not need the scoped addresses
+		volumeContext: &volume.SecretVolumeContext{
+			Class:             "tls",
+			Format:            volume.SecretFormatTLSTruststore,
+			TlsPKCS12Password: "changeit",
+		},
+		maxCertificateLifeTime: maxCertificateLifeTime,
+		certManager:            &testCertificateManager{ca: authority},
+	}
+
+	content, err := backend.GetSecretData(context.Background())
+	if err != nil {
+		t.Fatalf("GetSecretData() error = %v", err)
+	}
+
+	if len(content.Data) != 2 {
+		t.Errorf("GetSecretData() files = %d, want ca.crt and truststore.p12 only", len(content.Data))
+	}
+	block, _ := pem.Decode([]byte(content.Data[PEMCaCertFileName]))
+	if block == nil || string(block.Bytes) != string(authority.Certificate.Raw) {
+		t.Errorf("ca.crt is not the certificate authority")
+	}
+	certs, err := pkcs12.DecodeTrustStore([]byte(content.Data[TruststoreP12FileName]), "changeit")
+	if err != nil {
+		t.Fatalf("DecodeTrustStore() error = %v", err)
+	}
+	if len(certs) != 1 || !certs[0].Equal(authority.Certificate) {
+		t.Errorf("truststore.p12 certificates = %d, want the certificate authority", len(certs))
+	}
+
+	wantExpires := authority.Certificate.NotAfter.Add(-maxCertificateLifeTime - DefaultCertBuffer)
+	if content.ExpiresTime == nil || !content.ExpiresTime.Equal(wantExpires) {
+		t.Errorf("GetSecretData() expiresTime = %v, want %v", content.ExpiresTime, wantExpires)
+	}
+}
@@ -23,6 +23,10 @@ const (
 	KerberosKeytabType BackendType = "KerberosKeytab"
 	AutoTlsType        BackendType = "AutoTls"
 	K8sSearchType      BackendType = "K8sSearch"
+	SSHCAType          BackendType = "SSHCA"
+	JWTType            BackendType = "JWT"
+	CompositeType      BackendType = "Composite"
+	PluginType         BackendType = "Plugin"
 )
 
 type BackendConfig struct {
@@ -47,10 +51,19 @@ func NewBackend(ctx context.Context, c client.Client, podInfo *pod_info.PodInfo,
 	if err := c.Get(ctx, client.ObjectKey{Name: volumeCtx.Class}, secretClass); err != nil {
 		return nil, err
 	}
+	return newBackendFromSecretClass(ctx, c, podInfo, volumeCtx, secretClass)
+}
 
+func newBackendFromSecretClass(
+	ctx context.Context,
+	c client.Client,
+	podInfo *pod_info.PodInfo,
+	volumeCtx *volume.SecretVolumeContext,
+	secretClass *secretsv1alpha1.SecretClass,
+) (*Backend, error) {
 	config := &BackendConfig{ctx: ctx, Client: c, PodInfo: podInfo, VolumeContext: volumeCtx, SecretClass: secretClass}
 
-	backendType := determineBackendType(config.SecretClass)
+	backendType := DetermineBackendType(config.SecretClass)
 	impl, err := CreateBackend(backendType, config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create backend: %w", err)
@@ -58,7 +71,8 @@ func NewBackend(ctx context.Context, c client.Client, podInfo *pod_info.PodInfo,
 	return &Backend{impl: impl}, nil
 }
 
-func determineBackendType(secretClass *secretsv1alpha1.SecretClass) BackendType {
+// DetermineBackendType returns the type of the backend of the SecretClass, empty when it has none.
+func DetermineBackendType(secretClass *secretsv1alpha1.SecretClass) BackendType {
 	backend := secretClass.Spec.Backend
 
 	if backend.KerberosKeytab != nil {
@@ -70,6 +84,18 @@ func determineBackendType(secretClass *secretsv1alpha1.SecretClass) BackendType
 	if backend.K8sSearch != nil {
 		return K8sSearchType
 	}
+	if backend.SSHCA != nil {
+		return SSHCAType
+	}
+	if backend.JWT != nil {
+		return JWTType
+	}
+	if backend.Composite != nil {
+		return CompositeType
+	}
+	if backend.Plugin != nil {
+		return PluginType
+	}
 	return ""
 }
 
@@ -111,4 +137,8 @@ func init() {
 	RegisterBackend(KerberosKeytabType, NewKerberosBackend)
 	RegisterBackend(AutoTlsType, NewAutoTlsBackend)
 	RegisterBackend(K8sSearchType, NewK8sSearchBackend)
+	RegisterBackend(SSHCAType, NewSSHCABackend)
+	RegisterBackend(JWTType, NewJWTBackend)
+	RegisterBackend(CompositeType, NewCompositeBackend)
+	RegisterBackend(PluginType, NewPluginBackend)
 }
@@ -72,6 +72,10 @@ type CertificateAuthority struct {
 	Certificate  *x509.Certificate
 	privateKey   *rsa.PrivateKey
 	rsaKeyLength int
+
+	// storedPrivateKey is the private key as stored in the CA secret, plain or sealed,
+	// it is saved again as is so unchanged certificate authorities do not change the secret.
+	storedPrivateKey []byte
 }
 
 func NewCertificateAuthorityFromData(
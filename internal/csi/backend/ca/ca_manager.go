@@ -1,11 +1,13 @@
 package ca
 
 import (
+	"bytes"
 	"context"
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"maps"
 	"slices"
 	"strconv"
 	"strings"
@@ -19,16 +21,33 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	secretsv1alpha1 "github.com/zncdatadev/secret-operator/api/v1alpha1"
+	"github.com/zncdatadev/secret-operator/internal/csi/backend/kms"
 	"github.com/zncdatadev/secret-operator/pkg/pod_info"
 )
 
 var (
-	logger  = ctrl.Log.WithName("ca-manager")
-	caMutex sync.Mutex
+	logger = ctrl.Log.WithName("ca-manager")
+
+	// caSecretLocks serialize the loading of certificate authorities by CA secret, so concurrent volumes
+	// of a SecretClass do not rotate the same certificate authority twice.
+	caSecretLocks sync.Map
+
+	// configMapMetadataReader reads the metadata of ConfigMaps, usually from a metadata-only informer cache.
+	// When set, additional trust roots of ConfigMaps are only read again when their resource version changes.
+	configMapMetadataReader client.Reader
 )
 
+// SetConfigMapMetadataReader sets the reader of the ConfigMap metadata used for additional trust roots.
+func SetConfigMapMetadataReader(reader client.Reader) {
+	configMapMetadataReader = reader
+}
+
 type CertificateManager interface {
 	GetTrustAnchors(ctx context.Context) ([]*Certificate, error)
+	// NewestCertificateAuthorityNotAfter returns the expiry of the newest certificate authority,
+	// certificates are signed by certificate authorities unknown to the current trust anchors
+	// once it expires within the max certificate lifetime.
+	NewestCertificateAuthorityNotAfter() time.Time
 	SignServerCertificate(addresses []pod_info.Address, notAfter time.Time) (*Certificate, error)
 	SignClientCertificate(addresses []pod_info.Address, notAfter time.Time) (*Certificate, error)
 }
@@ -42,57 +61,67 @@ type certificateManager struct {
 	auto                   bool
 	additionalTrustRoots   []secretsv1alpha1.AdditionalTrustRootSpec
 	rsaKeyLength           int
+	// keyEncryption seals the private keys in the CA secret, nil stores them as plain PEM
+	keyEncryption kms.Service
+	// keyID is the id of the key encryption key the private keys are sealed with
+	keyID string
 
 	caSecret *corev1.Secret
+	// observedResourceVersion is the resource version of the CA secret before the manager saved it,
+	// the informer cache may still return it shortly after.
+	observedResourceVersion string
+
+	cas []*CertificateAuthority
 
-	selectedCA *CertificateAuthority
-	cas        []*CertificateAuthority
+	trustRootsMutex sync.Mutex
+	// trustRoots are the parsed additional trust roots by source, with the resource version they were parsed from
+	trustRoots map[string]parsedTrustRoots
 }
 
-// NewCertificateManager creates a new CertificateManager
+type parsedTrustRoots struct {
+	resourceVersion string
+	certs           []*Certificate
+}
+
+// newCertificateManager creates a new certificateManager
 // Get pem key pairs from a secret.
 // If the secret does not exist, and auto is enabled, it will create a new self-signed certificate authority.
 // If the secret does not exist, and auto is disabled, return error.
 // If the secret exists, get certificate authorities from the secret.
 // Now, pem key supports only RSA 256.
-func NewCertificateManager(
-	ctx context.Context,
-	client client.Client,
-	maxCertificateLifeTime time.Duration,
-	caCertificateLifetime time.Duration,
-	auto bool,
-	caSecretSpec *secretsv1alpha1.SecretSpec,
-	additionalTrustRoots []secretsv1alpha1.AdditionalTrustRootSpec,
-	rsaKeyLength int,
-
-) (CertificateManager, error) {
-
-	caSecret, err := getSecret(ctx, client, caSecretSpec.Name, caSecretSpec.Namespace)
-	if err != nil {
-		return nil, err
+func newCertificateManager(ctx context.Context, client client.Client, config *CertificateManagerConfig) (*certificateManager, error) {
+	cm := &certificateManager{
+		client:                 client,
+		maxCertificateLifeTime: config.MaxCertificateLifeTime,
+		caCertificateLifetime:  config.CACertificateLifeTime,
+		auto:                   config.AutoGenerate,
+		additionalTrustRoots:   config.AdditionalTrustRoots,
+		cas:                    []*CertificateAuthority{},
+		rsaKeyLength:           config.RSAKeyLength,
+		trustRoots:             make(map[string]parsedTrustRoots),
 	}
 
-	cm := &certificateManager{
-		client:                client,
-		caCertificateLifetime: caCertificateLifetime,
-		auto:                  auto,
-		caSecret:              caSecret,
-		cas:                   []*CertificateAuthority{},
-		rsaKeyLength:          rsaKeyLength,
+	if config.KeyEncryption != nil {
+		// readers of the CA secret would read the key encryption key as well
+		if config.KeyEncryption.Secret != nil && config.KeyEncryption.Secret.Namespace == config.CASecret.Namespace {
+			return nil, fmt.Errorf("key encryption key secret %s/%s must be in another namespace than the CA secret",
+				config.KeyEncryption.Secret.Namespace, config.KeyEncryption.Secret.Name)
+		}
+		keyEncryption, err := kms.NewService(ctx, client, config.KeyEncryption)
+		if err != nil {
+			return nil, err
+		}
+		cm.keyEncryption = keyEncryption
 	}
 
-	ca, err := cm.getCertificateAuthority(ctx)
-	if err != nil {
+	if err := cm.loadCertificateAuthorities(ctx, &config.CASecret); err != nil {
 		return nil, err
 	}
-
-	// init ca immediately
-	cm.selectedCA = ca
-
 	return cm, nil
 }
 
 func (c *certificateManager) updateSecret(ctx context.Context, data map[string][]byte) error {
+	c.caSecret = c.caSecret.DeepCopy()
 	c.caSecret.Data = data
 	// if server side object has been modified, it will raise a conflict error
 	// we should get the latest object and retry from the beginning.
@@ -104,7 +133,7 @@ func (c *certificateManager) updateSecret(ctx context.Context, data map[string][
 }
 
 func (c *certificateManager) secretCreateIfDoesNotExist(ctx context.Context) error {
-	if c.caSecret.UID != "" {
+	if c.caSecret.ResourceVersion != "" {
 		return nil
 	}
 
@@ -118,7 +147,7 @@ func (c *certificateManager) secretCreateIfDoesNotExist(ctx context.Context) err
 
 }
 
-func (c certificateManager) getPEMKeyPairsFromSecret() []PEMkeyPair {
+func (c *certificateManager) getPEMKeyPairsFromSecret() []PEMkeyPair {
 	var keyPairs []PEMkeyPair
 
 	if len(c.caSecret.Data) == 0 {
@@ -139,31 +168,107 @@ func (c certificateManager) getPEMKeyPairsFromSecret() []PEMkeyPair {
 	return keyPairs
 }
 
-func (c *certificateManager) updateCertificateAuthoritiesToSecret(ctx context.Context, cas []*CertificateAuthority) error {
-	if !c.auto {
-		return fmt.Errorf("could not save certificate authorities, because auto is %s, this will overwrite the existing certificate authorities",
-			strconv.FormatBool(c.auto),
-		)
-	}
-
-	if err := c.secretCreateIfDoesNotExist(ctx); err != nil {
-		return err
+// CertificateAuthoritiesNotAfter returns the expiry of the certificate authorities in the CA secret by serial number.
+// Only the certificates are parsed, the private keys are not decrypted.
+func CertificateAuthoritiesNotAfter(secret *corev1.Secret) (map[string]time.Time, error) {
+	notAfter := make(map[string]time.Time)
+	for name, certPEM := range secret.Data {
+		prefix, ok := strings.CutSuffix(name, ".crt")
+		if !ok {
+			continue
+		}
+		if _, ok := secret.Data[prefix+".key"]; !ok {
+			continue
+		}
+		block, _ := pem.Decode(certPEM)
+		if block == nil {
+			return nil, fmt.Errorf("invalid certificate %s in secret %s/%s", name, secret.Namespace, secret.Name)
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate %s in secret %s/%s: %w", name, secret.Namespace, secret.Name, err)
+		}
+		notAfter[formatSerialNumber(cert.SerialNumber)] = cert.NotAfter
 	}
+	return notAfter, nil
+}
 
+// updateCertificateAuthoritiesToSecret saves the certificate authorities when they changed,
+// e.g. after a rotation. Secrets of manually managed certificate authorities are never written.
+func (c *certificateManager) updateCertificateAuthoritiesToSecret(ctx context.Context, cas []*CertificateAuthority) error {
 	c.sort(cas)
 
 	data := map[string][]byte{}
 	for i, ca := range cas {
+		privateKey, err := c.storePrivateKey(ctx, ca)
+		if err != nil {
+			return err
+		}
 		prefix := strconv.Itoa(i)
 		data[prefix+".ca.crt"] = ca.CertificatePEM()
-		data[prefix+".ca.key"] = ca.privateKeyPEM()
+		data[prefix+".ca.key"] = privateKey
 	}
 
-	if err := c.updateSecret(ctx, data); err != nil {
-		return err
+	if c.caSecret.ResourceVersion != "" && maps.EqualFunc(c.caSecret.Data, data, bytes.Equal) {
+		logger.V(5).Info("certificate authorities are unchanged, skip saving", "name", c.caSecret.Name, "namespace", c.caSecret.Namespace)
+		return nil
 	}
 
-	return nil
+	if !c.auto {
+		logger.V(1).Info("certificate authorities changed, but auto-generate is disabled, the secret is not overwritten",
+			"name", c.caSecret.Name, "namespace", c.caSecret.Namespace)
+		return nil
+	}
+
+	if c.caSecret.ResourceVersion == "" {
+		c.caSecret.Data = data
+		return c.secretCreateIfDoesNotExist(ctx)
+	}
+
+	return c.updateSecret(ctx, data)
+}
+
+// storePrivateKey returns the private key of the certificate authority as it is stored in the CA secret.
+// A key loaded from the secret is stored unchanged, other keys are sealed when key encryption is configured.
+func (c *certificateManager) storePrivateKey(ctx context.Context, ca *CertificateAuthority) ([]byte, error) {
+	if ca.storedPrivateKey != nil {
+		return ca.storedPrivateKey, nil
+	}
+	if c.keyEncryption == nil {
+		return ca.privateKeyPEM(), nil
+	}
+	sealed, err := kms.Seal(ctx, c.keyEncryption, ca.privateKeyPEM())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt private key of certificate authority %s: %w", ca.SerialNumber(), err)
+	}
+	return sealed, nil
+}
+
+// loadPrivateKey returns the PEM private key of a key pair, a sealed key is decrypted in memory.
+// The stored key is returned when it can be saved again as is, it is nil when the key must be sealed,
+// because it is plain or was sealed with another key encryption key than the current one.
+func (c *certificateManager) loadPrivateKey(ctx context.Context, keyPEM []byte, keyID string) ([]byte, []byte, error) {
+	if !kms.IsSealed(keyPEM) {
+		if c.keyEncryption != nil {
+			return keyPEM, nil, nil
+		}
+		return keyPEM, keyPEM, nil
+	}
+	if c.keyEncryption == nil {
+		return nil, nil, fmt.Errorf("private keys in secret %s/%s are encrypted, but key encryption is not configured",
+			c.caSecret.Namespace, c.caSecret.Name)
+	}
+
+	plaintext, sealedKeyID, err := kms.Open(ctx, c.keyEncryption, keyPEM)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decrypt private key in secret %s/%s: %w", c.caSecret.Namespace, c.caSecret.Name, err)
+	}
+	if sealedKeyID != keyID {
+		logger.V(1).Info("private key is encrypted with a previous key encryption key, encrypt it again",
+			"name", c.caSecret.Name, "namespace", c.caSecret.Namespace, "keyID", sealedKeyID, "currentKeyID", keyID)
+		return plaintext, nil, nil
+	}
+	return plaintext, keyPEM, nil
 }
 
 // Get certificate authorities from a secret, if the secret does not exist,
@@ -179,14 +284,25 @@ func (c *certificateManager) updateCertificateAuthoritiesToSecret(ctx context.Co
 // When checking the certificate, if an existing certificate is found and the certificate is about to expire,
 // in the case of auto being false, it will be prompted in the form of a log and will not affect
 // the issuance of service certificates.
-func (c *certificateManager) getCertificateAuthorities(pemKeyPairs []PEMkeyPair) ([]*CertificateAuthority, error) {
+//
+// Sealed private keys are decrypted in memory, keyID is the id of the current key encryption key.
+func (c *certificateManager) getCertificateAuthorities(
+	ctx context.Context,
+	pemKeyPairs []PEMkeyPair,
+	keyID string,
+) ([]*CertificateAuthority, error) {
 	cas := make([]*CertificateAuthority, 0)
 
 	for _, keyPair := range pemKeyPairs {
-		ca, err := NewCertificateAuthorityFromData(keyPair.CertPEMBlock, keyPair.KeyPEMBlock, c.rsaKeyLength)
+		keyPEM, storedPrivateKey, err := c.loadPrivateKey(ctx, keyPair.KeyPEMBlock, keyID)
+		if err != nil {
+			return nil, err
+		}
+		ca, err := NewCertificateAuthorityFromData(keyPair.CertPEMBlock, keyPEM, c.rsaKeyLength)
 		if err != nil {
 			return nil, err
 		}
+		ca.storedPrivateKey = storedPrivateKey
 		if ca.Certificate.NotAfter.Before(time.Now()) {
 			logger.V(1).Info("certificate authority is expired, skip it.", "serialNumber", ca.SerialNumber(), "notAfter", ca.Certificate.NotAfter)
 			continue
@@ -291,37 +407,58 @@ func (c *certificateManager) rotateCertificateAuthority(cas []*CertificateAuthor
 	return cas, nil
 }
 
+// getAliveCertificateAuthority returns the oldest certificate authority valid after atAfter, so certificates
+// do not outlive it. If none is valid long enough, the newest certificate authority is returned.
 func (c *certificateManager) getAliveCertificateAuthority(atAfter time.Time, cas []*CertificateAuthority) *CertificateAuthority {
-	cas = slices.DeleteFunc(cas, func(ca *CertificateAuthority) bool {
+	compareNotAfter := func(a, b *CertificateAuthority) int {
+		return a.Certificate.NotAfter.Compare(b.Certificate.NotAfter)
+	}
+
+	alive := slices.DeleteFunc(slices.Clone(cas), func(ca *CertificateAuthority) bool {
 		return ca.Certificate.NotAfter.Before(atAfter)
 	})
+	if len(alive) == 0 {
+		newestCA := slices.MaxFunc(cas, compareNotAfter)
+		logger.Info("no certificate authority is valid for the max certificate lifetime, use the newest one",
+			"serialNumber", newestCA.SerialNumber(), "notAfter", newestCA.Certificate.NotAfter, "atAfter", atAfter)
+		return newestCA
+	}
 
-	oldestCA := slices.MinFunc(cas, func(a, b *CertificateAuthority) int {
-		if a.Certificate.NotAfter.Before(b.Certificate.NotAfter) {
-			return -1
-		}
-		if a.Certificate.NotAfter.After(b.Certificate.NotAfter) {
-			return 1
-		}
-		return 0
-	})
+	oldestCA := slices.MinFunc(alive, compareNotAfter)
 	logger.V(1).Info("got alive certificate authority", "serialNumber", oldestCA.SerialNumber(), "notAfter", oldestCA.Certificate.NotAfter)
 
 	return oldestCA
 }
 
-// Get the certificate authority before the expiration time, the expiration time from the secret class
-func (c *certificateManager) getCertificateAuthority(ctx context.Context) (*CertificateAuthority, error) {
-	atAfter := time.Now().Add(c.maxCertificateLifeTime) // server cert lifetime in secret class configed
-	// retry to get certificate authority
+// loadCertificateAuthorities gets the certificate authorities from the CA secret, rotates them if needed
+// and saves them when they changed.
+func (c *certificateManager) loadCertificateAuthorities(ctx context.Context, caSecretSpec *secretsv1alpha1.SecretSpec) error {
+	lock, _ := caSecretLocks.LoadOrStore(caSecretSpec.Namespace+"/"+caSecretSpec.Name, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	var keyID string
+	if c.keyEncryption != nil {
+		var err error
+		if keyID, err = c.keyEncryption.KeyID(ctx); err != nil {
+			return err
+		}
+	}
+	c.keyID = keyID
+
 	// if the secret is modified by other clients, it will raise a conflict error
 	// we should get the latest object and retry from the beginning.
-	caMutex.Lock()
-	defer caMutex.Unlock()
-	if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		caSecret, err := getSecret(ctx, c.client, caSecretSpec.Name, caSecretSpec.Namespace)
+		if err != nil {
+			return err
+		}
+		c.caSecret = caSecret
+		c.observedResourceVersion = caSecret.ResourceVersion
+
 		pemKeyPairs := c.getPEMKeyPairsFromSecret()
 
-		cas, err := c.getCertificateAuthorities(pemKeyPairs)
+		cas, err := c.getCertificateAuthorities(ctx, pemKeyPairs, keyID)
 		if err != nil {
 			return err
 		}
@@ -333,48 +470,56 @@ func (c *certificateManager) getCertificateAuthority(ctx context.Context) (*Cert
 		c.cas = cas
 
 		return c.updateCertificateAuthoritiesToSecret(ctx, c.cas)
-	}); err != nil {
-		return nil, err
+	})
+}
+
+// reloadDue returns whether the certificate authorities must be loaded again,
+// because one of them expired or the newest one must be rotated.
+func (c *certificateManager) reloadDue(now time.Time) bool {
+	for _, ca := range c.cas {
+		if ca.Certificate.NotAfter.Before(now) {
+			return true
+		}
 	}
-	ca := c.getAliveCertificateAuthority(atAfter, c.cas)
-	return ca, nil
+	return c.auto && now.Add(c.caCertificateLifetime/2).After(c.NewestCertificateAuthorityNotAfter())
 }
 
 func (c *certificateManager) getAdditionalTrustRoots(ctx context.Context) ([]*Certificate, error) {
 	additionalTrustRoots := make([]*Certificate, 0, len(c.additionalTrustRoots))
 
 	for _, additionalTrustRoot := range c.additionalTrustRoots {
-		configMap, err := getConfigmap(ctx, c.client, additionalTrustRoot.ConfigMap.Name, additionalTrustRoot.ConfigMap.Namespace)
-		if err != nil {
-			return nil, err
-		}
-		if configMap == nil {
-			return nil, fmt.Errorf("could not find configmap: %s/%s", additionalTrustRoot.ConfigMap.Namespace, additionalTrustRoot.ConfigMap.Name)
-		}
-
-		certs, err := c.processConfigmapDataToCert(configMap.Data, configMap.BinaryData)
-		if err != nil {
-			return nil, err
-		}
-
-		additionalTrustRoots = append(additionalTrustRoots, certs...)
+		if additionalTrustRoot.ConfigMap != nil {
+			name, namespace := additionalTrustRoot.ConfigMap.Name, additionalTrustRoot.ConfigMap.Namespace
+			certs, err := c.getConfigMapTrustRoots(ctx, name, namespace)
+			if err != nil {
+				return nil, err
+			}
 
-		logger.V(1).Info("got additional trust roots from configmap", "name", configMap.Name, "namespace", configMap.Namespace, "len", len(certs))
+			additionalTrustRoots = append(additionalTrustRoots, certs...)
 
-		secret, err := getSecret(ctx, c.client, additionalTrustRoot.Secret.Name, additionalTrustRoot.Secret.Namespace)
-		if err != nil {
-			return nil, err
-		}
-		if secret == nil {
-			return nil, fmt.Errorf("could not find secret: %s/%s", additionalTrustRoot.Secret.Namespace, additionalTrustRoot.Secret.Name)
+			logger.V(1).Info("got additional trust roots from configmap", "name", name, "namespace", namespace, "len", len(certs))
 		}
 
-		certs, err = c.processSecretDataToCert(secret.Data)
-		if err != nil {
-			return nil, err
+		if additionalTrustRoot.Secret != nil {
+			secret, err := getSecret(ctx, c.client, additionalTrustRoot.Secret.Name, additionalTrustRoot.Secret.Namespace)
+			if err != nil {
+				return nil, err
+			}
+			// getSecret returns an empty secret when it does not exist
+			if secret.ResourceVersion == "" {
+				return nil, fmt.Errorf("could not find secret: %s/%s", additionalTrustRoot.Secret.Namespace, additionalTrustRoot.Secret.Name)
+			}
+
+			certs, err := c.parseTrustRootsCached("secret/"+secret.Namespace+"/"+secret.Name, secret.ResourceVersion,
+				func() ([]*Certificate, error) {
+					return c.processSecretDataToCert(secret.Data)
+				})
+			if err != nil {
+				return nil, err
+			}
+			additionalTrustRoots = append(additionalTrustRoots, certs...)
+			logger.V(1).Info("got additional trust roots from secret", "name", secret.Name, "namespace", secret.Namespace, "len", len(certs))
 		}
-		additionalTrustRoots = append(additionalTrustRoots, certs...)
-		logger.V(1).Info("got additional trust roots from secret", "name", secret.Name, "namespace", secret.Namespace, "len", len(certs))
 	}
 
 	logger.V(1).Info("got additional trust roots", "len", len(additionalTrustRoots))
@@ -382,70 +527,102 @@ func (c *certificateManager) getAdditionalTrustRoots(ctx context.Context) ([]*Ce
 	return additionalTrustRoots, nil
 }
 
-func (c *certificateManager) processConfigmapDataToCert(data map[string]string, binaryData map[string][]byte) ([]*Certificate, error) {
-	certs := make([]*Certificate, 0, len(data))
-	for key, certPEM := range data {
-		cert, err := c.convertDataToCert(key, []byte(certPEM))
-		if err != nil {
-			return nil, err
+// getConfigMapTrustRoots returns the trust roots of the ConfigMap. With a ConfigMap metadata reader, the ConfigMap
+// is only read when its resource version changed since its trust roots were parsed.
+func (c *certificateManager) getConfigMapTrustRoots(ctx context.Context, name, namespace string) ([]*Certificate, error) {
+	source := "configmap/" + namespace + "/" + name
+
+	if configMapMetadataReader != nil {
+		metadata := &metav1.PartialObjectMetadata{}
+		metadata.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+		if err := configMapMetadataReader.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, metadata); err != nil {
+			// e.g. the cache is not started yet, the configmap is read instead
+			logger.V(1).Info("could not get configmap metadata", "name", name, "namespace", namespace, "error", err.Error())
+		} else if certs, ok := c.getParsedTrustRoots(source, metadata.ResourceVersion); ok {
+			return certs, nil
 		}
-		certs = append(certs, cert)
 	}
 
-	for key, certPEM := range binaryData {
-		cert, err := c.convertDataToCert(key, certPEM)
-		if err != nil {
-			return nil, err
-		}
-		certs = append(certs, cert)
+	configMap, err := getConfigmap(ctx, c.client, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if configMap == nil {
+		return nil, fmt.Errorf("could not find configmap: %s/%s", namespace, name)
 	}
 
-	return certs, nil
+	return c.parseTrustRootsCached(source, configMap.ResourceVersion, func() ([]*Certificate, error) {
+		return c.processConfigmapDataToCert(configMap.Data, configMap.BinaryData)
+	})
 }
 
-func (c *certificateManager) processSecretDataToCert(data map[string][]byte) ([]*Certificate, error) {
-	certs := make([]*Certificate, 0, len(data))
-	for key, certPEM := range data {
-		cert, err := c.convertDataToCert(key, certPEM)
-		if err != nil {
-			return nil, err
-		}
-		certs = append(certs, cert)
+// getParsedTrustRoots returns the trust roots of the source if they were parsed at the resource version.
+func (c *certificateManager) getParsedTrustRoots(source string, resourceVersion string) ([]*Certificate, bool) {
+	c.trustRootsMutex.Lock()
+	defer c.trustRootsMutex.Unlock()
+
+	parsed, ok := c.trustRoots[source]
+	if !ok || parsed.resourceVersion != resourceVersion {
+		return nil, false
+	}
+	return parsed.certs, true
+}
+
+// parseTrustRootsCached returns the trust roots of the source parsed at the resource version, or parses them.
+func (c *certificateManager) parseTrustRootsCached(
+	source string,
+	resourceVersion string,
+	parse func() ([]*Certificate, error),
+) ([]*Certificate, error) {
+	c.trustRootsMutex.Lock()
+	defer c.trustRootsMutex.Unlock()
+
+	if parsed, ok := c.trustRoots[source]; ok && parsed.resourceVersion == resourceVersion {
+		return parsed.certs, nil
+	}
+	certs, err := parse()
+	if err != nil {
+		return nil, err
 	}
+	c.trustRoots[source] = parsedTrustRoots{resourceVersion: resourceVersion, certs: certs}
 	return certs, nil
 }
 
-// convertDataToCert convert data to cert
-// if the key is suffix with .crt, it will parse the data with base64 encoded DER certificate
-// if the key is suffix with .der, it will parse the data with binary DER certificate
-func (c *certificateManager) convertDataToCert(key string, data []byte) (*Certificate, error) {
-	if strings.HasSuffix(key, ".crt") {
-		// Parse PEM format certificate
-		block, _ := pem.Decode(data)
-		if block == nil {
-			return nil, fmt.Errorf("failed to decode PEM data for key %s", key)
-		}
-		cert, err := x509.ParseCertificate(block.Bytes)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse certificate for key %s: %v", key, err)
+func (c *certificateManager) processConfigmapDataToCert(data map[string]string, binaryData map[string][]byte) ([]*Certificate, error) {
+	allData := make(map[string][]byte, len(data)+len(binaryData))
+	for key, value := range data {
+		allData[key] = []byte(value)
+	}
+	maps.Copy(allData, binaryData)
+	return c.processSecretDataToCert(allData)
+}
+
+// processSecretDataToCert parses the certificates of all keys, in the order of the keys.
+// Keys which are not in a certificate format are skipped, e.g. a README in the same secret.
+func (c *certificateManager) processSecretDataToCert(data map[string][]byte) ([]*Certificate, error) {
+	certs := make([]*Certificate, 0, len(data))
+	for _, key := range slices.Sorted(maps.Keys(data)) {
+		keyCerts, err := parseTrustRoots(key, data[key])
+		if errors.Is(err, errNotTrustRoot) {
+			logger.Info("skip additional trust root key, must end with .crt, .pem, .der, .p7b or .p7c", "key", key)
+			continue
 		}
-		return &Certificate{Certificate: cert}, nil
-	} else if strings.HasSuffix(key, ".der") {
-		// Parse DER format certificate
-		cert, err := x509.ParseCertificate(data)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse DER certificate for key %s: %v", key, err)
+			return nil, err
 		}
-		return &Certificate{Certificate: cert}, nil
+		certs = append(certs, keyCerts...)
 	}
-
-	return nil, fmt.Errorf("unsupported certificate format for key %s, must end with .crt or .der", key)
+	return certs, nil
 }
 
 // GetTrustAnchors returns the all ca certificates
 func (c *certificateManager) GetTrustAnchors(ctx context.Context) ([]*Certificate, error) {
 	trustAnchors := make([]*Certificate, 0, len(c.cas)+len(c.additionalTrustRoots))
+	now := time.Now()
 	for _, ca := range c.cas {
+		if ca.Certificate.NotAfter.Before(now) {
+			continue
+		}
 		// Do not publish the private key to the trust anchors
 		trustAnchors = append(trustAnchors, &Certificate{Certificate: ca.Certificate})
 	}
@@ -459,11 +636,27 @@ func (c *certificateManager) GetTrustAnchors(ctx context.Context) ([]*Certificat
 
 	trustAnchors = append(trustAnchors, additionalTrustRoots...)
 
-	return trustAnchors, nil
+	// the same certificate may be an additional trust root of several sources, or of the secret class itself
+	return dedupeCertificates(trustAnchors), nil
+}
+
+func (c *certificateManager) NewestCertificateAuthorityNotAfter() time.Time {
+	var notAfter time.Time
+	for _, ca := range c.cas {
+		if ca.Certificate.NotAfter.After(notAfter) {
+			notAfter = ca.Certificate.NotAfter
+		}
+	}
+	return notAfter
+}
+
+// selectedCA returns the certificate authority signing certificates now.
+func (c *certificateManager) selectedCA() *CertificateAuthority {
+	return c.getAliveCertificateAuthority(time.Now().Add(c.maxCertificateLifeTime), c.cas)
 }
 
 func (c *certificateManager) SignServerCertificate(addresses []pod_info.Address, notAfter time.Time) (*Certificate, error) {
-	cert, err := c.selectedCA.SignServerCertificate(addresses, notAfter)
+	cert, err := c.selectedCA().SignServerCertificate(addresses, notAfter)
 	if err != nil {
 		return nil, err
 	}
@@ -472,7 +665,7 @@ func (c *certificateManager) SignServerCertificate(addresses []pod_info.Address,
 }
 
 func (c *certificateManager) SignClientCertificate(addresses []pod_info.Address, notAfter time.Time) (*Certificate, error) {
-	cert, err := c.selectedCA.SignClientCertificate(addresses, notAfter)
+	cert, err := c.selectedCA().SignClientCertificate(addresses, notAfter)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,122 @@
+package ca
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1alpha1 "github.com/zncdatadev/secret-operator/api/v1alpha1"
+	"github.com/zncdatadev/secret-operator/internal/csi/backend/kms"
+)
+
+// certificateManagers are the certificate managers by SecretClass, shared by all volumes of the SecretClass.
+var certificateManagers = &certificateManagerCache{managers: make(map[string]*cachedCertificateManager)}
+
+// CertificateManagerConfig is the configuration of the certificate manager of a SecretClass.
+type CertificateManagerConfig struct {
+	MaxCertificateLifeTime time.Duration
+	CACertificateLifeTime  time.Duration
+	AutoGenerate           bool
+	CASecret               secretsv1alpha1.SecretSpec
+	AdditionalTrustRoots   []secretsv1alpha1.AdditionalTrustRootSpec
+	RSAKeyLength           int
+	KeyEncryption          *secretsv1alpha1.KeyEncryptionSpec
+}
+
+type cachedCertificateManager struct {
+	config  CertificateManagerConfig
+	manager *certificateManager
+}
+
+type certificateManagerCache struct {
+	mu       sync.Mutex
+	managers map[string]*cachedCertificateManager
+}
+
+// GetCertificateManager returns the certificate manager of the SecretClass.
+// The manager is cached until the configuration or the resource version of the CA secret changes,
+// or its certificate authorities must be rotated or an expired one removed. With a client reading from
+// the informer cache, a cached manager costs no API request, and the CA secret is only written on changes.
+// Trust root ConfigMaps are only read again when they change, see SetConfigMapMetadataReader.
+// A replaced key encryption key also creates a new manager, which encrypts the private keys again.
+func GetCertificateManager(ctx context.Context, c client.Client, class string, config *CertificateManagerConfig) (CertificateManager, error) {
+	caSecret, err := getSecret(ctx, c, config.CASecret.Name, config.CASecret.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	if manager := certificateManagers.get(class, config, caSecret.ResourceVersion); manager != nil {
+		changed, err := manager.keyEncryptionChanged(ctx, config.KeyEncryption)
+		if err != nil {
+			// the private keys are decrypted in memory, the manager is used until the key can be checked
+			logger.Error(err, "failed to check the key encryption key of the cached certificate manager", "class", class)
+			return manager, nil
+		}
+		if !changed {
+			return manager, nil
+		}
+		logger.V(1).Info("key encryption key changed, load the certificate authorities again", "class", class)
+	}
+
+	manager, err := newCertificateManager(ctx, c, config)
+	if err != nil {
+		return nil, err
+	}
+	certificateManagers.put(class, config, manager)
+	logger.V(1).Info("cached certificate manager", "class", class, "resourceVersion", manager.caSecret.ResourceVersion)
+	return manager, nil
+}
+
+// keyEncryptionChanged returns whether the key id of the key encryption key changed since the private keys were
+// sealed. The key id of a key encryption key secret is derived from the key, of a kms plugin read from its status.
+func (c *certificateManager) keyEncryptionChanged(ctx context.Context, spec *secretsv1alpha1.KeyEncryptionSpec) (bool, error) {
+	if c.keyEncryption == nil {
+		return false, nil
+	}
+
+	var keyID string
+	var err error
+	if spec.Secret != nil {
+		var secret *corev1.Secret
+		if secret, err = getSecret(ctx, c.client, spec.Secret.Name, spec.Secret.Namespace); err != nil {
+			return false, err
+		}
+		keyID, err = kms.LocalKeyID(secret.Data[kms.KEKSecretKey])
+	} else {
+		keyID, err = c.keyEncryption.KeyID(ctx)
+	}
+	if err != nil {
+		return false, err
+	}
+	return keyID != c.keyID, nil
+}
+
+// get returns the cached manager of the class if it is still valid for the config and the resource version.
+func (m *certificateManagerCache) get(class string, config *CertificateManagerConfig, resourceVersion string) *certificateManager {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cached, ok := m.managers[class]
+	if !ok || !reflect.DeepEqual(&cached.config, config) {
+		return nil
+	}
+	manager := cached.manager
+	// the informer may not have seen the update of the manager yet
+	if resourceVersion != manager.caSecret.ResourceVersion && resourceVersion != manager.observedResourceVersion {
+		return nil
+	}
+	if manager.reloadDue(time.Now()) {
+		return nil
+	}
+	return manager
+}
+
+func (m *certificateManagerCache) put(class string, config *CertificateManagerConfig, manager *certificateManager) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.managers[class] = &cachedCertificateManager{config: *config, manager: manager}
+}
@@ -0,0 +1,349 @@
+package ca
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	secretsv1alpha1 "github.com/zncdatadev/secret-operator/api/v1alpha1"
+	"github.com/zncdatadev/secret-operator/internal/csi/backend/kms"
+)
+
+func newTestCertificateManagerConfig() *CertificateManagerConfig {
+	return &CertificateManagerConfig{
+		MaxCertificateLifeTime: 24 * time.Hour,
+		CACertificateLifeTime:  365 * 24 * time.Hour,
+		AutoGenerate:           true,
+		CASecret:               secretsv1alpha1.SecretSpec{Name: "tls-ca", Namespace: "default"},
+		RSAKeyLength:           2048,
+	}
+}
+
+func TestGetCertificateManager(t *testing.T) {
+	ctx := context.Background()
+	var writes atomic.Int32
+	c := fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			writes.Add(1)
+			return c.Create(ctx, obj, opts...)
+		},
+		Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+			writes.Add(1)
+			return c.Update(ctx, obj, opts...)
+		},
+	}).Build()
+	config := newTestCertificateManagerConfig()
+
+	first, err := GetCertificateManager(ctx, c, "test-cache", config)
+	if err != nil {
+		t.Fatalf("GetCertificateManager() error = %v", err)
+	}
+	if writes.Load() != 1 {
+		t.Fatalf("GetCertificateManager() writes = %d, want 1 creating the ca secret", writes.Load())
+	}
+
+	second, err := GetCertificateManager(ctx, c, "test-cache", config)
+	if err != nil {
+		t.Fatalf("GetCertificateManager() error = %v", err)
+	}
+	if first != second {
+		t.Errorf("GetCertificateManager() did not return the cached manager")
+	}
+
+	// a changed ca secret loads the certificate authorities again, without writing unchanged ones
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{Name: "tls-ca", Namespace: "default"}, secret); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	secret.Labels = map[string]string{"changed": "true"}
+	if err := c.Update(ctx, secret); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	writes.Store(0)
+
+	third, err := GetCertificateManager(ctx, c, "test-cache", config)
+	if err != nil {
+		t.Fatalf("GetCertificateManager() error = %v", err)
+	}
+	if third == first {
+		t.Errorf("GetCertificateManager() returned the manager of an outdated ca secret")
+	}
+	if writes.Load() != 0 {
+		t.Errorf("GetCertificateManager() writes = %d, want 0 for unchanged certificate authorities", writes.Load())
+	}
+
+	// a changed configuration creates a new manager
+	config.MaxCertificateLifeTime = 48 * time.Hour
+	fourth, err := GetCertificateManager(ctx, c, "test-cache", config)
+	if err != nil {
+		t.Fatalf("GetCertificateManager() error = %v", err)
+	}
+	if fourth == third {
+		t.Errorf("GetCertificateManager() returned the manager of an outdated configuration")
+	}
+}
+
+func TestReloadDue(t *testing.T) {
+	now := time.Now()
+	authority, err := NewSelfSignedCertificateAuthority(now.Add(100*time.Hour), nil, nil, 2048)
+	if err != nil {
+		t.Fatalf("NewSelfSignedCertificateAuthority() error = %v", err)
+	}
+	c := &certificateManager{auto: true, caCertificateLifetime: 100 * time.Hour, cas: []*CertificateAuthority{authority}}
+
+	if c.reloadDue(now) {
+		t.Errorf("reloadDue() = true for a new certificate authority")
+	}
+	if !c.reloadDue(now.Add(51 * time.Hour)) {
+		t.Errorf("reloadDue() = false after half of the certificate authority lifetime")
+	}
+	c.auto = false
+	if c.reloadDue(now.Add(51 * time.Hour)) {
+		t.Errorf("reloadDue() = true for a rotation without auto-generate")
+	}
+	if !c.reloadDue(now.Add(101 * time.Hour)) {
+		t.Errorf("reloadDue() = false for an expired certificate authority")
+	}
+}
+
+func TestGetAliveCertificateAuthority(t *testing.T) {
+	now := time.Now()
+	var cas []*CertificateAuthority
+	for _, lifetime := range []time.Duration{30 * time.Hour, 10 * time.Hour, 20 * time.Hour} {
+		authority, err := NewSelfSignedCertificateAuthority(now.Add(lifetime), nil, nil, 2048)
+		if err != nil {
+			t.Fatalf("NewSelfSignedCertificateAuthority() error = %v", err)
+		}
+		cas = append(cas, authority)
+	}
+	c := &certificateManager{}
+
+	if got := c.getAliveCertificateAuthority(now.Add(15*time.Hour), cas); got != cas[2] {
+		t.Errorf("getAliveCertificateAuthority() = %v, want the oldest alive certificate authority", got.Certificate.NotAfter)
+	}
+	if len(cas) != 3 || cas[1] == nil {
+		t.Errorf("getAliveCertificateAuthority() modified the certificate authorities")
+	}
+	if got := c.getAliveCertificateAuthority(now.Add(40*time.Hour), cas); got != cas[0] {
+		t.Errorf("getAliveCertificateAuthority() = %v, want the newest certificate authority", got.Certificate.NotAfter)
+	}
+}
+
+func TestCertificateManagerKeyEncryption(t *testing.T) {
+	ctx := context.Background()
+	var writes atomic.Int32
+	c := fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+		Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+			writes.Add(1)
+			return c.Update(ctx, obj, opts...)
+		},
+	}).Build()
+	config := newTestCertificateManagerConfig()
+
+	plain, err := newCertificateManager(ctx, c, config)
+	if err != nil {
+		t.Fatalf("newCertificateManager() error = %v", err)
+	}
+
+	// plain private keys are encrypted on the next write
+	config.KeyEncryption = &secretsv1alpha1.KeyEncryptionSpec{
+		Secret: &secretsv1alpha1.SecretSpec{Name: "tls-ca-kek", Namespace: "platform"},
+	}
+	encrypted, err := newCertificateManager(ctx, c, config)
+	if err != nil {
+		t.Fatalf("newCertificateManager() error = %v", err)
+	}
+	if writes.Load() != 1 {
+		t.Errorf("newCertificateManager() writes = %d, want 1 encrypting the private keys", writes.Load())
+	}
+	if !encrypted.cas[0].Certificate.Equal(plain.cas[0].Certificate) {
+		t.Errorf("newCertificateManager() changed the certificate authority")
+	}
+
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{Name: "tls-ca", Namespace: "default"}, secret); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !kms.IsSealed(secret.Data["0.ca.key"]) || strings.Contains(string(secret.Data["0.ca.key"]), "RSA PRIVATE KEY") {
+		t.Errorf("private key in secret = %s, want sealed", secret.Data["0.ca.key"])
+	}
+
+	// sealed private keys are decrypted in memory, without writing them again
+	writes.Store(0)
+	reloaded, err := newCertificateManager(ctx, c, config)
+	if err != nil {
+		t.Fatalf("newCertificateManager() error = %v", err)
+	}
+	if writes.Load() != 0 {
+		t.Errorf("newCertificateManager() writes = %d, want 0 for unchanged sealed private keys", writes.Load())
+	}
+	if _, err := reloaded.SignServerCertificate(nil, time.Now().Add(time.Hour)); err != nil {
+		t.Errorf("SignServerCertificate() error = %v", err)
+	}
+
+	// private keys sealed with a previous key encryption key are sealed again with the new key
+	kek := &corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{Name: "tls-ca-kek", Namespace: "platform"}, kek); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	kek.Data[kms.PreviousKEKSecretKeyPrefix+"1"] = kek.Data[kms.KEKSecretKey]
+	kek.Data[kms.KEKSecretKey] = []byte(strings.Repeat("k", 32))
+	if err := c.Update(ctx, kek); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	writes.Store(0)
+	if _, err := newCertificateManager(ctx, c, config); err != nil {
+		t.Fatalf("newCertificateManager() with a replaced key encryption key error = %v", err)
+	}
+	if writes.Load() != 1 {
+		t.Errorf("newCertificateManager() writes = %d, want 1 encrypting the private keys again", writes.Load())
+	}
+	current, err := kms.NewLocalService([]byte(strings.Repeat("k", 32)))
+	if err != nil {
+		t.Fatalf("NewLocalService() error = %v", err)
+	}
+	if err := c.Get(ctx, client.ObjectKey{Name: "tls-ca", Namespace: "default"}, secret); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if _, _, err := kms.Open(ctx, current, secret.Data["0.ca.key"]); err != nil {
+		t.Errorf("private key in secret is not sealed with the new key encryption key: %v", err)
+	}
+
+	config.KeyEncryption = nil
+	if _, err := newCertificateManager(ctx, c, config); err == nil {
+		t.Errorf("newCertificateManager() without key encryption expected error for sealed private keys")
+	}
+}
+
+func TestGetCertificateManagerKeyEncryptionKeyReplaced(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewClientBuilder().Build()
+	config := newTestCertificateManagerConfig()
+	config.KeyEncryption = &secretsv1alpha1.KeyEncryptionSpec{
+		Secret: &secretsv1alpha1.SecretSpec{Name: "tls-ca-kek", Namespace: "platform"},
+	}
+
+	first, err := GetCertificateManager(ctx, c, "test-kek", config)
+	if err != nil {
+		t.Fatalf("GetCertificateManager() error = %v", err)
+	}
+	second, err := GetCertificateManager(ctx, c, "test-kek", config)
+	if err != nil {
+		t.Fatalf("GetCertificateManager() error = %v", err)
+	}
+	if first != second {
+		t.Errorf("GetCertificateManager() did not return the cached manager")
+	}
+
+	// a replaced key encryption key is picked up by the cached manager, which seals the private keys again
+	kek := &corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{Name: "tls-ca-kek", Namespace: "platform"}, kek); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	kek.Data[kms.PreviousKEKSecretKeyPrefix+"1"] = kek.Data[kms.KEKSecretKey]
+	kek.Data[kms.KEKSecretKey] = []byte(strings.Repeat("k", 32))
+	if err := c.Update(ctx, kek); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	third, err := GetCertificateManager(ctx, c, "test-kek", config)
+	if err != nil {
+		t.Fatalf("GetCertificateManager() error = %v", err)
+	}
+	if third == first {
+		t.Errorf("GetCertificateManager() returned the manager of a replaced key encryption key")
+	}
+
+	current, err := kms.NewLocalService([]byte(strings.Repeat("k", 32)))
+	if err != nil {
+		t.Fatalf("NewLocalService() error = %v", err)
+	}
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{Name: "tls-ca", Namespace: "default"}, secret); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if _, _, err := kms.Open(ctx, current, secret.Data["0.ca.key"]); err != nil {
+		t.Errorf("private key in secret is not sealed with the new key encryption key: %v", err)
+	}
+}
+
+func TestCertificateManagerKeyEncryptionNamespace(t *testing.T) {
+	config := newTestCertificateManagerConfig()
+	config.KeyEncryption = &secretsv1alpha1.KeyEncryptionSpec{
+		Secret: &secretsv1alpha1.SecretSpec{Name: "tls-ca-kek", Namespace: config.CASecret.Namespace},
+	}
+	if _, err := newCertificateManager(context.Background(), fake.NewClientBuilder().Build(), config); err == nil {
+		t.Errorf("newCertificateManager() expected error for a key encryption key next to the CA secret")
+	}
+}
+
+func TestGetTrustAnchorsConfigMapMetadata(t *testing.T) {
+	ctx := context.Background()
+	trustRoot, err := NewSelfSignedCertificateAuthority(time.Now().Add(time.Hour), nil, nil, 2048)
+	if err != nil {
+		t.Fatalf("NewSelfSignedCertificateAuthority() error = %v", err)
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "trust-roots", Namespace: "default"},
+		Data:       map[string]string{"ca.crt": string(trustRoot.CertificatePEM())},
+	}
+	metadataReader := fake.NewClientBuilder().WithObjects(configMap).Build()
+	SetConfigMapMetadataReader(metadataReader)
+	t.Cleanup(func() { SetConfigMapMetadataReader(nil) })
+
+	var configMapGets atomic.Int32
+	c := interceptor.NewClient(metadataReader, interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			if _, ok := obj.(*corev1.ConfigMap); ok {
+				configMapGets.Add(1)
+			}
+			return c.Get(ctx, key, obj, opts...)
+		},
+	})
+	config := newTestCertificateManagerConfig()
+	config.AdditionalTrustRoots = []secretsv1alpha1.AdditionalTrustRootSpec{
+		{ConfigMap: &secretsv1alpha1.ConfigMapSpec{Name: "trust-roots", Namespace: "default"}},
+	}
+	manager, err := newCertificateManager(ctx, c, config)
+	if err != nil {
+		t.Fatalf("newCertificateManager() error = %v", err)
+	}
+
+	for range 2 {
+		anchors, err := manager.GetTrustAnchors(ctx)
+		if err != nil {
+			t.Fatalf("GetTrustAnchors() error = %v", err)
+		}
+		if len(anchors) != 2 {
+			t.Fatalf("GetTrustAnchors() = %d anchors, want the ca and the trust root", len(anchors))
+		}
+	}
+	if configMapGets.Load() != 1 {
+		t.Errorf("GetTrustAnchors() configmap gets = %d, want 1 while the resource version is unchanged", configMapGets.Load())
+	}
+
+	// a changed trust root configmap is read again
+	otherRoot, err := NewSelfSignedCertificateAuthority(time.Now().Add(time.Hour), nil, nil, 2048)
+	if err != nil {
+		t.Fatalf("NewSelfSignedCertificateAuthority() error = %v", err)
+	}
+	configMap.Data["other.crt"] = string(otherRoot.CertificatePEM())
+	if err := metadataReader.Update(ctx, configMap); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	anchors, err := manager.GetTrustAnchors(ctx)
+	if err != nil {
+		t.Fatalf("GetTrustAnchors() error = %v", err)
+	}
+	if len(anchors) != 3 || configMapGets.Load() != 2 {
+		t.Errorf("GetTrustAnchors() = %d anchors with %d configmap gets, want 3 anchors reading the changed configmap",
+			len(anchors), configMapGets.Load())
+	}
+}
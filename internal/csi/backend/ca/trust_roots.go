@@ -0,0 +1,136 @@
+package ca
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	oidPKCS7SignedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+
+	// errNotTrustRoot is returned for keys whose suffix is not a certificate format, they are skipped.
+	errNotTrustRoot = errors.New("not a trust root")
+)
+
+// parseTrustRoots parses the certificates of a key of an additional trust root, by the suffix of the key:
+//   - `.crt` and `.pem` are PEM bundles of any number of certificates, PKCS#7 blocks are also accepted
+//   - `.der` is one or more concatenated DER certificates
+//   - `.p7b` and `.p7c` are PKCS#7 certificate bundles, PEM or DER encoded
+//
+// Other keys return errNotTrustRoot.
+func parseTrustRoots(key string, data []byte) ([]*Certificate, error) {
+	var certs []*x509.Certificate
+	var err error
+	switch {
+	case strings.HasSuffix(key, ".crt"), strings.HasSuffix(key, ".pem"):
+		certs, err = parsePEMCertificates(data)
+	case strings.HasSuffix(key, ".der"):
+		certs, err = x509.ParseCertificates(data)
+	case strings.HasSuffix(key, ".p7b"), strings.HasSuffix(key, ".p7c"):
+		if bytes.HasPrefix(bytes.TrimSpace(data), []byte("-----BEGIN")) {
+			certs, err = parsePEMCertificates(data)
+		} else {
+			certs, err = parsePKCS7Certificates(data)
+		}
+	default:
+		return nil, errNotTrustRoot
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificates for key %s: %w", key, err)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificates found for key %s", key)
+	}
+
+	trustRoots := make([]*Certificate, 0, len(certs))
+	for _, cert := range certs {
+		trustRoots = append(trustRoots, &Certificate{Certificate: cert})
+	}
+	return trustRoots, nil
+}
+
+// parsePEMCertificates returns the certificates of all `CERTIFICATE` and `PKCS7` blocks, other blocks are skipped.
+func parsePEMCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return certs, nil
+		}
+
+		switch block.Type {
+		case "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			certs = append(certs, cert)
+		case "PKCS7":
+			pkcs7Certs, err := parsePKCS7Certificates(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			certs = append(certs, pkcs7Certs...)
+		default:
+			logger.V(1).Info("skip pem block, it is not a certificate", "type", block.Type)
+		}
+	}
+}
+
+// parsePKCS7Certificates returns the certificates of a DER encoded PKCS#7 SignedData, as in `.p7b` bundles.
+// The signatures are not verified, certificate bundles are usually not signed.
+func parsePKCS7Certificates(data []byte) ([]*x509.Certificate, error) {
+	var contentInfo struct {
+		ContentType asn1.ObjectIdentifier
+		// [0] EXPLICIT
+		Content asn1.RawValue
+	}
+	if _, err := asn1.Unmarshal(data, &contentInfo); err != nil {
+		return nil, fmt.Errorf("invalid pkcs7: %w", err)
+	}
+	if !contentInfo.ContentType.Equal(oidPKCS7SignedData) {
+		return nil, fmt.Errorf("unsupported pkcs7 content type %s, only signed data is supported", contentInfo.ContentType)
+	}
+
+	// SignedData is a sequence of version, digestAlgorithms, contentInfo, certificates [0] IMPLICIT OPTIONAL,
+	// crls [1] IMPLICIT OPTIONAL and signerInfos, only the certificates are used
+	var signedData asn1.RawValue
+	if _, err := asn1.Unmarshal(contentInfo.Content.Bytes, &signedData); err != nil {
+		return nil, fmt.Errorf("invalid pkcs7 signed data: %w", err)
+	}
+	elements := signedData.Bytes
+	for len(elements) > 0 {
+		var element asn1.RawValue
+		var err error
+		if elements, err = asn1.Unmarshal(elements, &element); err != nil {
+			return nil, fmt.Errorf("invalid pkcs7 signed data: %w", err)
+		}
+		if element.Class == asn1.ClassContextSpecific && element.Tag == 0 {
+			return x509.ParseCertificates(element.Bytes)
+		}
+	}
+	return nil, nil
+}
+
+// dedupeCertificates removes certificates with the same SHA-256 fingerprint, keeping the first one.
+func dedupeCertificates(certs []*Certificate) []*Certificate {
+	seen := make(map[[sha256.Size]byte]bool, len(certs))
+	deduped := make([]*Certificate, 0, len(certs))
+	for _, cert := range certs {
+		fingerprint := sha256.Sum256(cert.Certificate.Raw)
+		if seen[fingerprint] {
+			logger.V(1).Info("skip duplicate trust anchor", "subject", cert.Certificate.Subject, "serialNumber", cert.SerialNumber())
+			continue
+		}
+		seen[fingerprint] = true
+		deduped = append(deduped, cert)
+	}
+	return deduped
+}
@@ -0,0 +1,120 @@
+package ca
+
+import (
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestCertificates(t *testing.T, n int) []*Certificate {
+	t.Helper()
+	certs := make([]*Certificate, 0, n)
+	for range n {
+		authority, err := NewSelfSignedCertificateAuthority(time.Now().Add(time.Hour), nil, nil, 2048)
+		if err != nil {
+			t.Fatalf("NewSelfSignedCertificateAuthority() error = %v", err)
+		}
+		certs = append(certs, authority.PublicCertificate())
+	}
+	return certs
+}
+
+// marshalPKCS7 returns a degenerate PKCS#7 SignedData with the certificates, as exported by `openssl crl2pkcs7`.
+func marshalPKCS7(t *testing.T, certs []*Certificate) []byte {
+	t.Helper()
+	var raw []byte
+	for _, cert := range certs {
+		raw = append(raw, cert.Certificate.Raw...)
+	}
+	signedData, err := asn1.Marshal(struct {
+		Version          int
+		DigestAlgorithms asn1.RawValue
+		ContentInfo      struct{ ContentType asn1.ObjectIdentifier }
+		Certificates     asn1.RawValue
+		SignerInfos      asn1.RawValue
+	}{
+		Version:          1,
+		DigestAlgorithms: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true},
+		ContentInfo:      struct{ ContentType asn1.ObjectIdentifier }{asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: raw},
+		SignerInfos:      asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true},
+	})
+	if err != nil {
+		t.Fatalf("asn1.Marshal() error = %v", err)
+	}
+	data, err := asn1.Marshal(struct {
+		ContentType asn1.ObjectIdentifier
+		Content     asn1.RawValue
+	}{oidPKCS7SignedData, asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: signedData}})
+	if err != nil {
+		t.Fatalf("asn1.Marshal() error = %v", err)
+	}
+	return data
+}
+
+func TestParseTrustRoots(t *testing.T) {
+	certs := newTestCertificates(t, 3)
+
+	var bundle []byte
+	for _, cert := range certs {
+		bundle = append(bundle, cert.CertificatePEM()...)
+	}
+	// a private key in the bundle is skipped
+	bundleWithKey := append(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("key")}), bundle...)
+	pkcs7 := marshalPKCS7(t, certs)
+
+	tests := []struct {
+		name    string
+		key     string
+		data    []byte
+		want    int
+		wantErr bool
+	}{
+		{name: "pem bundle", key: "ca-bundle.crt", data: bundleWithKey, want: 3},
+		{name: "pem suffix", key: "ca.pem", data: certs[0].CertificatePEM(), want: 1},
+		{name: "der", key: "ca.der", data: certs[1].Certificate.Raw, want: 1},
+		{name: "pkcs7 der", key: "ca.p7b", data: pkcs7, want: 3},
+		{name: "pkcs7 pem", key: "ca.p7c", data: pem.EncodeToMemory(&pem.Block{Type: "PKCS7", Bytes: pkcs7}), want: 3},
+		{name: "no certificates", key: "ca.crt", data: []byte("not a certificate"), wantErr: true},
+		{name: "invalid der", key: "ca.der", data: []byte("not a certificate"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTrustRoots(tt.key, tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTrustRoots() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != tt.want {
+				t.Errorf("parseTrustRoots() certificates = %d, want %d", len(got), tt.want)
+			}
+		})
+	}
+
+	if _, err := parseTrustRoots("README.md", []byte("trust roots")); !errors.Is(err, errNotTrustRoot) {
+		t.Errorf("parseTrustRoots() of README.md error = %v, want errNotTrustRoot", err)
+	}
+}
+
+func TestProcessSecretDataToCert(t *testing.T) {
+	certs := newTestCertificates(t, 2)
+	c := &certificateManager{}
+
+	got, err := c.processSecretDataToCert(map[string][]byte{
+		"a.crt":     append(certs[0].CertificatePEM(), certs[1].CertificatePEM()...),
+		"b.der":     certs[1].Certificate.Raw,
+		"README.md": []byte("corporate trust roots"),
+	})
+	if err != nil {
+		t.Fatalf("processSecretDataToCert() error = %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("processSecretDataToCert() certificates = %d, want 3", len(got))
+	}
+
+	deduped := dedupeCertificates(got)
+	if len(deduped) != 2 || !deduped[0].Certificate.Equal(certs[0].Certificate) || !deduped[1].Certificate.Equal(certs[1].Certificate) {
+		t.Errorf("dedupeCertificates() = %d certificates, want the 2 distinct ones in order", len(deduped))
+	}
+}
@@ -0,0 +1,190 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1alpha1 "github.com/zncdatadev/secret-operator/api/v1alpha1"
+	"github.com/zncdatadev/secret-operator/pkg/util"
+	"github.com/zncdatadev/secret-operator/pkg/volume"
+)
+
+var _ IBackend = &CompositeBackend{}
+
+// compositeClassesKey is the context key of the secret classes of nested composite backends, to detect cycles.
+type compositeClassesKey struct{}
+
+type CompositeBackend struct {
+	components []*compositeComponent
+}
+
+// compositeComponent is the backend of a secret class of the composite, with where its files are written.
+type compositeComponent struct {
+	class     string
+	directory string
+	prefix    string
+	backend   *Backend
+}
+
+func NewCompositeBackend(config *BackendConfig) (IBackend, error) {
+	spec := config.SecretClass.Spec.Backend.Composite
+	if len(spec.SecretClasses) == 0 {
+		return nil, errors.New("secretClasses is empty in composite backend")
+	}
+
+	classes, _ := config.ctx.Value(compositeClassesKey{}).([]string)
+	classes = append(slices.Clone(classes), config.SecretClass.Name)
+	ctx := context.WithValue(config.ctx, compositeClassesKey{}, classes)
+
+	components := make([]*compositeComponent, 0, len(spec.SecretClasses))
+	for _, componentSpec := range spec.SecretClasses {
+		if slices.Contains(classes, componentSpec.Name) {
+			return nil, fmt.Errorf("composite secret class %s references itself through %s",
+				config.SecretClass.Name, strings.Join(append(classes, componentSpec.Name), " -> "))
+		}
+		if componentSpec.Directory != "" && !filepath.IsLocal(componentSpec.Directory) {
+			return nil, fmt.Errorf("directory %q of secret class %s must be a relative path within the volume",
+				componentSpec.Directory, componentSpec.Name)
+		}
+		if strings.ContainsAny(componentSpec.Prefix, `/\`) {
+			return nil, fmt.Errorf("prefix %q of secret class %s must not contain a path separator, use directory",
+				componentSpec.Prefix, componentSpec.Name)
+		}
+
+		component, err := newCompositeComponent(ctx, config, &componentSpec)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, component)
+	}
+
+	return &CompositeBackend{components: components}, nil
+}
+
+// newCompositeComponent creates the backend of a secret class with the volume context of the composite,
+// after checking the secret class against the cross-namespace policy of the volume.
+func newCompositeComponent(
+	ctx context.Context,
+	config *BackendConfig,
+	spec *secretsv1alpha1.CompositeSecretClassSpec,
+) (*compositeComponent, error) {
+	secretClass := &secretsv1alpha1.SecretClass{}
+	if err := config.Client.Get(ctx, client.ObjectKey{Name: spec.Name}, secretClass); err != nil {
+		return nil, fmt.Errorf("failed to get secret class %s of composite: %w", spec.Name, err)
+	}
+
+	volumeCtx := *config.VolumeContext
+	volumeCtx.Class = spec.Name
+	if spec.Format != "" {
+		volumeCtx.Format = volume.SecretFormat(spec.Format)
+	}
+
+	if err := ValidateCrossNamespaceReferences(secretClass, &volumeCtx); err != nil {
+		return nil, err
+	}
+
+	backend, err := newBackendFromSecretClass(ctx, config.Client, config.PodInfo, &volumeCtx, secretClass)
+	if err != nil {
+		return nil, fmt.Errorf("secret class %s of composite: %w", spec.Name, err)
+	}
+
+	return &compositeComponent{class: spec.Name, directory: spec.Directory, prefix: spec.Prefix, backend: backend}, nil
+}
+
+// fileName returns the path of a file of the component in the volume.
+func (c *compositeComponent) fileName(name string) string {
+	return path.Join(filepath.ToSlash(c.directory), c.prefix+name)
+}
+
+// GetQualifiedNodeNames implements Backend.
+// It returns the nodes qualified for all secret classes, or nil when no secret class restricts the nodes.
+func (c *CompositeBackend) GetQualifiedNodeNames(ctx context.Context) ([]string, error) {
+	var nodeNames []string
+	restricted := false
+	for _, component := range c.components {
+		componentNodeNames, err := component.backend.GetQualifiedNodeNames(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(componentNodeNames) == 0 {
+			continue
+		}
+
+		if !restricted {
+			nodeNames = slices.Clone(componentNodeNames)
+			restricted = true
+			continue
+		}
+		nodeNames = slices.DeleteFunc(nodeNames, func(nodeName string) bool {
+			return !slices.Contains(componentNodeNames, nodeName)
+		})
+	}
+
+	if restricted && len(nodeNames) == 0 {
+		return nil, errors.New("no node is qualified for all secret classes of the composite")
+	}
+	return nodeNames, nil
+}
+
+// GetSecretData implements Backend.
+// It merges the files of all secret classes, and expires with the earliest expiring secret class.
+func (c *CompositeBackend) GetSecretData(ctx context.Context) (*util.SecretContent, error) {
+	contents := make([]*util.SecretContent, 0, len(c.components))
+	for _, component := range c.components {
+		content, err := component.backend.GetSecretData(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get secret data of secret class %s: %w", component.class, err)
+		}
+		contents = append(contents, content)
+	}
+	return mergeSecretContents(c.components, contents)
+}
+
+// mergeSecretContents merges the contents of the components, a file written by two components is a conflict,
+// as is a file whose path is a directory of another file, e.g. a file `tls` and a component with directory `tls`.
+func mergeSecretContents(components []*compositeComponent, contents []*util.SecretContent) (*util.SecretContent, error) {
+	merged := &util.SecretContent{Data: make(map[string]string)}
+	owners := make(map[string]string)
+	directories := make(map[string]string)
+
+	for i, content := range contents {
+		component := components[i]
+		for name, value := range content.Data {
+			fileName := component.fileName(name)
+			if owner, ok := owners[fileName]; ok {
+				return nil, fmt.Errorf("file %s of secret class %s conflicts with secret class %s, set a directory or prefix",
+					fileName, component.class, owner)
+			}
+			if owner, ok := directories[fileName]; ok {
+				return nil, fmt.Errorf("file %s of secret class %s conflicts with a directory of secret class %s, "+
+					"set another directory or prefix", fileName, component.class, owner)
+			}
+			for dir := path.Dir(fileName); dir != "." && dir != "/"; dir = path.Dir(dir) {
+				if owner, ok := owners[dir]; ok {
+					return nil, fmt.Errorf("directory %s of secret class %s conflicts with a file of secret class %s, "+
+						"set another directory or prefix", dir, component.class, owner)
+				}
+				if _, ok := directories[dir]; !ok {
+					directories[dir] = component.class
+				}
+			}
+			owners[fileName] = component.class
+			merged.Data[fileName] = value
+		}
+
+		if content.ExpiresTime != nil && (merged.ExpiresTime == nil || content.ExpiresTime.Before(*merged.ExpiresTime)) {
+			expiresTime := *content.ExpiresTime
+			merged.ExpiresTime = &expiresTime
+		}
+	}
+
+	logger.V(1).Info("merged secret data of composite", "files", len(merged.Data), "expiresTime", merged.ExpiresTime)
+	return merged, nil
+}
@@ -0,0 +1,99 @@
+package backend
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/zncdatadev/secret-operator/pkg/util"
+)
+
+type testBackend struct {
+	content   *util.SecretContent
+	nodeNames []string
+}
+
+func (t *testBackend) GetSecretData(ctx context.Context) (*util.SecretContent, error) {
+	return t.content, nil
+}
+
+func (t *testBackend) GetQualifiedNodeNames(ctx context.Context) ([]string, error) {
+	return t.nodeNames, nil
+}
+
+func newTestComposite(backends map[string]*testBackend, order ...string) *CompositeBackend {
+	composite := &CompositeBackend{}
+	for _, class := range order {
+		composite.components = append(composite.components, &compositeComponent{class: class, backend: &Backend{impl: backends[class]}})
+	}
+	return composite
+}
+
+func TestCompositeGetSecretData(t *testing.T) {
+	earlier := time.Now().Add(time.Hour)
+	later := earlier.Add(time.Hour)
+
+	backends := map[string]*testBackend{
+		"kerberos": {content: &util.SecretContent{Data: map[string]string{"keytab": "k", "krb5.conf": "c"}, ExpiresTime: &later}},
+		"tls":      {content: &util.SecretContent{Data: map[string]string{"tls.crt": "t", "ca.crt": "ca"}, ExpiresTime: &earlier}},
+		"jaas":     {content: &util.SecretContent{Data: map[string]string{"tls.crt": "j"}}},
+	}
+
+	composite := newTestComposite(backends, "kerberos", "tls", "jaas")
+	composite.components[1].directory = "tls"
+	composite.components[2].prefix = "jaas-"
+
+	content, err := composite.GetSecretData(context.Background())
+	if err != nil {
+		t.Fatalf("GetSecretData() error = %v", err)
+	}
+	want := map[string]string{"keytab": "k", "krb5.conf": "c", "tls/tls.crt": "t", "tls/ca.crt": "ca", "jaas-tls.crt": "j"}
+	if !maps.Equal(content.Data, want) {
+		t.Errorf("GetSecretData() data = %v, want %v", content.Data, want)
+	}
+	if content.ExpiresTime == nil || !content.ExpiresTime.Equal(earlier) {
+		t.Errorf("GetSecretData() expiresTime = %v, want %v", content.ExpiresTime, earlier)
+	}
+
+	composite.components[1].directory = ""
+	composite.components[2].prefix = ""
+	if _, err := composite.GetSecretData(context.Background()); err == nil {
+		t.Error("GetSecretData() expected error for conflicting files")
+	}
+
+	// a file named like the directory of another secret class can not be written
+	backends["jaas"].content = &util.SecretContent{Data: map[string]string{"tls": "j"}}
+	composite.components[1].directory = "tls"
+	if _, err := composite.GetSecretData(context.Background()); err == nil {
+		t.Error("GetSecretData() expected error for a file conflicting with a directory")
+	}
+	composite.components[1].directory = "tls/certs"
+	if _, err := composite.GetSecretData(context.Background()); err == nil {
+		t.Error("GetSecretData() expected error for a file conflicting with a parent directory")
+	}
+}
+
+func TestCompositeGetQualifiedNodeNames(t *testing.T) {
+	backends := map[string]*testBackend{
+		"tls":    {},
+		"search": {nodeNames: []string{"node1", "node2", "node3"}},
+		"other":  {nodeNames: []string{"node3", "node2"}},
+		"single": {nodeNames: []string{"node1"}},
+	}
+
+	nodeNames, err := newTestComposite(backends, "tls").GetQualifiedNodeNames(context.Background())
+	if err != nil || nodeNames != nil {
+		t.Errorf("GetQualifiedNodeNames() of unrestricted = %v, %v, want nil", nodeNames, err)
+	}
+
+	nodeNames, err = newTestComposite(backends, "tls", "search", "other").GetQualifiedNodeNames(context.Background())
+	if err != nil || !slices.Equal(nodeNames, []string{"node2", "node3"}) {
+		t.Errorf("GetQualifiedNodeNames() = %v, %v, want [node2 node3]", nodeNames, err)
+	}
+
+	if _, err := newTestComposite(backends, "other", "single").GetQualifiedNodeNames(context.Background()); err == nil {
+		t.Error("GetQualifiedNodeNames() expected error for disjoint nodes")
+	}
+}
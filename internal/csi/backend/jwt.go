@@ -0,0 +1,258 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zncdatadev/operator-go/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/zncdatadev/secret-operator/internal/csi/backend/jwt"
+	"github.com/zncdatadev/secret-operator/pkg/pod_info"
+	"github.com/zncdatadev/secret-operator/pkg/util"
+	"github.com/zncdatadev/secret-operator/pkg/volume"
+)
+
+const (
+	JWTTokenFileName    = "token"
+	JWKSConfigMapKey    = "jwks.json"
+	JWKSConfigMapSuffix = "-jwks"
+
+	DefaultJWTTokenLifeTime           = 24 * time.Hour
+	DefaultJWTKeyLifeTime             = 30 * 24 * time.Hour
+	DefaultJWTKeyPrePublicationPeriod = 24 * time.Hour
+)
+
+var _ IBackend = &JWTBackend{}
+
+type JWTBackend struct {
+	client        client.Client
+	podInfo       *pod_info.PodInfo
+	volumeContext *volume.SecretVolumeContext
+	issuer        string
+	audiences     []string
+	tokenLifeTime time.Duration
+	jwksKey       client.ObjectKey
+
+	keySet *jwt.KeySet
+}
+
+// jwtClaims are the claims of a workload token, the subject has the format of Kubernetes service account tokens.
+type jwtClaims struct {
+	Issuer    string         `json:"iss"`
+	Subject   string         `json:"sub"`
+	Audience  []string       `json:"aud,omitempty"`
+	IssuedAt  int64          `json:"iat"`
+	NotBefore int64          `json:"nbf"`
+	Expiry    int64          `json:"exp"`
+	ID        string         `json:"jti"`
+	Workload  workloadClaims `json:"secrets.kubedoop.dev"`
+}
+
+// workloadClaims is the `secrets.kubedoop.dev` claim, identifying the pod the token was issued to by the scope
+// of the volume, e.g. the pod name is only set with pod scope.
+type workloadClaims struct {
+	Class          string   `json:"class"`
+	Namespace      string   `json:"namespace"`
+	ServiceAccount string   `json:"serviceAccount"`
+	Pod            string   `json:"pod,omitempty"`
+	Node           string   `json:"node,omitempty"`
+	Services       []string `json:"services,omitempty"`
+	Listeners      []string `json:"listeners,omitempty"`
+}
+
+func NewJWTBackend(config *BackendConfig) (IBackend, error) {
+	spec := config.SecretClass.Spec.Backend.JWT
+	if spec.SigningKeys == nil || spec.SigningKeys.Secret == nil {
+		return nil, errors.New("signing keys secret is nil in jwt backend")
+	}
+
+	tokenLifeTime := DefaultJWTTokenLifeTime
+	if spec.TokenLifeTime != "" {
+		d, err := time.ParseDuration(spec.TokenLifeTime)
+		if err != nil {
+			return nil, err
+		}
+		tokenLifeTime = d
+	}
+
+	keyLifeTime := DefaultJWTKeyLifeTime
+	if spec.SigningKeys.KeyLifeTime != "" {
+		d, err := time.ParseDuration(spec.SigningKeys.KeyLifeTime)
+		if err != nil {
+			return nil, err
+		}
+		keyLifeTime = d
+	}
+
+	prePublication := min(DefaultJWTKeyPrePublicationPeriod, keyLifeTime/2)
+	if spec.SigningKeys.PrePublicationPeriod != "" {
+		d, err := time.ParseDuration(spec.SigningKeys.PrePublicationPeriod)
+		if err != nil {
+			return nil, err
+		}
+		if d >= keyLifeTime {
+			return nil, fmt.Errorf("pre-publication period %s of jwt signing keys must be shorter than the key lifetime %s",
+				d, keyLifeTime)
+		}
+		prePublication = d
+	}
+
+	jwksKey := client.ObjectKey{Name: config.SecretClass.Name + JWKSConfigMapSuffix, Namespace: spec.SigningKeys.Secret.Namespace}
+	if spec.JWKSConfigMap != nil {
+		jwksKey = client.ObjectKey{Name: spec.JWKSConfigMap.Name, Namespace: spec.JWKSConfigMap.Namespace}
+	}
+
+	keySet, err := jwt.LoadKeySet(config.ctx, config.Client, spec.SigningKeys, keyLifeTime, prePublication, tokenLifeTime)
+	if err != nil {
+		return nil, err
+	}
+
+	return &JWTBackend{
+		client:        config.Client,
+		podInfo:       config.PodInfo,
+		volumeContext: config.VolumeContext,
+		issuer:        spec.Issuer,
+		audiences:     spec.Audiences,
+		tokenLifeTime: tokenLifeTime,
+		jwksKey:       jwksKey,
+		keySet:        keySet,
+	}, nil
+}
+
+func (j *JWTBackend) GetQualifiedNodeNames(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
+// GetSecretData implements Backend.
+// It publishes the JWKS document before issuing the token, so the signing key of the token is always published.
+// Verifiers caching the JWKS document should fetch it again when a token has an unknown key id.
+func (j *JWTBackend) GetSecretData(ctx context.Context) (*util.SecretContent, error) {
+	if err := j.publishJWKS(ctx); err != nil {
+		return nil, err
+	}
+
+	workload, err := j.workloadClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiry := now.Add(j.tokenLifeTime)
+	claims := &jwtClaims{
+		Issuer:    j.issuer,
+		Subject:   fmt.Sprintf("system:serviceaccount:%s:%s", workload.Namespace, workload.ServiceAccount),
+		Audience:  j.audiences,
+		IssuedAt:  now.Unix(),
+		NotBefore: now.Unix(),
+		Expiry:    expiry.Unix(),
+		ID:        uuid.NewString(),
+		Workload:  *workload,
+	}
+
+	signingKey := j.keySet.Current()
+	token, err := signingKey.Sign(claims)
+	if err != nil {
+		return nil, err
+	}
+	logger.V(1).Info("issued jwt", "subject", claims.Subject, "kid", signingKey.KeyID, "jti", claims.ID, "expiry", expiry)
+
+	// restart the pod when 80% of the token lifetime has passed
+	restartAt := expiry.Add(-j.tokenLifeTime / 5)
+
+	return &util.SecretContent{
+		Data:        map[string]string{JWTTokenFileName: token},
+		ExpiresTime: &restartAt,
+	}, nil
+}
+
+func (j *JWTBackend) workloadClaims(ctx context.Context) (*workloadClaims, error) {
+	pod := j.podInfo.Pod
+	scope := j.volumeContext.Scope
+
+	serviceAccount := pod.Spec.ServiceAccountName
+	if serviceAccount == "" {
+		serviceAccount = "default"
+	}
+
+	claims := &workloadClaims{
+		Class:          j.volumeContext.Class,
+		Namespace:      pod.Namespace,
+		ServiceAccount: serviceAccount,
+		Services:       scope.Services,
+	}
+	if scope.Pod != "" {
+		claims.Pod = pod.Name
+	}
+	if scope.Node != "" {
+		claims.Node = pod.Spec.NodeName
+	}
+
+	if len(scope.ListenerVolumes) > 0 {
+		listenerVolumesToListenerName, err := j.podInfo.GetScopedListenerVolumeNamesToListenerName(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, listenerVolume := range scope.ListenerVolumes {
+			if listenerName, ok := listenerVolumesToListenerName[listenerVolume]; ok {
+				claims.Listeners = append(claims.Listeners, listenerName)
+			}
+		}
+	}
+	return claims, nil
+}
+
+// publishJWKS writes the public keys of the key set to the JWKS configmap, when they changed.
+// The configmap is shared by all csi nodes, the update is retried when another node modified or created it.
+func (j *JWTBackend) publishJWKS(ctx context.Context) error {
+	document, err := json.MarshalIndent(j.keySet.JWKS(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return retry.OnError(retry.DefaultRetry, func(err error) bool {
+		return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+	}, func() error {
+		configMap := &corev1.ConfigMap{}
+		if err := j.client.Get(ctx, j.jwksKey, configMap); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			configMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      j.jwksKey.Name,
+					Namespace: j.jwksKey.Namespace,
+					Labels: map[string]string{
+						constants.LabelKubernetesManagedBy: "secret-operator",
+					},
+					Annotations: map[string]string{
+						constants.AnnotationSecretsClass: j.volumeContext.Class,
+					},
+				},
+			}
+		}
+
+		if configMap.Data[JWKSConfigMapKey] == string(document) {
+			return nil
+		}
+		if configMap.Data == nil {
+			configMap.Data = make(map[string]string, 1)
+		}
+		configMap.Data[JWKSConfigMapKey] = string(document)
+
+		if configMap.ResourceVersion == "" {
+			logger.V(1).Info("create jwks configmap", "name", j.jwksKey.Name, "namespace", j.jwksKey.Namespace)
+			return j.client.Create(ctx, configMap)
+		}
+		logger.V(1).Info("update jwks configmap", "name", j.jwksKey.Name, "namespace", j.jwksKey.Namespace)
+		return j.client.Update(ctx, configMap)
+	})
+}
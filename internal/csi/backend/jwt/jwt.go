@@ -0,0 +1,210 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+const (
+	AlgorithmES256 = "ES256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+
+	rsaKeyLength = 3072
+
+	// p256ByteLength is the length of coordinates and signature values of P-256 keys
+	p256ByteLength = 32
+)
+
+var encoding = base64.RawURLEncoding
+
+// JWK is the public key of a signing key as JSON Web Key, RFC 7517.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid,omitempty"`
+	Use       string `json:"use,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set document.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// Thumbprint returns the JWK thumbprint of the key, RFC 7638, used as key id.
+func (j JWK) Thumbprint() string {
+	// the required members of the key type in lexicographic order
+	required := struct {
+		Curve   string `json:"crv,omitempty"`
+		E       string `json:"e,omitempty"`
+		KeyType string `json:"kty"`
+		N       string `json:"n,omitempty"`
+		X       string `json:"x,omitempty"`
+		Y       string `json:"y,omitempty"`
+	}{j.Curve, j.E, j.KeyType, j.N, j.X, j.Y}
+
+	data, _ := json.Marshal(required)
+	sum := sha256.Sum256(data)
+	return encoding.EncodeToString(sum[:])
+}
+
+// SigningKey is a private key of the key set, identified by the thumbprint of its public key.
+type SigningKey struct {
+	KeyID     string
+	Algorithm string
+	Created   time.Time
+
+	key crypto.Signer
+	jwk JWK
+}
+
+// GenerateSigningKey generates a signing key for the algorithm, ES256 if empty.
+func GenerateSigningKey(algorithm string, created time.Time) (*SigningKey, error) {
+	var key crypto.Signer
+	var err error
+	switch algorithm {
+	case AlgorithmES256, "":
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgorithmRS256:
+		key, err = rsa.GenerateKey(rand.Reader, rsaKeyLength)
+	case AlgorithmEdDSA:
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported jwt signing algorithm %q", algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewSigningKey(key, created)
+}
+
+// NewSigningKey creates a signing key from a private key, the algorithm is derived from the key type.
+func NewSigningKey(key crypto.Signer, created time.Time) (*SigningKey, error) {
+	jwk := JWK{Use: "sig"}
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		if k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("unsupported ecdsa curve %s, only P-256 is supported", k.Curve.Params().Name)
+		}
+		point, err := k.PublicKey.Bytes()
+		if err != nil {
+			return nil, err
+		}
+		// uncompressed point: 0x04 || x || y
+		jwk.KeyType, jwk.Algorithm, jwk.Curve = "EC", AlgorithmES256, "P-256"
+		jwk.X = encoding.EncodeToString(point[1 : 1+p256ByteLength])
+		jwk.Y = encoding.EncodeToString(point[1+p256ByteLength:])
+	case *rsa.PrivateKey:
+		jwk.KeyType, jwk.Algorithm = "RSA", AlgorithmRS256
+		jwk.N = encoding.EncodeToString(k.N.Bytes())
+		jwk.E = encoding.EncodeToString(big.NewInt(int64(k.E)).Bytes())
+	case ed25519.PrivateKey:
+		jwk.KeyType, jwk.Algorithm, jwk.Curve = "OKP", AlgorithmEdDSA, "Ed25519"
+		jwk.X = encoding.EncodeToString(k.Public().(ed25519.PublicKey))
+	default:
+		return nil, fmt.Errorf("unsupported jwt signing key %T", key)
+	}
+	jwk.KeyID = jwk.Thumbprint()
+
+	return &SigningKey{KeyID: jwk.KeyID, Algorithm: jwk.Algorithm, Created: created, key: key, jwk: jwk}, nil
+}
+
+// ParseSigningKey parses a PEM private key, in PKCS #8, SEC 1 or PKCS #1 format.
+func ParseSigningKey(data []byte, created time.Time) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM private key")
+	}
+
+	var key any
+	var err error
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported jwt signing key %T", key)
+	}
+	return NewSigningKey(signer, created)
+}
+
+// MarshalPrivateKey returns the private key in PKCS #8 PEM format.
+func (k *SigningKey) MarshalPrivateKey() ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(k.key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// PublicJWK returns the public key as JWK.
+func (k *SigningKey) PublicJWK() JWK {
+	return k.jwk
+}
+
+// Sign returns a compact serialized JWS of the claims, with the key id in the header.
+func (k *SigningKey) Sign(claims any) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": k.Algorithm, "kid": k.KeyID, "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := encoding.EncodeToString(header) + "." + encoding.EncodeToString(payload)
+	signature, err := k.sign([]byte(signingInput))
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + encoding.EncodeToString(signature), nil
+}
+
+func (k *SigningKey) sign(data []byte) ([]byte, error) {
+	switch key := k.key.(type) {
+	case *ecdsa.PrivateKey:
+		digest := sha256.Sum256(data)
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		if err != nil {
+			return nil, err
+		}
+		// JWS uses the fixed length r || s encoding instead of ASN.1
+		signature := make([]byte, 2*p256ByteLength)
+		r.FillBytes(signature[:p256ByteLength])
+		s.FillBytes(signature[p256ByteLength:])
+		return signature, nil
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256(data)
+		return rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	case ed25519.PrivateKey:
+		return ed25519.Sign(key, data), nil
+	}
+	return nil, fmt.Errorf("unsupported jwt signing key %T", k.key)
+}
@@ -0,0 +1,194 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestThumbprint(t *testing.T) {
+	// example of RFC 7638, section 3.1
+	jwk := JWK{
+		KeyType:   "RSA",
+		KeyID:     "2011-04-29",
+		Algorithm: "RS256",
+		N: "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn6" +
+			"4tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n9" +
+			"1CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		E: "AQAB",
+	}
+	if got, want := jwk.Thumbprint(), "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"; got != want {
+		t.Errorf("Thumbprint() = %q, want %q", got, want)
+	}
+}
+
+func TestSign(t *testing.T) {
+	for _, algorithm := range []string{AlgorithmES256, AlgorithmRS256, AlgorithmEdDSA} {
+		t.Run(algorithm, func(t *testing.T) {
+			key, err := GenerateSigningKey(algorithm, time.Now())
+			if err != nil {
+				t.Fatalf("GenerateSigningKey() error = %v", err)
+			}
+
+			// the key survives a round trip through the secret
+			privateKey, err := key.MarshalPrivateKey()
+			if err != nil {
+				t.Fatalf("MarshalPrivateKey() error = %v", err)
+			}
+			parsed, err := ParseSigningKey(privateKey, key.Created)
+			if err != nil {
+				t.Fatalf("ParseSigningKey() error = %v", err)
+			}
+			if parsed.KeyID != key.KeyID || parsed.Algorithm != algorithm {
+				t.Errorf("ParseSigningKey() = %s %s, want %s %s", parsed.KeyID, parsed.Algorithm, key.KeyID, algorithm)
+			}
+
+			token, err := parsed.Sign(map[string]string{"sub": "system:serviceaccount:default:web"})
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+
+			parts := strings.Split(token, ".")
+			if len(parts) != 3 {
+				t.Fatalf("Sign() = %q, want three parts", token)
+			}
+			header := map[string]string{}
+			if err := decodeJSON(parts[0], &header); err != nil {
+				t.Fatalf("invalid header: %v", err)
+			}
+			if header["alg"] != algorithm || header["kid"] != key.KeyID {
+				t.Errorf("Sign() header = %v", header)
+			}
+
+			signature, err := encoding.DecodeString(parts[2])
+			if err != nil {
+				t.Fatalf("invalid signature: %v", err)
+			}
+			if !verify(t, key.PublicJWK(), []byte(parts[0]+"."+parts[1]), signature) {
+				t.Error("Sign() signature does not verify with the published key")
+			}
+		})
+	}
+}
+
+func TestRotateSigningKeys(t *testing.T) {
+	now := time.Now()
+	keyLifeTime := 30 * 24 * time.Hour
+	prePublication := 24 * time.Hour
+	tokenLifeTime := time.Hour
+
+	keys, changed, err := rotateSigningKeys(nil, AlgorithmES256, now, keyLifeTime, prePublication, tokenLifeTime)
+	if err != nil {
+		t.Fatalf("rotateSigningKeys() error = %v", err)
+	}
+	if !changed || len(keys) != 1 {
+		t.Fatalf("rotateSigningKeys() of no keys = %d keys, changed %t, want a generated key", len(keys), changed)
+	}
+	// the first key signs immediately, no verifier cached a JWKS document without it
+	if current := currentSigningKey(keys, now, prePublication); current != 0 {
+		t.Errorf("currentSigningKey() of the first key = %d, want 0", current)
+	}
+
+	if _, changed, _ := rotateSigningKeys(keys, AlgorithmES256, now.Add(keyLifeTime/2), keyLifeTime, prePublication, tokenLifeTime); changed {
+		t.Error("rotateSigningKeys() rotated a key before its key lifetime")
+	}
+
+	// the new key is published for the pre-publication period before it signs
+	rotatedAt := now.Add(keyLifeTime)
+	keys, changed, _ = rotateSigningKeys(keys, AlgorithmES256, rotatedAt, keyLifeTime, prePublication, tokenLifeTime)
+	if !changed || len(keys) != 2 || !keys[1].Created.Equal(rotatedAt) {
+		t.Fatalf("rotateSigningKeys() at key lifetime = %d keys, changed %t, want a new key", len(keys), changed)
+	}
+	if current := currentSigningKey(keys, rotatedAt.Add(prePublication-time.Second), prePublication); current != 0 {
+		t.Errorf("currentSigningKey() within the pre-publication period = %d, want the previous key", current)
+	}
+	currentAt := rotatedAt.Add(prePublication)
+	if current := currentSigningKey(keys, currentAt, prePublication); current != 1 {
+		t.Errorf("currentSigningKey() after the pre-publication period = %d, want the new key", current)
+	}
+	current := keys[1].KeyID
+
+	// the previous key stays published for the token lifetime after the new key became current
+	keys, _, _ = rotateSigningKeys(keys, AlgorithmES256, currentAt.Add(tokenLifeTime-time.Second), keyLifeTime, prePublication, tokenLifeTime)
+	if len(keys) != 2 {
+		t.Errorf("rotateSigningKeys() within token lifetime = %d keys, want the previous and current key", len(keys))
+	}
+	keys, _, _ = rotateSigningKeys(keys, AlgorithmES256, currentAt.Add(tokenLifeTime), keyLifeTime, prePublication, tokenLifeTime)
+	if len(keys) != 1 || keys[0].KeyID != current {
+		t.Errorf("rotateSigningKeys() after token lifetime = %d keys, want only the current key", len(keys))
+	}
+}
+
+func TestParseSigningKeys(t *testing.T) {
+	older, _ := GenerateSigningKey(AlgorithmEdDSA, time.Now().Add(-time.Hour).Truncate(time.Second))
+	newer, _ := GenerateSigningKey(AlgorithmES256, time.Now().Truncate(time.Second))
+
+	data, err := marshalSigningKeys([]*SigningKey{newer, older})
+	if err != nil {
+		t.Fatalf("marshalSigningKeys() error = %v", err)
+	}
+	// a manually added key without creation time is the oldest
+	manual, _ := GenerateSigningKey(AlgorithmRS256, time.Time{})
+	data["manual.key"], _ = manual.MarshalPrivateKey()
+
+	keys, err := parseSigningKeys(data)
+	if err != nil {
+		t.Fatalf("parseSigningKeys() error = %v", err)
+	}
+	if len(keys) != 3 || keys[0].KeyID != manual.KeyID || keys[1].KeyID != older.KeyID || keys[2].KeyID != newer.KeyID {
+		t.Errorf("parseSigningKeys() did not sort the keys by creation time")
+	}
+	if !keys[2].Created.Equal(newer.Created) {
+		t.Errorf("parseSigningKeys() created = %s, want %s", keys[2].Created, newer.Created)
+	}
+}
+
+func decodeJSON(part string, v any) error {
+	data, err := encoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func decodeInt(t *testing.T, value string) *big.Int {
+	data, err := encoding.DecodeString(value)
+	if err != nil {
+		t.Fatalf("invalid jwk value %q: %v", value, err)
+	}
+	return new(big.Int).SetBytes(data)
+}
+
+// verify checks the signature with the public key of the JWK, as a verifier only knowing the JWKS document would.
+func verify(t *testing.T, jwk JWK, data, signature []byte) bool {
+	digest := sha256.Sum256(data)
+	switch jwk.Algorithm {
+	case AlgorithmES256:
+		x, y := decodeInt(t, jwk.X).FillBytes(make([]byte, 32)), decodeInt(t, jwk.Y).FillBytes(make([]byte, 32))
+		publicKey, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), append(append([]byte{4}, x...), y...))
+		if err != nil {
+			t.Fatalf("invalid ec jwk: %v", err)
+		}
+		r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(publicKey, digest[:], r, s)
+	case AlgorithmRS256:
+		publicKey := &rsa.PublicKey{N: decodeInt(t, jwk.N), E: int(decodeInt(t, jwk.E).Int64())}
+		return rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature) == nil
+	case AlgorithmEdDSA:
+		publicKey, err := encoding.DecodeString(jwk.X)
+		if err != nil {
+			t.Fatalf("invalid okp jwk: %v", err)
+		}
+		return ed25519.Verify(publicKey, data, signature)
+	}
+	t.Fatalf("unsupported algorithm %q", jwk.Algorithm)
+	return false
+}
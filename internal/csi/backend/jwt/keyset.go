@@ -0,0 +1,232 @@
+package jwt
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zncdatadev/operator-go/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1alpha1 "github.com/zncdatadev/secret-operator/api/v1alpha1"
+)
+
+const (
+	// PrivateKeySuffix and CreatedSuffix are the suffixes of the keys of a signing key in the secret
+	PrivateKeySuffix = ".key"
+	CreatedSuffix    = ".created"
+)
+
+var (
+	logger = ctrl.Log.WithName("jwt-keys")
+)
+
+// KeySet holds the signing keys of a secret class, sorted by creation time.
+// The newest key published for the pre-publication period signs tokens, all keys are published in the JWKS document.
+type KeySet struct {
+	keys    []*SigningKey
+	current int
+}
+
+// Current returns the signing key of new tokens.
+func (s *KeySet) Current() *SigningKey {
+	return s.keys[s.current]
+}
+
+// JWKS returns the public keys of the key set.
+func (s *KeySet) JWKS() *JWKS {
+	jwks := &JWKS{Keys: make([]JWK, 0, len(s.keys))}
+	for _, key := range s.keys {
+		jwks.Keys = append(jwks.Keys, key.PublicJWK())
+	}
+	return jwks
+}
+
+// LoadKeySet gets the signing keys from the secret of the spec.
+// With auto generate, a signing key is generated when there is none or the newest key is older than the key lifetime,
+// it signs tokens once it has been published for the pre-publication period. Previous keys are dropped once they
+// have been superseded for the token lifetime, when all tokens they signed expired.
+func LoadKeySet(
+	ctx context.Context,
+	c client.Client,
+	spec *secretsv1alpha1.JWTSigningKeysSpec,
+	keyLifeTime time.Duration,
+	prePublication time.Duration,
+	tokenLifeTime time.Duration,
+) (*KeySet, error) {
+	now := time.Now()
+	var keys []*SigningKey
+	err := retry.OnError(retry.DefaultRetry, func(err error) bool {
+		return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+	}, func() error {
+		secret := &corev1.Secret{}
+		if err := c.Get(ctx, client.ObjectKey{Name: spec.Secret.Name, Namespace: spec.Secret.Namespace}, secret); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      spec.Secret.Name,
+					Namespace: spec.Secret.Namespace,
+					Labels: map[string]string{
+						constants.LabelKubernetesManagedBy: "secret-operator",
+					},
+				},
+				Type: corev1.SecretTypeOpaque,
+			}
+		}
+
+		var err error
+		if keys, err = parseSigningKeys(secret.Data); err != nil {
+			return fmt.Errorf("invalid jwt signing keys in secret %s/%s: %w", secret.Namespace, secret.Name, err)
+		}
+
+		if !spec.AutoGenerate {
+			if len(keys) == 0 {
+				return fmt.Errorf("could not find jwt signing keys in secret %s/%s, and auto-generate is false, please create manually",
+					spec.Secret.Namespace, spec.Secret.Name)
+			}
+			return nil
+		}
+
+		var changed bool
+		if keys, changed, err = rotateSigningKeys(keys, spec.Algorithm, now, keyLifeTime, prePublication, tokenLifeTime); err != nil {
+			return err
+		}
+		if !changed {
+			return nil
+		}
+
+		data, err := marshalSigningKeys(keys)
+		if err != nil {
+			return err
+		}
+		secret.Data = data
+
+		if secret.ResourceVersion == "" {
+			err = c.Create(ctx, secret)
+		} else {
+			err = c.Update(ctx, secret)
+		}
+		if err != nil {
+			return err
+		}
+		logger.Info("rotated jwt signing keys", "name", secret.Name, "namespace", secret.Namespace,
+			"current", keys[currentSigningKey(keys, now, prePublication)].KeyID, "newest", keys[len(keys)-1].KeyID,
+			"keys", len(keys))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &KeySet{keys: keys, current: currentSigningKey(keys, now, prePublication)}, nil
+}
+
+// currentSigningKey returns the index of the newest key published for the pre-publication period, so verifiers
+// caching the JWKS document know a key before it signs tokens. When no key is published long enough,
+// e.g. the first generated key, the oldest key signs, as there is no cached JWKS document to miss it.
+func currentSigningKey(keys []*SigningKey, now time.Time, prePublication time.Duration) int {
+	for i := len(keys) - 1; i >= 0; i-- {
+		if now.Sub(keys[i].Created) >= prePublication {
+			return i
+		}
+	}
+	return 0
+}
+
+// parseSigningKeys parses the `<name>.key` private keys with their `<name>.created` creation time,
+// a key without creation time is the oldest. Keys are sorted by creation time, then key id.
+func parseSigningKeys(data map[string][]byte) ([]*SigningKey, error) {
+	keys := make([]*SigningKey, 0, len(data)/2)
+	for name, value := range data {
+		prefix, ok := strings.CutSuffix(name, PrivateKeySuffix)
+		if !ok {
+			continue
+		}
+
+		var created time.Time
+		if createdValue, ok := data[prefix+CreatedSuffix]; ok {
+			var err error
+			if created, err = time.Parse(time.RFC3339, string(createdValue)); err != nil {
+				return nil, fmt.Errorf("invalid creation time of signing key %s: %w", name, err)
+			}
+		}
+
+		key, err := ParseSigningKey(value, created)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signing key %s: %w", name, err)
+		}
+		if !slices.ContainsFunc(keys, func(k *SigningKey) bool { return k.KeyID == key.KeyID }) {
+			keys = append(keys, key)
+		}
+	}
+
+	sortSigningKeys(keys)
+	return keys, nil
+}
+
+func sortSigningKeys(keys []*SigningKey) {
+	slices.SortFunc(keys, func(a, b *SigningKey) int {
+		if c := a.Created.Compare(b.Created); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.KeyID, b.KeyID)
+	})
+}
+
+func marshalSigningKeys(keys []*SigningKey) (map[string][]byte, error) {
+	data := make(map[string][]byte, 2*len(keys))
+	for _, key := range keys {
+		privateKey, err := key.MarshalPrivateKey()
+		if err != nil {
+			return nil, err
+		}
+		data[key.KeyID+PrivateKeySuffix] = privateKey
+		data[key.KeyID+CreatedSuffix] = []byte(key.Created.UTC().Format(time.RFC3339))
+	}
+	return data, nil
+}
+
+// rotateSigningKeys generates a signing key when there is none or the newest key reached the key lifetime,
+// and drops keys superseded for longer than the token lifetime. It returns the keys and whether they changed.
+// A generated key becomes current after the pre-publication period, so every key signs for the key lifetime.
+func rotateSigningKeys(
+	keys []*SigningKey,
+	algorithm string,
+	now time.Time,
+	keyLifeTime time.Duration,
+	prePublication time.Duration,
+	tokenLifeTime time.Duration,
+) ([]*SigningKey, bool, error) {
+	changed := false
+	if len(keys) == 0 || now.Sub(keys[len(keys)-1].Created) >= keyLifeTime {
+		key, err := GenerateSigningKey(algorithm, now)
+		if err != nil {
+			return nil, false, err
+		}
+		keys = append(keys, key)
+		changed = true
+	}
+
+	current := currentSigningKey(keys, now, prePublication)
+	retained := make([]*SigningKey, 0, len(keys))
+	for i, key := range keys {
+		// a key is superseded when a newer key becomes current
+		if i < current && now.Sub(keys[i+1].Created.Add(prePublication)) >= tokenLifeTime {
+			logger.V(1).Info("drop superseded jwt signing key", "kid", key.KeyID,
+				"supersededAt", keys[i+1].Created.Add(prePublication))
+			changed = true
+			continue
+		}
+		retained = append(retained, key)
+	}
+	return retained, changed, nil
+}
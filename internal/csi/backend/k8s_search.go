@@ -1,27 +1,53 @@
 package backend
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/zncdatadev/operator-go/pkg/constants"
 	secretsv1alpha1 "github.com/zncdatadev/secret-operator/api/v1alpha1"
+	"github.com/zncdatadev/secret-operator/internal/metrics"
 	"github.com/zncdatadev/secret-operator/pkg/pod_info"
 	"github.com/zncdatadev/secret-operator/pkg/util"
 	"github.com/zncdatadev/secret-operator/pkg/volume"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+const (
+	K8sSearchKindSecret    = "Secret"
+	K8sSearchKindConfigMap = "ConfigMap"
+
+	K8sSearchMultipleMatchesFail  = "Fail"
+	K8sSearchMultipleMatchesMerge = "Merge"
+
+	// AnnotationSecretsPriority orders secrets and configmaps with the same number of scope labels when
+	// multiple objects match a volume, the value of the object with the highest priority wins. Default is 0.
+	AnnotationSecretsPriority = "secrets.kubedoop.dev/priority"
+)
+
 var _ IBackend = &K8sSearchBackend{}
 
 type K8sSearchBackend struct {
 	client          client.Client
 	podInfo         *pod_info.PodInfo
 	volumeContext   *volume.SecretVolumeContext
+	class           string
 	searchNamespace *secretsv1alpha1.SearchNamespaceSpec
+	kinds           []string
+	multipleMatches string
+	scopeLabels     scopeLabelKeys
+	labelSelector   *metav1.LabelSelector
+	// generateSpec is nil when generating missing secrets is not enabled in the secret class
+	generateSpec *secretsv1alpha1.GenerateSpec
 }
 
 func NewK8sSearchBackend(config *BackendConfig) (IBackend, error) {
@@ -34,11 +60,31 @@ func NewK8sSearchBackend(config *BackendConfig) (IBackend, error) {
 		return nil, errors.New("searchNamespace is nil in secret class")
 	}
 
+	kinds := spec.Kinds
+	if len(kinds) == 0 {
+		kinds = []string{K8sSearchKindSecret}
+	}
+
+	if spec.Generate != nil && !slices.Contains(kinds, K8sSearchKindSecret) {
+		return nil, errors.New("generate in k8sSearch backend requires Secret in kinds")
+	}
+
+	multipleMatches := spec.MultipleMatches
+	if multipleMatches == "" {
+		multipleMatches = K8sSearchMultipleMatchesMerge
+	}
+
 	return &K8sSearchBackend{
 		client:          config.Client,
 		podInfo:         config.PodInfo,
 		volumeContext:   config.VolumeContext,
+		class:           config.SecretClass.Name,
 		searchNamespace: spec.SearchNamespace,
+		kinds:           kinds,
+		multipleMatches: multipleMatches,
+		scopeLabels:     newScopeLabelKeys(spec.ScopeLabels),
+		labelSelector:   spec.LabelSelector,
+		generateSpec:    spec.Generate,
 	}, nil
 }
 
@@ -59,64 +105,155 @@ func (k *K8sSearchBackend) namespace() (string, error) {
 	return "", errors.New("can not found namespace name in searchNamespace field")
 }
 
-// GetSecretData implements Backend.
-func (k *K8sSearchBackend) getSecretList(ctx context.Context, matchingLabels map[string]string) (*corev1.SecretList, error) {
+// searchResult is a secret or configmap matching the labels of a volume.
+type searchResult struct {
+	metav1.ObjectMeta
+	kind string
+	data map[string][]byte
+}
+
+func (r *searchResult) String() string {
+	return r.kind + "/" + r.Name
+}
+
+// search lists the objects of the searched kinds in the search namespace matching the selector.
+func (k *K8sSearchBackend) search(ctx context.Context, selector labels.Selector) ([]searchResult, error) {
 	namespace, err := k.namespace()
 	if err != nil {
 		return nil, err
 	}
 
-	logger.V(1).Info("searching secrets", "namespace", namespace, "matchingLabels", matchingLabels)
-	objs := &corev1.SecretList{}
-	if err := k.client.List(ctx, objs, client.InNamespace(namespace), client.MatchingLabels(matchingLabels)); err != nil {
-		return nil, err
+	logger.V(1).Info("searching objects", "kinds", k.kinds, "namespace", namespace, "selector", selector.String())
+	opts := []client.ListOption{client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}}
+	results := make([]searchResult, 0)
+
+	if slices.Contains(k.kinds, K8sSearchKindSecret) {
+		secrets := &corev1.SecretList{}
+		if err := k.client.List(ctx, secrets, opts...); err != nil {
+			return nil, err
+		}
+		for _, secret := range secrets.Items {
+			results = append(results, searchResult{ObjectMeta: secret.ObjectMeta, kind: K8sSearchKindSecret, data: secret.Data})
+		}
 	}
 
-	if len(objs.Items) == 0 {
-		return nil, fmt.Errorf("can not found secret in namespace %s with labels: %v", namespace, matchingLabels)
+	if slices.Contains(k.kinds, K8sSearchKindConfigMap) {
+		configMaps := &corev1.ConfigMapList{}
+		if err := k.client.List(ctx, configMaps, opts...); err != nil {
+			return nil, err
+		}
+		for _, configMap := range configMaps.Items {
+			data := make(map[string][]byte, len(configMap.Data)+len(configMap.BinaryData))
+			for key, value := range configMap.Data {
+				data[key] = []byte(value)
+			}
+			maps.Copy(data, configMap.BinaryData)
+			results = append(results, searchResult{ObjectMeta: configMap.ObjectMeta, kind: K8sSearchKindConfigMap, data: data})
+		}
 	}
 
-	secretNames := make([]string, 0, len(objs.Items))
-	for _, obj := range objs.Items {
-		secretNames = append(secretNames, obj.GetName())
+	names := make([]string, 0, len(results))
+	for _, result := range results {
+		names = append(names, result.String())
 	}
-	logger.V(1).Info("found secrets", "total", len(secretNames), "secrets", secretNames, "namespace", namespace, "matchingLabels", matchingLabels)
+	logger.V(1).Info("found objects", "total", len(names), "objects", names, "namespace", namespace, "selector", selector.String())
 
-	return objs, nil
+	return results, nil
 }
 
 // matchingLabels returns the labels that should be used to search for the secret.
-// The labels are based on the secret class and the volume selector.
+// The labels are based on the secret class and the volume selector, labels with a disabled key are skipped.
 func (k *K8sSearchBackend) matchingLabels(ctx context.Context, hasListenerNodeScope bool) (map[string]string, error) {
-	labels := map[string]string{constants.AnnotationSecretsClass: k.volumeContext.Class}
+	keys := k.scopeLabels
+	matchingLabels := make(map[string]string)
+	if keys.class != "" {
+		matchingLabels[keys.class] = k.volumeContext.Class
+	}
 
 	scope := k.volumeContext.Scope
 	pod := k.getPod()
 
-	if scope.Pod != "" {
-		labels[constants.LabelSecretsPod] = pod.GetName()
+	if scope.Pod != "" && keys.pod != "" {
+		matchingLabels[keys.pod] = pod.GetName()
 	}
 
-	if scope.Services != nil {
-		labels[constants.LabelSecretsService] = strings.Join(scope.Services, ",")
+	if scope.Services != nil && keys.service != "" {
+		matchingLabels[keys.service] = strings.Join(scope.Services, ",")
 	}
 
-	if scope.Node != "" || hasListenerNodeScope {
-		labels[constants.LabelSecretsNode] = pod.Spec.NodeName
+	if (scope.Node != "" || hasListenerNodeScope) && keys.node != "" {
+		matchingLabels[keys.node] = pod.Spec.NodeName
 	}
 
+	if keys.listenerPrefix == "" {
+		return matchingLabels, nil
+	}
 	listenerVolumesToListenerName, err := k.podInfo.GetScopedListenerVolumeNamesToListenerName(ctx)
 	if err != nil {
 		return nil, err
 	}
 	for idx, listenerVolume := range scope.ListenerVolumes {
-		label := fmt.Sprintf("secrets.stackable.tech/listener.%d", idx+1)
+		label := fmt.Sprintf("%s%d", keys.listenerPrefix, idx+1)
+		if listenerName, ok := listenerVolumesToListenerName[listenerVolume]; ok {
+			matchingLabels[label] = listenerName
+		}
+	}
+
+	return matchingLabels, nil
+}
+
+// selector returns the selector of the objects to search, the matching labels combined with
+// the label selector of the secret class evaluated for the volume.
+func (k *K8sSearchBackend) selector(ctx context.Context, hasListenerNodeScope bool) (labels.Selector, error) {
+	matchingLabels, err := k.matchingLabels(ctx, hasListenerNodeScope)
+	if err != nil {
+		return nil, err
+	}
+	selector := labels.SelectorFromSet(matchingLabels)
+
+	if k.labelSelector != nil {
+		values, err := k.labelSelectorValues(ctx)
+		if err != nil {
+			return nil, err
+		}
+		requirements, err := labelSelectorRequirements(k.labelSelector, values)
+		if err != nil {
+			return nil, err
+		}
+		selector = selector.Add(requirements...)
+	}
+
+	if selector.Empty() {
+		return nil, errors.New("no labels to search in k8sSearch backend, enable a scope label or set a label selector")
+	}
+	return selector, nil
+}
+
+func (k *K8sSearchBackend) labelSelectorValues(ctx context.Context) (*labelSelectorValues, error) {
+	pod := k.getPod()
+	scope := k.volumeContext.Scope
+
+	listenerVolumesToListenerName, err := k.podInfo.GetScopedListenerVolumeNamesToListenerName(ctx)
+	if err != nil {
+		return nil, err
+	}
+	listeners := make([]string, 0, len(scope.ListenerVolumes))
+	for _, listenerVolume := range scope.ListenerVolumes {
 		if listenerName, ok := listenerVolumesToListenerName[listenerVolume]; ok {
-			labels[label] = listenerName
+			listeners = append(listeners, listenerName)
 		}
 	}
 
-	return labels, nil
+	return &labelSelectorValues{
+		Class:          k.volumeContext.Class,
+		Namespace:      pod.GetNamespace(),
+		Pod:            pod.GetName(),
+		ServiceAccount: pod.Spec.ServiceAccountName,
+		Node:           pod.Spec.NodeName,
+		PodLabels:      pod.GetLabels(),
+		Services:       scope.Services,
+		Listeners:      listeners,
+	}, nil
 }
 
 // GetQualifiedNodeNames implements Backend.
@@ -132,22 +269,22 @@ func (k *K8sSearchBackend) GetQualifiedNodeNames(ctx context.Context) ([]string,
 		return nil, nil
 	}
 
-	matchingLabels, err := k.matchingLabels(ctx, hasListenerNodeScope)
+	selector, err := k.selector(ctx, hasListenerNodeScope)
 	if err != nil {
 		return nil, err
 	}
 
-	objs, err := k.getSecretList(ctx, matchingLabels)
+	objs, err := k.search(ctx, selector)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(objs.Items) == 0 {
+	if len(objs) == 0 {
 		return nil, nil
 	}
 
-	ndoes := make([]string, 0, len(objs.Items))
-	for _, obj := range objs.Items {
+	ndoes := make([]string, 0, len(objs))
+	for _, obj := range objs {
 		if obj.Annotations != nil {
 			if node, ok := obj.Annotations[constants.LabelSecretsNode]; ok {
 				ndoes = append(ndoes, node)
@@ -159,7 +296,7 @@ func (k *K8sSearchBackend) GetQualifiedNodeNames(ctx context.Context) ([]string,
 		return nil, err
 	}
 	logger.V(1).Info("found nodes from secrets with labels when listener node scope is enabled",
-		"total", len(ndoes), "nodes", ndoes, "namespace", namespace, "matchingLabels", matchingLabels,
+		"total", len(ndoes), "nodes", ndoes, "namespace", namespace, "selector", selector.String(),
 	)
 	return ndoes, nil
 }
@@ -176,24 +313,47 @@ func (k *K8sSearchBackend) GetSecretData(ctx context.Context) (*util.SecretConte
 		return nil, err
 	}
 
-	matchingLabels, err := k.matchingLabels(ctx, hasListenerNodeScope)
+	selector, err := k.selector(ctx, hasListenerNodeScope)
 	if err != nil {
 		return nil, err
 	}
 
-	objs, err := k.getSecretList(ctx, matchingLabels)
+	objs, err := k.search(ctx, selector)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(objs.Items) == 0 {
-		return nil, fmt.Errorf("can not found secret in namespace %s with labels: %v", namespace, matchingLabels)
+	if len(objs) == 0 {
+		metrics.K8sSearchMisses.WithLabelValues(k.class).Inc()
+	}
+
+	if len(objs) == 0 && k.generateSpec != nil {
+		if objs, err = k.generate(ctx, selector); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(objs) == 0 {
+		return nil, fmt.Errorf("can not found %v in namespace %s with selector: %s", k.kinds, namespace, selector)
 	}
 
-	secret := objs.Items[0]
-	logger.V(1).Info("found secret", "name", secret.GetName(), "namespace", secret.GetNamespace())
+	if len(objs) > 1 && k.multipleMatches == K8sSearchMultipleMatchesFail {
+		names := make([]string, 0, len(objs))
+		for _, obj := range objs {
+			names = append(names, obj.String())
+		}
+		slices.Sort(names)
+		return nil, fmt.Errorf("found %d objects in namespace %s with selector %s, expected one: %v",
+			len(names), namespace, selector, names)
+	}
 
-	decoded, err := DecodeSecretData(secret.Data)
+	data, sources, err := mergeSearchResults(objs, k.scopeLabels)
+	if err != nil {
+		return nil, err
+	}
+	logger.V(1).Info("found secret data", "namespace", namespace, "keySources", sources)
+
+	decoded, err := DecodeSecretData(data)
 	if err != nil {
 		return nil, err
 	}
@@ -203,6 +363,66 @@ func (k *K8sSearchBackend) GetSecretData(ctx context.Context) (*util.SecretConte
 	}, nil
 }
 
+// mergeSearchResults merges the data of the objects, and returns the object supplying each key.
+// When objects have the same key, the value is taken from the object with the most scope labels,
+// then the highest priority annotation, then the first object by name.
+func mergeSearchResults(results []searchResult, keys scopeLabelKeys) (map[string][]byte, map[string]string, error) {
+	type candidate struct {
+		result      *searchResult
+		specificity int
+		priority    int
+	}
+
+	candidates := make([]candidate, 0, len(results))
+	for i := range results {
+		result := &results[i]
+		priority := 0
+		if value, ok := result.Annotations[AnnotationSecretsPriority]; ok {
+			p, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid %s annotation %q of %s in namespace %s: %w",
+					AnnotationSecretsPriority, value, result, result.Namespace, err)
+			}
+			priority = p
+		}
+		candidates = append(candidates, candidate{result: result, specificity: scopeSpecificity(result.Labels, keys), priority: priority})
+	}
+
+	slices.SortFunc(candidates, func(a, b candidate) int {
+		return cmp.Or(
+			cmp.Compare(b.specificity, a.specificity),
+			cmp.Compare(b.priority, a.priority),
+			cmp.Compare(a.result.Name, b.result.Name),
+			cmp.Compare(a.result.kind, b.result.kind),
+		)
+	})
+
+	data := make(map[string][]byte)
+	sources := make(map[string]string)
+	for _, c := range candidates {
+		for key, value := range c.result.data {
+			if _, ok := data[key]; ok {
+				continue
+			}
+			data[key] = value
+			sources[key] = c.result.String()
+		}
+	}
+	return data, sources, nil
+}
+
+// scopeSpecificity returns the number of scope labels, an object for a pod or a node
+// is more specific than an object shared by all volumes of the secret class.
+func scopeSpecificity(labels map[string]string, keys scopeLabelKeys) int {
+	specificity := 0
+	for label := range labels {
+		if keys.isScopeLabel(label) {
+			specificity++
+		}
+	}
+	return specificity
+}
+
 // DecodeSecretData decodes the secret data.
 // secret data is base64 encoded.
 func DecodeSecretData(data map[string][]byte) (map[string]string, error) {
@@ -0,0 +1,207 @@
+package backend
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+
+	"github.com/zncdatadev/operator-go/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1alpha1 "github.com/zncdatadev/secret-operator/api/v1alpha1"
+)
+
+const (
+	GeneratedCharsetAlphanumeric = "Alphanumeric"
+	GeneratedCharsetNumeric      = "Numeric"
+	GeneratedCharsetHex          = "Hex"
+	GeneratedCharsetPrintable    = "Printable"
+
+	DefaultGeneratedLength = 32
+)
+
+var generatedCharsets = map[string]string{
+	GeneratedCharsetAlphanumeric: "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789",
+	GeneratedCharsetNumeric:      "0123456789",
+	GeneratedCharsetHex:          "0123456789abcdef",
+	GeneratedCharsetPrintable:    "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789!#$%&()*+,-./:;<=>?@[]^_{|}~",
+}
+
+// generateValue returns a random value of the key, every character is drawn uniformly from the charset.
+func generateValue(key *secretsv1alpha1.GeneratedKeySpec) (string, error) {
+	length := key.Length
+	if length == 0 {
+		length = DefaultGeneratedLength
+	}
+
+	characters := key.Characters
+	if characters == "" {
+		charset := key.Charset
+		if charset == "" {
+			charset = GeneratedCharsetAlphanumeric
+		}
+		var ok bool
+		if characters, ok = generatedCharsets[charset]; !ok {
+			return "", fmt.Errorf("unknown charset %q of generated key %s", charset, key.Name)
+		}
+	}
+
+	runes := []rune(characters)
+	size := big.NewInt(int64(len(runes)))
+	value := make([]rune, length)
+	for i := range value {
+		n, err := rand.Int(rand.Reader, size)
+		if err != nil {
+			return "", err
+		}
+		value[i] = runes[n.Int64()]
+	}
+	return string(value), nil
+}
+
+// generatedSecretLabels returns the labels a generated secret needs to match the selector,
+// from its equality requirements.
+func generatedSecretLabels(selector labels.Selector) (labels.Set, error) {
+	set := labels.Set{}
+	requirements, _ := selector.Requirements()
+	for _, requirement := range requirements {
+		switch requirement.Operator() {
+		case selection.Equals, selection.DoubleEquals, selection.In:
+			if requirement.Values().Len() == 1 {
+				set[requirement.Key()] = requirement.Values().UnsortedList()[0]
+			}
+		}
+	}
+
+	if !selector.Matches(set) {
+		return nil, fmt.Errorf("can not generate a secret matching selector %s, "+
+			"only equality and single value in requirements are set as labels", selector)
+	}
+	for key, value := range set {
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return nil, fmt.Errorf("can not generate a secret with label %s=%q: %s", key, value, strings.Join(errs, "; "))
+		}
+	}
+	return set, nil
+}
+
+// generatedSecretName derives the name from the labels, so nodes generating a secret for the same
+// volume scope at the same time create the same secret.
+func generatedSecretName(class string, set labels.Set) string {
+	hash := sha256.Sum256([]byte(set.String()))
+	return fmt.Sprintf("%s-%s", class, hex.EncodeToString(hash[:8]))
+}
+
+// generatedSecretOwner returns the owner of a secret generated for the volume scope, so the secret is
+// deleted with the pod or service it was generated for. Secrets of other scopes are shared and have no owner.
+// Owners must be in the namespace of the secret, pod and service scoped secrets are generated in the pod namespace.
+func (k *K8sSearchBackend) generatedSecretOwner(ctx context.Context, namespace string) (*metav1.OwnerReference, error) {
+	scope := k.volumeContext.Scope
+	pod := k.getPod()
+
+	podScoped := scope.Pod != "" && k.scopeLabels.pod != ""
+	serviceScoped := len(scope.Services) > 0 && k.scopeLabels.service != ""
+	if !podScoped && !serviceScoped {
+		return nil, nil
+	}
+	if namespace != pod.Namespace {
+		return nil, fmt.Errorf("can not generate a pod or service scoped secret in namespace %s, "+
+			"it must be in the pod namespace %s to be deleted with its owner", namespace, pod.Namespace)
+	}
+
+	if podScoped {
+		return &metav1.OwnerReference{APIVersion: "v1", Kind: "Pod", Name: pod.Name, UID: pod.UID}, nil
+	}
+
+	if len(scope.Services) > 1 {
+		return nil, fmt.Errorf("can not generate a secret for multiple services %v, the service label holds one service",
+			scope.Services)
+	}
+	service := &corev1.Service{}
+	if err := k.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: scope.Services[0]}, service); err != nil {
+		return nil, fmt.Errorf("failed to get service %s/%s owning the generated secret: %w", namespace, scope.Services[0], err)
+	}
+	return &metav1.OwnerReference{APIVersion: "v1", Kind: "Service", Name: service.Name, UID: service.UID}, nil
+}
+
+// generate creates a secret with random values matching the selector, and returns it as search result.
+// When another node created the secret first, its secret is returned.
+func (k *K8sSearchBackend) generate(ctx context.Context, selector labels.Selector) ([]searchResult, error) {
+	namespace, err := k.namespace()
+	if err != nil {
+		return nil, err
+	}
+
+	set, err := generatedSecretLabels(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := k.generatedSecretOwner(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(map[string][]byte, len(k.generateSpec.Keys))
+	for i := range k.generateSpec.Keys {
+		value, err := generateValue(&k.generateSpec.Keys[i])
+		if err != nil {
+			return nil, err
+		}
+		data[k.generateSpec.Keys[i].Name] = []byte(value)
+	}
+
+	secretLabels := labels.Merge(set, labels.Set{constants.LabelKubernetesManagedBy: "secret-operator"})
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      generatedSecretName(k.volumeContext.Class, set),
+			Namespace: namespace,
+			Labels:    secretLabels,
+			Annotations: map[string]string{
+				constants.AnnotationSecretsClass: k.volumeContext.Class,
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
+	}
+	if owner != nil {
+		secret.OwnerReferences = []metav1.OwnerReference{*owner}
+	}
+
+	logger.V(1).Info("generate secret", "name", secret.Name, "namespace", namespace, "labels", set)
+	if err := k.client.Create(ctx, secret); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return nil, err
+		}
+		// another node generated the secret first, the cache may not have it yet, the mount is retried
+		if err := k.client.Get(ctx, client.ObjectKeyFromObject(secret), secret); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("secret %s/%s is being generated by another node, retry later", namespace, secret.Name)
+			}
+			return nil, err
+		}
+		if !selector.Matches(labels.Set(secret.Labels)) {
+			return nil, fmt.Errorf("secret %s/%s exists and does not match selector %s", namespace, secret.Name, selector)
+		}
+	}
+
+	keys := make([]string, 0, len(secret.Data))
+	for key := range secret.Data {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	logger.Info("generated secret for volume", "name", secret.Name, "namespace", namespace, "keys", keys)
+
+	return []searchResult{{ObjectMeta: secret.ObjectMeta, kind: K8sSearchKindSecret, data: secret.Data}}, nil
+}
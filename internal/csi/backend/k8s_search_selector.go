@@ -0,0 +1,214 @@
+package backend
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zncdatadev/operator-go/pkg/constants"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+
+	secretsv1alpha1 "github.com/zncdatadev/secret-operator/api/v1alpha1"
+)
+
+const (
+	DefaultListenerLabelPrefix = "secrets.stackable.tech/listener."
+
+	podLabelsVariablePrefix = "podLabels."
+)
+
+// scopeLabelKeys are the label keys matched against the secret class and the scope of a volume,
+// an empty key is not matched.
+type scopeLabelKeys struct {
+	class          string
+	pod            string
+	service        string
+	node           string
+	listenerPrefix string
+}
+
+var defaultScopeLabelKeys = scopeLabelKeys{
+	class:          constants.AnnotationSecretsClass,
+	pod:            constants.LabelSecretsPod,
+	service:        constants.LabelSecretsService,
+	node:           constants.LabelSecretsNode,
+	listenerPrefix: DefaultListenerLabelPrefix,
+}
+
+func newScopeLabelKeys(spec *secretsv1alpha1.ScopeLabelsSpec) scopeLabelKeys {
+	keys := defaultScopeLabelKeys
+	if spec == nil {
+		return keys
+	}
+
+	for _, override := range []struct {
+		key   *string
+		value *string
+	}{
+		{&keys.class, spec.Class},
+		{&keys.pod, spec.Pod},
+		{&keys.service, spec.Service},
+		{&keys.node, spec.Node},
+		{&keys.listenerPrefix, spec.ListenerPrefix},
+	} {
+		if override.value != nil {
+			*override.key = *override.value
+		}
+	}
+	return keys
+}
+
+// isScopeLabel reports whether the label key is one of the scope label keys, the class is not a scope.
+func (s scopeLabelKeys) isScopeLabel(key string) bool {
+	switch {
+	case key == "":
+		return false
+	case key == s.pod, key == s.service, key == s.node:
+		return true
+	}
+	return s.listenerPrefix != "" && strings.HasPrefix(key, s.listenerPrefix)
+}
+
+// labelSelectorValues are the values a label selector template is evaluated against.
+type labelSelectorValues struct {
+	Class          string
+	Namespace      string
+	Pod            string
+	ServiceAccount string
+	Node           string
+	PodLabels      map[string]string
+
+	// Services and Listeners are lists, a template is expanded once for each of them.
+	Services  []string
+	Listeners []string
+}
+
+func (v *labelSelectorValues) lookup(name string) (string, bool) {
+	if key, ok := strings.CutPrefix(name, podLabelsVariablePrefix); ok {
+		value, ok := v.PodLabels[key]
+		return value, ok
+	}
+
+	switch name {
+	case "class":
+		return v.Class, true
+	case "namespace":
+		return v.Namespace, true
+	case "pod":
+		return v.Pod, true
+	case "serviceAccount":
+		return v.ServiceAccount, true
+	case "node":
+		return v.Node, true
+	}
+	return "", false
+}
+
+// expand evaluates a label value template, and returns a value for every service and listener it uses.
+func (v *labelSelectorValues) expand(template string) ([]string, error) {
+	var usesService, usesListener bool
+	var expandErr error
+	os.Expand(template, func(name string) string {
+		switch name {
+		case "service":
+			usesService = true
+		case "listener":
+			usesListener = true
+		default:
+			value, ok := v.lookup(name)
+			if !ok && expandErr == nil {
+				expandErr = fmt.Errorf("unknown variable %q in label selector value %q", name, template)
+			} else if value == "" && expandErr == nil {
+				expandErr = fmt.Errorf("variable %q in label selector value %q is empty", name, template)
+			}
+		}
+		return ""
+	})
+	if expandErr != nil {
+		return nil, expandErr
+	}
+
+	services := []string{""}
+	if usesService {
+		services = v.Services
+	}
+	listeners := []string{""}
+	if usesListener {
+		listeners = v.Listeners
+	}
+
+	values := make([]string, 0, len(services)*len(listeners))
+	for _, service := range services {
+		for _, listener := range listeners {
+			values = append(values, os.Expand(template, func(name string) string {
+				switch name {
+				case "service":
+					return service
+				case "listener":
+					return listener
+				}
+				value, _ := v.lookup(name)
+				return value
+			}))
+		}
+	}
+	return values, nil
+}
+
+// labelSelectorRequirements evaluates the templates of the label selector, and returns its requirements.
+// A matchLabels value must expand to exactly one value.
+func labelSelectorRequirements(selector *metav1.LabelSelector, values *labelSelectorValues) ([]labels.Requirement, error) {
+	if selector == nil {
+		return nil, nil
+	}
+
+	requirements := make([]labels.Requirement, 0, len(selector.MatchLabels)+len(selector.MatchExpressions))
+	for key, template := range selector.MatchLabels {
+		expanded, err := values.expand(template)
+		if err != nil {
+			return nil, err
+		}
+		if len(expanded) != 1 {
+			return nil, fmt.Errorf("label selector value %q of %q expands to %d values, expected one", template, key, len(expanded))
+		}
+		requirement, err := labels.NewRequirement(key, selection.Equals, expanded)
+		if err != nil {
+			return nil, err
+		}
+		requirements = append(requirements, *requirement)
+	}
+
+	for _, expression := range selector.MatchExpressions {
+		expandedValues := make([]string, 0, len(expression.Values))
+		for _, template := range expression.Values {
+			expanded, err := values.expand(template)
+			if err != nil {
+				return nil, err
+			}
+			expandedValues = append(expandedValues, expanded...)
+		}
+
+		var op selection.Operator
+		switch expression.Operator {
+		case metav1.LabelSelectorOpIn:
+			op = selection.In
+		case metav1.LabelSelectorOpNotIn:
+			op = selection.NotIn
+		case metav1.LabelSelectorOpExists:
+			op = selection.Exists
+		case metav1.LabelSelectorOpDoesNotExist:
+			op = selection.DoesNotExist
+		default:
+			return nil, fmt.Errorf("invalid label selector operator %q", expression.Operator)
+		}
+
+		requirement, err := labels.NewRequirement(expression.Key, op, expandedValues)
+		if err != nil {
+			return nil, err
+		}
+		requirements = append(requirements, *requirement)
+	}
+	return requirements, nil
+}
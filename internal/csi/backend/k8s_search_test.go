@@ -0,0 +1,217 @@
+package backend
+
+import (
+	"context"
+	"maps"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/zncdatadev/operator-go/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	secretsv1alpha1 "github.com/zncdatadev/secret-operator/api/v1alpha1"
+	"github.com/zncdatadev/secret-operator/pkg/pod_info"
+	"github.com/zncdatadev/secret-operator/pkg/volume"
+)
+
+func newTestSearchResult(kind, name string, labels, annotations map[string]string, data map[string]string) searchResult {
+	result := searchResult{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels, Annotations: annotations},
+		kind:       kind,
+		data:       make(map[string][]byte),
+	}
+	for key, value := range data {
+		result.data[key] = []byte(value)
+	}
+	return result
+}
+
+func TestMergeSearchResults(t *testing.T) {
+	class := map[string]string{constants.AnnotationSecretsClass: "tls"}
+	node := map[string]string{constants.AnnotationSecretsClass: "tls", constants.LabelSecretsNode: "node1"}
+
+	results := []searchResult{
+		newTestSearchResult("Secret", "shared-b", class, nil, map[string]string{"ca.crt": "b", "tls.crt": "b", "extra": "b"}),
+		newTestSearchResult("Secret", "shared-a", class, nil, map[string]string{"ca.crt": "a", "tls.crt": "a"}),
+		newTestSearchResult("Secret", "shared-c", class, map[string]string{AnnotationSecretsPriority: "10"}, map[string]string{"ca.crt": "c"}),
+		newTestSearchResult("Secret", "node1", node, nil, map[string]string{"tls.crt": "node1"}),
+		newTestSearchResult("ConfigMap", "shared-a", class, nil, map[string]string{"jaas.conf": "a", "tls.crt": "configmap"}),
+	}
+
+	data, sources, err := mergeSearchResults(results, defaultScopeLabelKeys)
+	if err != nil {
+		t.Fatalf("mergeSearchResults() error = %v", err)
+	}
+
+	want := map[string]string{
+		"tls.crt":   "Secret/node1",
+		"ca.crt":    "Secret/shared-c",
+		"extra":     "Secret/shared-b",
+		"jaas.conf": "ConfigMap/shared-a",
+	}
+	if !maps.Equal(sources, want) {
+		t.Errorf("mergeSearchResults() sources = %v, want %v", sources, want)
+	}
+	if got := string(data["tls.crt"]); got != "node1" {
+		t.Errorf("mergeSearchResults() tls.crt = %q, want %q", got, "node1")
+	}
+
+	// the same result regardless of the list order
+	reversed := []searchResult{results[4], results[3], results[2], results[1], results[0]}
+	if _, got, _ := mergeSearchResults(reversed, defaultScopeLabelKeys); !maps.Equal(got, want) {
+		t.Errorf("mergeSearchResults() of reversed list sources = %v, want %v", got, want)
+	}
+
+	invalid := []searchResult{newTestSearchResult("Secret", "invalid", class, map[string]string{AnnotationSecretsPriority: "high"}, nil)}
+	if _, _, err := mergeSearchResults(invalid, defaultScopeLabelKeys); err == nil {
+		t.Error("mergeSearchResults() expected error for invalid priority annotation")
+	}
+}
+
+func TestLabelSelectorRequirements(t *testing.T) {
+	values := &labelSelectorValues{
+		Class:     "tls",
+		Namespace: "default",
+		Pod:       "web-0",
+		Node:      "node1",
+		PodLabels: map[string]string{"app.kubernetes.io/instance": "web"},
+		Services:  []string{"web", "web-headless"},
+	}
+
+	selector := &metav1.LabelSelector{
+		MatchLabels: map[string]string{"app.kubernetes.io/instance": "${podLabels.app.kubernetes.io/instance}"},
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "example.com/service", Operator: metav1.LabelSelectorOpIn, Values: []string{"${service}", "shared"}},
+			{Key: "example.com/revoked", Operator: metav1.LabelSelectorOpDoesNotExist},
+		},
+	}
+
+	requirements, err := labelSelectorRequirements(selector, values)
+	if err != nil {
+		t.Fatalf("labelSelectorRequirements() error = %v", err)
+	}
+	got := labels.NewSelector().Add(requirements...).String()
+	want := "app.kubernetes.io/instance=web,!example.com/revoked,example.com/service in (shared,web,web-headless)"
+	if got != want {
+		t.Errorf("labelSelectorRequirements() = %q, want %q", got, want)
+	}
+
+	for name, selector := range map[string]*metav1.LabelSelector{
+		"unknown variable":     {MatchLabels: map[string]string{"a": "${foo}"}},
+		"missing pod label":    {MatchLabels: map[string]string{"a": "${podLabels.missing}"}},
+		"multiple match label": {MatchLabels: map[string]string{"a": "${service}"}},
+		"invalid value":        {MatchLabels: map[string]string{"a": "${pod} x"}},
+	} {
+		if _, err := labelSelectorRequirements(selector, values); err == nil {
+			t.Errorf("labelSelectorRequirements() expected error for %s", name)
+		}
+	}
+}
+
+func TestNewScopeLabelKeys(t *testing.T) {
+	disabled := ""
+	custom := "example.com/host"
+	keys := newScopeLabelKeys(&secretsv1alpha1.ScopeLabelsSpec{Class: &disabled, Node: &custom})
+
+	if keys.class != "" || keys.node != custom || keys.pod != defaultScopeLabelKeys.pod {
+		t.Errorf("newScopeLabelKeys() = %+v", keys)
+	}
+	if !keys.isScopeLabel(custom) || keys.isScopeLabel(constants.LabelSecretsNode) || !keys.isScopeLabel(DefaultListenerLabelPrefix+"1") {
+		t.Errorf("isScopeLabel() does not match the configured keys %+v", keys)
+	}
+}
+
+func TestGenerateValue(t *testing.T) {
+	value, err := generateValue(&secretsv1alpha1.GeneratedKeySpec{Name: "password", Length: 64, Charset: GeneratedCharsetHex})
+	if err != nil {
+		t.Fatalf("generateValue() error = %v", err)
+	}
+	if len(value) != 64 || strings.Trim(value, "0123456789abcdef") != "" {
+		t.Errorf("generateValue() = %q, want 64 hex characters", value)
+	}
+
+	value, err = generateValue(&secretsv1alpha1.GeneratedKeySpec{Name: "pin", Characters: "äö"})
+	if err != nil {
+		t.Fatalf("generateValue() error = %v", err)
+	}
+	if utf8.RuneCountInString(value) != DefaultGeneratedLength || strings.Trim(value, "äö") != "" {
+		t.Errorf("generateValue() = %q, want %d characters of the custom characters", value, DefaultGeneratedLength)
+	}
+}
+
+func TestGeneratedSecretLabels(t *testing.T) {
+	selector := labels.SelectorFromSet(labels.Set{constants.AnnotationSecretsClass: "redis", constants.LabelSecretsPod: "redis-0"})
+	revoked, _ := labels.NewRequirement("example.com/revoked", selection.DoesNotExist, nil)
+
+	set, err := generatedSecretLabels(selector.Add(*revoked))
+	if err != nil {
+		t.Fatalf("generatedSecretLabels() error = %v", err)
+	}
+	want := labels.Set{constants.AnnotationSecretsClass: "redis", constants.LabelSecretsPod: "redis-0"}
+	if !maps.Equal(set, want) {
+		t.Errorf("generatedSecretLabels() = %v, want %v", set, want)
+	}
+
+	exists, _ := labels.NewRequirement("example.com/team", selection.Exists, nil)
+	if _, err := generatedSecretLabels(selector.Add(*exists)); err == nil {
+		t.Error("generatedSecretLabels() expected error for a selector not satisfied by equality labels")
+	}
+
+	services := labels.SelectorFromSet(labels.Set{constants.LabelSecretsService: "redis,redis-headless"})
+	if _, err := generatedSecretLabels(services); err == nil {
+		t.Error("generatedSecretLabels() expected error for an invalid label value")
+	}
+}
+
+func TestGeneratedSecretOwner(t *testing.T) {
+	ctx := context.Background()
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "redis-0", Namespace: "default", UID: "pod-uid"}}
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "redis", Namespace: "default", UID: "service-uid"}}
+	c := fake.NewClientBuilder().WithObjects(service).Build()
+
+	newBackend := func(scope volume.SecretScope) *K8sSearchBackend {
+		return &K8sSearchBackend{
+			client:        c,
+			podInfo:       pod_info.NewPodInfo(c, pod, &scope),
+			volumeContext: &volume.SecretVolumeContext{Class: "redis", Scope: scope},
+			scopeLabels:   defaultScopeLabelKeys,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		scope     volume.SecretScope
+		namespace string
+		wantKind  string
+		wantUID   string
+		wantErr   bool
+	}{
+		{name: "pod", scope: volume.SecretScope{Pod: volume.ScopePod}, namespace: "default", wantKind: "Pod", wantUID: "pod-uid"},
+		{name: "service", scope: volume.SecretScope{Services: []string{"redis"}}, namespace: "default", wantKind: "Service", wantUID: "service-uid"},
+		{name: "node", scope: volume.SecretScope{Node: volume.ScopeNode}, namespace: "default"},
+		{name: "multiple services", scope: volume.SecretScope{Services: []string{"redis", "redis-headless"}}, namespace: "default", wantErr: true},
+		{name: "other namespace", scope: volume.SecretScope{Pod: volume.ScopePod}, namespace: "shared", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner, err := newBackend(tt.scope).generatedSecretOwner(ctx, tt.namespace)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("generatedSecretOwner() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantKind == "" {
+				if owner != nil {
+					t.Errorf("generatedSecretOwner() = %v, want no owner", owner)
+				}
+				return
+			}
+			if owner == nil || owner.Kind != tt.wantKind || string(owner.UID) != tt.wantUID {
+				t.Errorf("generatedSecretOwner() = %v, want %s %s", owner, tt.wantKind, tt.wantUID)
+			}
+		})
+	}
+}
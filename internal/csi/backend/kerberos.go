@@ -2,12 +2,17 @@ package backend
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
+	"slices"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	secretsv1alpha1 "github.com/zncdatadev/secret-operator/api/v1alpha1"
+	"github.com/zncdatadev/secret-operator/internal/metrics"
 	"github.com/zncdatadev/secret-operator/pkg/kerberos"
 	"github.com/zncdatadev/secret-operator/pkg/pod_info"
 	"github.com/zncdatadev/secret-operator/pkg/util"
@@ -21,23 +26,185 @@ type KerberosBackend struct {
 	podInfo       *pod_info.PodInfo
 	volumeContext *volume.SecretVolumeContext
 	spec          *secretsv1alpha1.KerberosKeytabSpec
+	class         string
+	// enctypes are the encryption types of delivered keys, all encryption types if empty
+	enctypes []int32
+
+	// cache is nil when keytab cache is not enabled in the secret class
+	cache *keytabCache
+	// registry is nil when principal gc is not enabled in the secret class
+	registry *principalRegistry
+	// rotation is nil when key rotation is not enabled in the secret class
+	rotation *keyRotation
 }
 
 func NewKerberosBackend(config *BackendConfig) (IBackend, error) {
+	spec := config.SecretClass.Spec.Backend.KerberosKeytab
+	if spec.Admin == nil {
+		return nil, errors.New("admin is nil in kerberos backend")
+	}
+
+	enctypes, err := parseEncryptionTypes(spec.EncryptionTypes)
+	if err != nil {
+		return nil, err
+	}
+	// heimdal kadmin creates keys of the default encryption types of the kadmin server, filtering the
+	// delivered keys would still leave keys of the other encryption types in the KDC
+	if len(enctypes) > 0 && spec.Admin.Heimdal != nil {
+		return nil, errors.New("encryptionTypes is not supported with a heimdal admin server, " +
+			"set the default_keys of the heimdal kadmin server instead")
+	}
+
+	var cache *keytabCache
+	if spec.KeytabCache != nil {
+		if cache, err = newKeytabCache(config.Client, config.SecretClass.Name, spec); err != nil {
+			return nil, err
+		}
+	}
+
+	var rotation *keyRotation
+	if spec.KeyRotation != nil {
+		if rotation, err = newKeyRotation(config.Client, config.SecretClass.Name, spec); err != nil {
+			return nil, err
+		}
+	}
+
+	var registry *principalRegistry
+	if spec.PrincipalGC != nil {
+		registry = newPrincipalRegistry(config.Client, config.SecretClass.Name, spec)
+	}
+
 	return &KerberosBackend{
 		client:        config.Client,
 		podInfo:       config.PodInfo,
 		volumeContext: config.VolumeContext,
-		spec:          config.SecretClass.Spec.Backend.KerberosKeytab,
+		spec:          spec,
+		class:         config.SecretClass.Name,
+		enctypes:      enctypes,
+		cache:         cache,
+		registry:      registry,
+		rotation:      rotation,
 	}, nil
 }
 
+func parseEncryptionTypes(names []string) ([]int32, error) {
+	enctypes := make([]int32, 0, len(names))
+	for _, name := range names {
+		enctype, err := kerberos.ParseEncryptionType(name)
+		if err != nil {
+			return nil, err
+		}
+		enctypes = append(enctypes, enctype)
+	}
+	return enctypes, nil
+}
+
+// encryptionTypeNames returns the canonical names of the encryption types, as understood by kadmin and krb5.conf.
+func (k *KerberosBackend) encryptionTypeNames() []string {
+	names := make([]string, 0, len(k.enctypes))
+	for _, enctype := range k.enctypes {
+		names = append(names, kerberos.EncryptionTypeName(enctype))
+	}
+	return names
+}
+
+func (k *KerberosBackend) getAdminServer() string {
+	if k.spec.Admin.Heimdal != nil {
+		return k.spec.Admin.Heimdal.KadminServer
+	}
+	if k.spec.Admin.MIT != nil {
+		return k.spec.Admin.MIT.KadminServer
+	}
+	return ""
+}
+
 func (k *KerberosBackend) getKrb5Config() *kerberos.Krb5Config {
-	return &kerberos.Krb5Config{
-		Realm:       k.spec.RealmName,
-		AdminServer: k.spec.Admin.MIT.KadminServer,
-		KDC:         k.spec.KDC,
+	config := &kerberos.Krb5Config{
+		Realm:          k.spec.RealmName,
+		AdminServer:    k.getAdminServer(),
+		KDC:            k.spec.KDC,
+		AdditionalKDCs: k.spec.AdditionalKDCs,
+		ClusterDomain:  util.GetClusterDomain(),
 	}
+	if len(k.enctypes) > 0 {
+		config.PermittedEnctypes = k.encryptionTypeNames()
+	}
+
+	krb5Conf := k.spec.Krb5Conf
+	if krb5Conf == nil {
+		return config
+	}
+
+	config.MasterKDC = krb5Conf.MasterKDC
+	config.KpasswdServer = krb5Conf.KpasswdServer
+	if len(krb5Conf.PermittedEncryptionTypes) > 0 {
+		config.PermittedEnctypes = krb5Conf.PermittedEncryptionTypes
+	}
+	config.TicketLifetime = krb5Conf.TicketLifetime
+	config.RenewLifetime = krb5Conf.RenewLifetime
+	config.DNSLookupKDC = krb5Conf.DNSLookupKDC
+	config.UDPPreferenceLimit = krb5Conf.UDPPreferenceLimit
+
+	for _, realm := range krb5Conf.AdditionalRealms {
+		config.Realms = append(config.Realms, kerberos.Realm{
+			Name:        realm.Name,
+			KDCs:        realm.KDCs,
+			AdminServer: realm.AdminServer,
+		})
+	}
+	for _, domainRealm := range krb5Conf.DomainRealms {
+		config.DomainRealms = append(config.DomainRealms, kerberos.DomainRealm{
+			Domain: domainRealm.Domain,
+			Realm:  domainRealm.Realm,
+		})
+	}
+
+	return config
+}
+
+func (k *KerberosBackend) getPrincipalAttributes() (*kerberos.PrincipalAttributes, error) {
+	spec := k.spec.PrincipalAttributes
+	if spec == nil {
+		return nil, nil
+	}
+
+	attributes := &kerberos.PrincipalAttributes{Policy: spec.Policy, Flags: spec.Flags}
+	if spec.MaxLife != "" {
+		d, err := time.ParseDuration(spec.MaxLife)
+		if err != nil {
+			return nil, fmt.Errorf("invalid principal max life: %w", err)
+		}
+		attributes.MaxLife = d
+	}
+	if spec.MaxRenewLife != "" {
+		d, err := time.ParseDuration(spec.MaxRenewLife)
+		if err != nil {
+			return nil, fmt.Errorf("invalid principal max renew life: %w", err)
+		}
+		attributes.MaxRenewLife = d
+	}
+	return attributes, nil
+}
+
+// newAdminClient creates the kadmin client matching the admin server type in the secret class
+func (k *KerberosBackend) newAdminClient(adminKeytab []byte) (kerberos.AdminClient, error) {
+	attributes, err := k.getPrincipalAttributes()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case k.spec.Admin.MIT != nil:
+		kadmin := kerberos.NewKadmin(k.getKrb5Config(), &k.spec.AdminPrincipal, adminKeytab)
+		kadmin.SetPrincipalAttributes(attributes)
+		kadmin.SetEncryptionTypes(k.encryptionTypeNames())
+		return kadmin, nil
+	case k.spec.Admin.Heimdal != nil:
+		kadmin := kerberos.NewHeimdalKadmin(k.getKrb5Config(), &k.spec.AdminPrincipal, adminKeytab)
+		kadmin.SetPrincipalAttributes(attributes)
+		return kadmin, nil
+	}
+	return nil, errors.New("no admin server specified in kerberos backend, one of mit or heimdal is required")
 }
 
 func (k *KerberosBackend) GetQualifiedNodeNames(ctx context.Context) ([]string, error) {
@@ -47,41 +214,192 @@ func (k *KerberosBackend) GetQualifiedNodeNames(ctx context.Context) ([]string,
 
 // GetSecretData implements Backend.
 func (k *KerberosBackend) GetSecretData(ctx context.Context) (*util.SecretContent, error) {
-	keytab, err := k.provisionKeytab(ctx)
+	keytab, expiresTime, err := k.provisionKeytab(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	krb5Config := k.getKrb5Config().Content()
 
-	return &util.SecretContent{Data: map[string]string{"keytab": string(keytab), "krb5.conf": krb5Config}}, nil
+	return &util.SecretContent{
+		Data:        map[string]string{"keytab": string(keytab), "krb5.conf": krb5Config},
+		ExpiresTime: expiresTime,
+	}, nil
 }
 
-func (k *KerberosBackend) provisionKeytab(ctx context.Context) ([]byte, error) {
+// provisionKeytab returns a keytab containing the keys of all principals of the volume.
+// When the keytab cache is enabled, cached keys are trusted until they are older than the cache max age,
+// kadmin is only contacted for principals which are not cached yet or whose cached keys are expired.
+// When key rotation is enabled, it returns the time the keytab must be replaced, otherwise nil.
+func (k *KerberosBackend) provisionKeytab(ctx context.Context) ([]byte, *time.Time, error) {
+	principals, err := k.getPrincipals(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// add the volume to the owners of tracked principals before using their keys, so the garbage collector
+	// does not delete them meanwhile. Principals being deleted fail the volume until they are deleted.
+	if k.registry != nil {
+		if err := k.trackPrincipals(ctx, principals, nil); err != nil {
+			logger.Error(err, "failed to track principals", "principals", principals)
+			return nil, nil, err
+		}
+	}
+
+	cached := &kerberos.Keytab{}
+	missing := principals
+	if k.cache != nil {
+		if cached, missing, err = k.cache.Get(ctx, principals); err != nil {
+			logger.Error(err, "failed to get keys from keytab cache", "principals", principals)
+			return nil, nil, err
+		}
+	}
+
+	extracted := &kerberos.Keytab{}
+	if len(missing) > 0 {
+		var created []string
+		if extracted, created, err = k.extractKeytab(ctx, missing); err != nil {
+			return nil, nil, err
+		}
+
+		// only principals created by the operator are tracked, existing principals are never garbage collected
+		if k.registry != nil && len(created) > 0 {
+			if err := k.trackPrincipals(ctx, created, created); err != nil {
+				logger.Error(err, "failed to track created principals", "principals", created)
+				return nil, nil, err
+			}
+		}
+
+		if k.cache != nil {
+			if err := k.cache.Put(ctx, extracted); err != nil {
+				// the keytab is still valid, the keys will be extracted again next time
+				logger.Error(err, "failed to save keys to keytab cache", "principals", missing)
+			}
+		}
+	}
+
+	keytab := kerberos.MergeKeytabs(cached, extracted)
+
+	var expiresTime *time.Time
+	if k.rotation != nil {
+		keytab, expiresTime, err = k.rotation.Rotate(ctx, principals, keytab, func(due []string) (*kerberos.Keytab, error) {
+			return k.rekeyKeytab(ctx, due)
+		})
+		if err != nil {
+			logger.Error(err, "failed to rotate keys", "principals", principals)
+			return nil, nil, err
+		}
+	}
+
+	// kadmin extracts existing keys of all encryption types, e.g. of principals created before the
+	// encryption types were restricted
+	if len(k.enctypes) > 0 {
+		keytab = keytab.FilterByEncryptionType(k.enctypes...)
+	}
+
+	if err := keytab.Verify(principals...); err != nil {
+		if len(k.enctypes) > 0 {
+			return nil, nil, fmt.Errorf("no keys of encryption types %v: %w", k.encryptionTypeNames(), err)
+		}
+		return nil, nil, err
+	}
+
+	data, err := keytab.Marshal()
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, expiresTime, nil
+}
+
+// trackPrincipals records the pod and the scoped services as users of the principals,
+// records are only added for the created principals.
+func (k *KerberosBackend) trackPrincipals(ctx context.Context, principals []string, created []string) error {
+	pod := k.podInfo.Pod
+	services := make([]PrincipalOwner, 0, len(k.podInfo.Scope.Services))
+	for _, name := range k.podInfo.Scope.Services {
+		services = append(services, PrincipalOwner{Namespace: pod.Namespace, Name: name})
+	}
+
+	return k.registry.Track(ctx, principals, created, PrincipalOwner{Namespace: pod.Namespace, Name: pod.Name, UID: pod.UID}, services)
+}
+
+// extractKeytab creates the principals if they do not exist, and extracts their keys in one kadmin session.
+// It returns the keys and the principals created by the session.
+func (k *KerberosBackend) extractKeytab(ctx context.Context, principals []string) (*kerberos.Keytab, []string, error) {
+	adminKeytab, err := k.getAdminKeytab(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	kadmin, err := k.newAdminClient(adminKeytab)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, created, err := kadmin.ProvisionKeytab(principals...)
+	metrics.ObserveKadmin(k.class, metrics.KadminOperationProvision, err)
+	if err != nil {
+		logger.Error(err, "failed to provision keytab", "principals", principals)
+		return nil, nil, err
+	}
+
+	keytab, err := k.verifyKeytab(data, principals)
+	if err != nil {
+		logger.Error(err, "failed to verify keytab", "principals", principals)
+		return nil, nil, err
+	}
+
+	return keytab, created, nil
+}
+
+// rekeyKeytab replaces the keys of the principals with new random keys and returns them.
+// The keytab cache is updated, so other volumes do not get the replaced keys from the cache.
+func (k *KerberosBackend) rekeyKeytab(ctx context.Context, principals []string) (*kerberos.Keytab, error) {
 	adminKeytab, err := k.getAdminKeytab(ctx)
 	if err != nil {
 		return nil, err
 	}
-	kadmin := kerberos.NewKadmin(k.getKrb5Config(), &k.spec.AdminPrincipal, adminKeytab)
+	kadmin, err := k.newAdminClient(adminKeytab)
+	if err != nil {
+		return nil, err
+	}
 
-	principals, err := k.getPrincipals(ctx)
+	data, err := kadmin.RekeyPrincipals(principals...)
+	metrics.ObserveKadmin(k.class, metrics.KadminOperationRekey, err)
 	if err != nil {
+		logger.Error(err, "failed to rekey principals", "principals", principals)
 		return nil, err
 	}
 
-	for _, principal := range principals {
-		if err := kadmin.AddPrincipal(principal); err != nil {
-			logger.Error(err, "failed to add principal", "principal", principal, "kdc", k.spec.KDC)
-			return nil, err
+	keytab, err := k.verifyKeytab(data, principals)
+	if err != nil {
+		logger.Error(err, "failed to verify keytab", "principals", principals)
+		return nil, err
+	}
+
+	if k.cache != nil {
+		if err := k.cache.Put(ctx, keytab); err != nil {
+			logger.Error(err, "failed to save keys to keytab cache", "principals", principals)
 		}
 	}
+	return keytab, nil
+}
 
-	keytab, err := kadmin.Ktadd(principals...)
+// verifyKeytab checks the keytab extracted by kadmin contains keys of all requested principals,
+// so an incomplete keytab is never mounted to the pod.
+func (k *KerberosBackend) verifyKeytab(data []byte, principals []string) (*kerberos.Keytab, error) {
+	keytab, err := kerberos.ParseKeytab(data)
 	if err != nil {
-		logger.Error(err, "failed to provision keytab", "principals", principals)
+		return nil, fmt.Errorf("failed to parse keytab: %w", err)
+	}
+
+	if err := keytab.Verify(principals...); err != nil {
 		return nil, err
 	}
 
+	for _, entry := range keytab.Entries {
+		logger.V(1).Info("keytab entry", "principal", entry.Principal.String(), "kvno", entry.KVNO,
+			"enctype", kerberos.EncryptionTypeName(entry.EncType))
+	}
 	return keytab, nil
 }
 
@@ -101,32 +419,104 @@ func (k *KerberosBackend) getAdminKeytab(ctx context.Context) ([]byte, error) {
 	return data, nil
 }
 
-func (k *KerberosBackend) getPrincipals(ctx context.Context) ([]string, error) {
+// getPrincipalTemplates returns the principal templates of the volume if the secret class allows it,
+// otherwise the templates of the secret class.
+func (k *KerberosBackend) getPrincipalTemplates() ([]string, error) {
+	spec := k.spec.Principals
+
+	if len(k.volumeContext.KerberosPrincipals) > 0 {
+		if spec == nil || !spec.AllowVolumeOverride {
+			return nil, fmt.Errorf("secret class %s does not allow volumes to override kerberos principals", k.volumeContext.Class)
+		}
+		return k.volumeContext.KerberosPrincipals, nil
+	}
+
+	if spec != nil && len(spec.Templates) > 0 {
+		return spec.Templates, nil
+	}
+	return []string{kerberos.DefaultPrincipalTemplate}, nil
+}
+
+// getHostnames returns the hostnames of the scoped addresses, without the excluded hostnames.
+func (k *KerberosBackend) getHostnames(ctx context.Context) ([]string, error) {
 	scopedAddresses, err := k.podInfo.GetScopedAddresses(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	svcNames := k.volumeContext.KerberosServiceNames
+	var excludeIPHostnames bool
+	var excludes []*regexp.Regexp
+	if spec := k.spec.Principals; spec != nil {
+		excludeIPHostnames = spec.ExcludeIPHostnames
+		for _, pattern := range spec.ExcludeHostnames {
+			re, err := regexp.Compile("^(?:" + pattern + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid excluded hostname pattern %q: %w", pattern, err)
+			}
+			excludes = append(excludes, re)
+		}
+	}
 
-	principals := make([]string, 0)
+	hostnames := make([]string, 0, len(scopedAddresses))
+	for _, addr := range scopedAddresses {
+		hostname := addr.Hostname
+		// only support FQDN
+		if hostname == "" || slices.Contains(hostnames, hostname) {
+			continue
+		}
+		if excludeIPHostnames && kerberos.IsIPHostname(hostname) {
+			logger.V(1).Info("exclude ip hostname", "hostname", hostname)
+			continue
+		}
+		if slices.ContainsFunc(excludes, func(re *regexp.Regexp) bool { return re.MatchString(hostname) }) {
+			logger.V(1).Info("exclude hostname", "hostname", hostname)
+			continue
+		}
+		hostnames = append(hostnames, hostname)
+	}
+	return hostnames, nil
+}
+
+func (k *KerberosBackend) getPrincipals(ctx context.Context) ([]string, error) {
+	templates, err := k.getPrincipalTemplates()
+	if err != nil {
+		return nil, err
+	}
 
-	for _, svcName := range svcNames {
-		for _, addr := range scopedAddresses {
-			hostname := addr.Hostname
-			// only support FQDN
-			if hostname != "" {
-				principal := svcName + "/" + hostname + "@" + k.spec.RealmName
+	hostnames, err := k.getHostnames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pod := k.podInfo.Pod
+	values := &kerberos.PrincipalValues{
+		Realm:          k.spec.RealmName,
+		Namespace:      pod.Namespace,
+		Pod:            pod.Name,
+		ServiceAccount: pod.Spec.ServiceAccountName,
+		Node:           pod.Spec.NodeName,
+		ServiceNames:   k.volumeContext.KerberosServiceNames,
+		Hostnames:      hostnames,
+	}
+
+	principals := make([]string, 0)
+	for _, template := range templates {
+		expanded, err := kerberos.ExpandPrincipalTemplate(template, values)
+		if err != nil {
+			return nil, err
+		}
+		for _, principal := range expanded {
+			if !slices.Contains(principals, principal) {
 				principals = append(principals, principal)
-				logger.V(1).Info("add principal", "principal", principal)
+				logger.V(1).Info("add principal", "principal", principal, "template", template)
 			}
 		}
 	}
 
 	if len(principals) == 0 {
-		return nil, fmt.Errorf("no principals found for service names %v", svcNames)
+		return nil, fmt.Errorf("no principals found for templates %v, service names %v and hostnames %v",
+			templates, values.ServiceNames, hostnames)
 	}
 
 	return principals, nil
-
 }
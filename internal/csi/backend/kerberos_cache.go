@@ -0,0 +1,208 @@
+package backend
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+	"time"
+
+	"github.com/zncdatadev/operator-go/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1alpha1 "github.com/zncdatadev/secret-operator/api/v1alpha1"
+	"github.com/zncdatadev/secret-operator/pkg/kerberos"
+)
+
+const (
+	KeytabCacheSecretSuffix  = "-keytab-cache"
+	DefaultKeytabCacheMaxAge = 24 * time.Hour
+)
+
+// keytabCache caches the keys extracted by kadmin in a secret, one keytab per principal.
+// The key of the secret data is the hash of the principal, because principals contain
+// characters which are not allowed in secret keys.
+// The timestamp of cached keytab entries is the time the keys were extracted.
+type keytabCache struct {
+	client      client.Client
+	secretClass string
+	key         client.ObjectKey
+	maxAge      time.Duration
+}
+
+func newKeytabCache(c client.Client, secretClass string, spec *secretsv1alpha1.KerberosKeytabSpec) (*keytabCache, error) {
+	cacheSpec := spec.KeytabCache
+
+	maxAge := DefaultKeytabCacheMaxAge
+	if cacheSpec.MaxAge != "" {
+		d, err := time.ParseDuration(cacheSpec.MaxAge)
+		if err != nil {
+			return nil, err
+		}
+		maxAge = d
+	}
+
+	key := client.ObjectKey{Name: secretClass + KeytabCacheSecretSuffix, Namespace: spec.AdminKeytabSecret.Namespace}
+	if cacheSpec.Secret != nil {
+		key = client.ObjectKey{Name: cacheSpec.Secret.Name, Namespace: cacheSpec.Secret.Namespace}
+	}
+
+	return &keytabCache{client: c, secretClass: secretClass, key: key, maxAge: maxAge}, nil
+}
+
+func keytabCacheKey(principal string) string {
+	sum := sha256.Sum256([]byte(principal))
+	return hex.EncodeToString(sum[:16]) + ".keytab"
+}
+
+// Get returns the cached keys of the principals, and the principals which are not cached or expired.
+func (c *keytabCache) Get(ctx context.Context, principals []string) (*kerberos.Keytab, []string, error) {
+	cached := &kerberos.Keytab{}
+
+	secret := &corev1.Secret{}
+	if err := c.client.Get(ctx, c.key, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.V(1).Info("keytab cache secret not found", "name", c.key.Name, "namespace", c.key.Namespace)
+			return cached, principals, nil
+		}
+		return nil, nil, err
+	}
+
+	missing := make([]string, 0)
+	for _, principal := range principals {
+		data, ok := secret.Data[keytabCacheKey(principal)]
+		if !ok {
+			missing = append(missing, principal)
+			continue
+		}
+
+		keytab, err := kerberos.ParseKeytab(data)
+		if err != nil || keytab.Verify(principal) != nil {
+			logger.Info("invalid cached keytab, extract it again", "principal", principal, "error", err)
+			missing = append(missing, principal)
+			continue
+		}
+
+		if c.expired(keytab) {
+			logger.V(1).Info("cached keytab is expired, extract it again", "principal", principal, "maxAge", c.maxAge)
+			missing = append(missing, principal)
+			continue
+		}
+
+		cached = kerberos.MergeKeytabs(cached, keytab)
+	}
+
+	logger.V(1).Info("got keys from keytab cache", "cached", cached.Principals(), "missing", missing,
+		"name", c.key.Name, "namespace", c.key.Namespace)
+	return cached, missing, nil
+}
+
+func (c *keytabCache) expired(keytab *kerberos.Keytab) bool {
+	deadline := time.Now().Add(-c.maxAge)
+	for _, entry := range keytab.Entries {
+		if entry.Timestamp.Before(deadline) {
+			return true
+		}
+	}
+	return false
+}
+
+// prune removes the expired and invalid keytabs from the cache data, and returns the number of removed keytabs.
+func (c *keytabCache) prune(data map[string][]byte) int {
+	pruned := 0
+	for key, value := range data {
+		keytab, err := kerberos.ParseKeytab(value)
+		if err != nil || c.expired(keytab) {
+			delete(data, key)
+			pruned++
+		}
+	}
+	return pruned
+}
+
+// Put saves the keys of every principal in the keytab to the cache, replacing the cached keys of the principal.
+// Expired keys of other principals are removed.
+func (c *keytabCache) Put(ctx context.Context, keytab *kerberos.Keytab) error {
+	now := time.Now()
+	data := make(map[string][]byte)
+	for _, principal := range keytab.Principals() {
+		entries := &kerberos.Keytab{}
+		for _, entry := range keytab.FilterByPrincipal(principal).Entries {
+			stamped := *entry
+			stamped.Timestamp = now
+			entries.Entries = append(entries.Entries, &stamped)
+		}
+		encoded, err := entries.Marshal()
+		if err != nil {
+			return err
+		}
+		data[keytabCacheKey(principal)] = encoded
+	}
+
+	// the cache secret is shared by all csi nodes, retry when another node modified or created it.
+	return retry.OnError(retry.DefaultRetry, func(err error) bool {
+		return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+	}, func() error {
+		secret := &corev1.Secret{}
+		if err := c.client.Get(ctx, c.key, secret); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      c.key.Name,
+					Namespace: c.key.Namespace,
+					Labels: map[string]string{
+						constants.LabelKubernetesManagedBy: "secret-operator",
+					},
+					Annotations: map[string]string{
+						constants.AnnotationSecretsClass: c.secretClass,
+					},
+				},
+				Type: corev1.SecretTypeOpaque,
+				Data: data,
+			}
+			logger.V(1).Info("create keytab cache secret", "name", c.key.Name, "namespace", c.key.Namespace)
+			return c.client.Create(ctx, secret)
+		}
+
+		if secret.Data == nil {
+			secret.Data = make(map[string][]byte)
+		}
+		// the secret is shared by all volumes of the class, drop expired keys so it does not grow without bound
+		pruned := c.prune(secret.Data)
+		maps.Copy(secret.Data, data)
+		logger.V(1).Info("update keytab cache secret", "name", c.key.Name, "namespace", c.key.Namespace,
+			"principals", keytab.Principals(), "pruned", pruned)
+		return c.client.Update(ctx, secret)
+	})
+}
+
+// Delete removes the cached keys of the principals, e.g. when the principals are deleted from the KDC.
+func (c *keytabCache) Delete(ctx context.Context, principals []string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		secret := &corev1.Secret{}
+		if err := c.client.Get(ctx, c.key, secret); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+
+		changed := false
+		for _, principal := range principals {
+			key := keytabCacheKey(principal)
+			if _, ok := secret.Data[key]; ok {
+				delete(secret.Data, key)
+				changed = true
+			}
+		}
+		if !changed {
+			return nil
+		}
+
+		logger.V(1).Info("delete keys from keytab cache", "name", c.key.Name, "namespace", c.key.Namespace, "principals", principals)
+		return c.client.Update(ctx, secret)
+	})
+}
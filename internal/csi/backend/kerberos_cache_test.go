@@ -0,0 +1,42 @@
+package backend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestKeytabCachePutPrunesExpiredKeys(t *testing.T) {
+	ctx := context.Background()
+	key := client.ObjectKey{Name: "kerberos-keytab-cache", Namespace: "kubedoop"}
+
+	expired, err := newTestKeytab(1, time.Now().Add(-48*time.Hour)).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+		Data: map[string][]byte{
+			keytabCacheKey("expired@EXAMPLE.COM"): expired,
+			keytabCacheKey("invalid@EXAMPLE.COM"): []byte("invalid"),
+		},
+	}).Build()
+	cache := &keytabCache{client: c, secretClass: "kerberos", key: key, maxAge: 24 * time.Hour}
+
+	if err := cache.Put(ctx, newTestKeytab(2, time.Now())); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, key, secret); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := secret.Data[keytabCacheKey(testPrincipal)]; !ok || len(secret.Data) != 1 {
+		t.Errorf("Put() cache keys = %d, want only the keys of %s", len(secret.Data), testPrincipal)
+	}
+}
@@ -0,0 +1,248 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1alpha1 "github.com/zncdatadev/secret-operator/api/v1alpha1"
+	"github.com/zncdatadev/secret-operator/internal/metrics"
+)
+
+const (
+	DefaultPrincipalGCGracePeriod = 24 * time.Hour
+)
+
+// PrincipalGarbageCollector deletes the principals of a kerberos secret class from the KDC
+// once none of the pods and services tracked for them exist for the grace period.
+// Only principals created by the operator are tracked, see principalRegistry.Track.
+//
+// Expired records are marked as deleting in the same configmap update which finds them orphaned,
+// so a volume tracking the principal concurrently either keeps it or fails until it is deleted.
+// Principals are deleted before their records, a principal whose deletion failed stays marked and
+// is retried in the next collection.
+type PrincipalGarbageCollector struct {
+	client      client.Client
+	backend     *KerberosBackend
+	registry    *principalRegistry
+	gracePeriod time.Duration
+	dryRun      bool
+}
+
+func NewPrincipalGarbageCollector(c client.Client, secretClass *secretsv1alpha1.SecretClass) (*PrincipalGarbageCollector, error) {
+	if secretClass.Spec.Backend == nil || secretClass.Spec.Backend.KerberosKeytab == nil {
+		return nil, errors.New("secret class has no kerberos backend")
+	}
+	spec := secretClass.Spec.Backend.KerberosKeytab
+	if spec.PrincipalGC == nil {
+		return nil, errors.New("principal gc is not enabled in kerberos backend")
+	}
+	if spec.Admin == nil {
+		return nil, errors.New("admin is nil in kerberos backend")
+	}
+
+	gracePeriod := DefaultPrincipalGCGracePeriod
+	if spec.PrincipalGC.GracePeriod != "" {
+		d, err := time.ParseDuration(spec.PrincipalGC.GracePeriod)
+		if err != nil {
+			return nil, err
+		}
+		gracePeriod = d
+	}
+
+	backend := &KerberosBackend{client: c, spec: spec, class: secretClass.Name}
+	if spec.KeytabCache != nil {
+		cache, err := newKeytabCache(c, secretClass.Name, spec)
+		if err != nil {
+			return nil, err
+		}
+		backend.cache = cache
+	}
+	if spec.KeyRotation != nil {
+		rotation, err := newKeyRotation(c, secretClass.Name, spec)
+		if err != nil {
+			return nil, err
+		}
+		backend.rotation = rotation
+	}
+
+	return &PrincipalGarbageCollector{
+		client:      c,
+		backend:     backend,
+		registry:    newPrincipalRegistry(c, secretClass.Name, spec),
+		gracePeriod: gracePeriod,
+		dryRun:      spec.PrincipalGC.DryRun,
+	}, nil
+}
+
+// Collect deletes the principals orphaned longer than the grace period, and returns them.
+// In dry run mode, the principals are only reported.
+func (g *PrincipalGarbageCollector) Collect(ctx context.Context) ([]string, error) {
+	records, err := g.registry.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deadPods, deadServices, err := g.findDeadOwners(ctx, records)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var expired []string
+	err = g.registry.update(ctx, func(records map[string]*PrincipalRecord) bool {
+		changed := false
+		expired = expired[:0]
+		for _, record := range records {
+			pods, services := len(record.Pods), len(record.Services)
+			// owners tracked after the dead owners were looked up are kept, they are not in the dead sets
+			record.Pods = slices.DeleteFunc(record.Pods, func(o PrincipalOwner) bool { return deadPods[o] })
+			record.Services = slices.DeleteFunc(record.Services, func(o PrincipalOwner) bool { return deadServices[o] })
+			if len(record.Pods) != pods || len(record.Services) != services {
+				changed = true
+			}
+
+			// a deletion interrupted before the record was removed is retried
+			if record.DeletingAt != nil {
+				expired = append(expired, record.Principal)
+				continue
+			}
+
+			if len(record.Pods) > 0 || len(record.Services) > 0 {
+				if record.OrphanedAt != nil {
+					record.OrphanedAt = nil
+					changed = true
+				}
+				continue
+			}
+
+			if record.OrphanedAt == nil {
+				record.OrphanedAt = &metav1.Time{Time: now}
+				changed = true
+				logger.V(1).Info("principal is orphaned", "principal", record.Principal)
+			}
+			if record.orphanedFor(now) >= g.gracePeriod {
+				expired = append(expired, record.Principal)
+				if !g.dryRun {
+					record.DeletingAt = &metav1.Time{Time: now}
+					changed = true
+				}
+			}
+		}
+		return changed
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(expired) == 0 {
+		return nil, nil
+	}
+	slices.Sort(expired)
+
+	if g.dryRun {
+		logger.Info("dry run, orphaned principals are not deleted", "principals", expired, "gracePeriod", g.gracePeriod)
+		return expired, nil
+	}
+
+	if err := g.deletePrincipals(ctx, expired); err != nil {
+		return nil, err
+	}
+
+	logger.Info("deleted orphaned principals", "principals", expired, "gracePeriod", g.gracePeriod)
+	return expired, nil
+}
+
+func (g *PrincipalGarbageCollector) deletePrincipals(ctx context.Context, principals []string) error {
+	adminKeytab, err := g.backend.getAdminKeytab(ctx)
+	if err != nil {
+		return err
+	}
+	kadmin, err := g.backend.newAdminClient(adminKeytab)
+	if err != nil {
+		return err
+	}
+
+	err = kadmin.DeletePrincipals(principals...)
+	metrics.ObserveKadmin(g.backend.class, metrics.KadminOperationDelete, err)
+	if err != nil {
+		return err
+	}
+
+	// cached keys of deleted principals are invalid, a recreated principal gets new keys
+	if g.backend.cache != nil {
+		if err := g.backend.cache.Delete(ctx, principals); err != nil {
+			return err
+		}
+	}
+	if g.backend.rotation != nil {
+		if err := g.backend.rotation.Delete(ctx, principals); err != nil {
+			return err
+		}
+	}
+
+	// volumes are not tracked on records being deleted, the principal is recreated by the next volume
+	return g.registry.update(ctx, func(records map[string]*PrincipalRecord) bool {
+		changed := false
+		for _, principal := range principals {
+			key := keytabCacheKey(principal)
+			if record, ok := records[key]; ok && record.DeletingAt != nil {
+				delete(records, key)
+				changed = true
+			}
+		}
+		return changed
+	})
+}
+
+// findDeadOwners looks up the pods and services of the records, and returns the ones which no longer exist.
+func (g *PrincipalGarbageCollector) findDeadOwners(
+	ctx context.Context,
+	records []*PrincipalRecord,
+) (pods map[PrincipalOwner]bool, services map[PrincipalOwner]bool, err error) {
+	pods = make(map[PrincipalOwner]bool)
+	services = make(map[PrincipalOwner]bool)
+
+	for _, record := range records {
+		for _, owner := range record.Pods {
+			if _, ok := pods[owner]; ok {
+				continue
+			}
+			pod := &corev1.Pod{}
+			exists, err := g.exists(ctx, owner, pod)
+			if err != nil {
+				return nil, nil, err
+			}
+			pods[owner] = !exists || (owner.UID != "" && pod.UID != owner.UID)
+		}
+
+		for _, owner := range record.Services {
+			if _, ok := services[owner]; ok {
+				continue
+			}
+			exists, err := g.exists(ctx, owner, &corev1.Service{})
+			if err != nil {
+				return nil, nil, err
+			}
+			services[owner] = !exists
+		}
+	}
+
+	return pods, services, nil
+}
+
+func (g *PrincipalGarbageCollector) exists(ctx context.Context, owner PrincipalOwner, obj client.Object) (bool, error) {
+	if err := g.client.Get(ctx, client.ObjectKey{Namespace: owner.Namespace, Name: owner.Name}, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
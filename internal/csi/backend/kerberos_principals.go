@@ -0,0 +1,228 @@
+package backend
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/zncdatadev/operator-go/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1alpha1 "github.com/zncdatadev/secret-operator/api/v1alpha1"
+)
+
+const (
+	PrincipalRegistryConfigMapSuffix = "-principals"
+)
+
+var errPrincipalDeleting = errors.New("principals are being deleted by the garbage collector")
+
+// PrincipalOwner references a pod or service using a principal.
+type PrincipalOwner struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// UID is only set for pods, so a recreated pod with the same name is not mistaken for the old one.
+	UID types.UID `json:"uid,omitempty"`
+}
+
+// PrincipalRecord tracks a principal created by the kerberos backend, and the pods and services using it.
+type PrincipalRecord struct {
+	Principal string           `json:"principal"`
+	Pods      []PrincipalOwner `json:"pods,omitempty"`
+	Services  []PrincipalOwner `json:"services,omitempty"`
+	CreatedAt metav1.Time      `json:"createdAt"`
+	// OrphanedAt is the time the garbage collector found no pod or service using the principal,
+	// it is reset when the principal is used again.
+	OrphanedAt *metav1.Time `json:"orphanedAt,omitempty"`
+	// DeletingAt is the time the garbage collector started to delete the principal from the KDC,
+	// the principal can not be used until the record is removed after the deletion.
+	DeletingAt *metav1.Time `json:"deletingAt,omitempty"`
+}
+
+// addOwners adds the pod and services to the owners of the principal, and returns whether the record changed.
+func (r *PrincipalRecord) addOwners(pod PrincipalOwner, services []PrincipalOwner) bool {
+	changed := r.OrphanedAt != nil
+	r.OrphanedAt = nil
+
+	// a pod recreated with the same name replaces the old one
+	index := slices.IndexFunc(r.Pods, func(o PrincipalOwner) bool { return o.Namespace == pod.Namespace && o.Name == pod.Name })
+	if index < 0 {
+		r.Pods = append(r.Pods, pod)
+		changed = true
+	} else if r.Pods[index].UID != pod.UID {
+		r.Pods[index] = pod
+		changed = true
+	}
+
+	for _, service := range services {
+		if !slices.Contains(r.Services, service) {
+			r.Services = append(r.Services, service)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// orphanedFor returns how long the principal has been orphaned, zero when it is in use.
+func (r *PrincipalRecord) orphanedFor(now time.Time) time.Duration {
+	if r.OrphanedAt == nil {
+		return 0
+	}
+	return now.Sub(r.OrphanedAt.Time)
+}
+
+// principalRegistry stores the records of the principals created for a secret class in a configmap,
+// one record per principal. The key of the configmap data is the hash of the principal, as for the keytab cache.
+// A configmap is limited to 1MiB, which is enough for a few thousand principals.
+type principalRegistry struct {
+	client      client.Client
+	secretClass string
+	key         client.ObjectKey
+}
+
+func newPrincipalRegistry(c client.Client, secretClass string, spec *secretsv1alpha1.KerberosKeytabSpec) *principalRegistry {
+	key := client.ObjectKey{Name: secretClass + PrincipalRegistryConfigMapSuffix, Namespace: spec.AdminKeytabSecret.Namespace}
+	if spec.PrincipalGC.ConfigMap != nil {
+		key = client.ObjectKey{Name: spec.PrincipalGC.ConfigMap.Name, Namespace: spec.PrincipalGC.ConfigMap.Namespace}
+	}
+	return &principalRegistry{client: c, secretClass: secretClass, key: key}
+}
+
+// Track records the pod and services as owners of the principals.
+// Only principals created by the operator are tracked: a record is added for the created principals,
+// the other principals only get the owners added when they already have a record, so principals
+// which existed in the KDC before, e.g. pre-provisioned service principals, are never deleted.
+// It fails for principals being deleted by the garbage collector, the volume is retried once they are deleted.
+// The configmap is only written when a principal or owner is new, so remounting a volume does not cause a write.
+func (r *principalRegistry) Track(ctx context.Context, principals []string, created []string, pod PrincipalOwner, services []PrincipalOwner) error {
+	var deleting []string
+	err := r.update(ctx, func(records map[string]*PrincipalRecord) bool {
+		changed := false
+		deleting = deleting[:0]
+		for _, principal := range principals {
+			key := keytabCacheKey(principal)
+			record, ok := records[key]
+			if !ok {
+				if !slices.Contains(created, principal) {
+					continue
+				}
+				record = &PrincipalRecord{Principal: principal, CreatedAt: metav1.Now()}
+				records[key] = record
+			}
+			if record.DeletingAt != nil {
+				deleting = append(deleting, principal)
+				continue
+			}
+			if record.addOwners(pod, services) {
+				changed = true
+			}
+		}
+		// owners are not added when a principal is being deleted, so the volume does not keep it
+		return changed && len(deleting) == 0
+	})
+	if err != nil {
+		return err
+	}
+	if len(deleting) > 0 {
+		return fmt.Errorf("%w: %v", errPrincipalDeleting, deleting)
+	}
+	return nil
+}
+
+// List returns the records of all tracked principals.
+func (r *principalRegistry) List(ctx context.Context) ([]*PrincipalRecord, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := r.client.Get(ctx, r.key, configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	records, err := decodePrincipalRecords(configMap)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*PrincipalRecord, 0, len(records))
+	for _, record := range records {
+		list = append(list, record)
+	}
+	slices.SortFunc(list, func(a, b *PrincipalRecord) int { return cmp.Compare(a.Principal, b.Principal) })
+	return list, nil
+}
+
+// update applies mutate to the records and saves them when mutate returns true.
+// The configmap is shared by all csi nodes, the update is retried when another node modified or created it.
+func (r *principalRegistry) update(ctx context.Context, mutate func(records map[string]*PrincipalRecord) bool) error {
+	return retry.OnError(retry.DefaultRetry, func(err error) bool {
+		return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+	}, func() error {
+		configMap := &corev1.ConfigMap{}
+		if err := r.client.Get(ctx, r.key, configMap); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			configMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      r.key.Name,
+					Namespace: r.key.Namespace,
+					Labels: map[string]string{
+						constants.LabelKubernetesManagedBy: "secret-operator",
+					},
+					Annotations: map[string]string{
+						constants.AnnotationSecretsClass: r.secretClass,
+					},
+				},
+			}
+		}
+
+		records, err := decodePrincipalRecords(configMap)
+		if err != nil {
+			return err
+		}
+
+		if !mutate(records) {
+			return nil
+		}
+
+		data := make(map[string]string, len(records))
+		for key, record := range records {
+			encoded, err := json.Marshal(record)
+			if err != nil {
+				return err
+			}
+			data[key] = string(encoded)
+		}
+		configMap.Data = data
+
+		if configMap.ResourceVersion == "" {
+			logger.V(1).Info("create principal registry configmap", "name", r.key.Name, "namespace", r.key.Namespace)
+			return r.client.Create(ctx, configMap)
+		}
+		logger.V(1).Info("update principal registry configmap", "name", r.key.Name, "namespace", r.key.Namespace)
+		return r.client.Update(ctx, configMap)
+	})
+}
+
+func decodePrincipalRecords(configMap *corev1.ConfigMap) (map[string]*PrincipalRecord, error) {
+	records := make(map[string]*PrincipalRecord, len(configMap.Data))
+	for key, value := range configMap.Data {
+		record := &PrincipalRecord{}
+		if err := json.Unmarshal([]byte(value), record); err != nil {
+			return nil, fmt.Errorf("failed to decode principal record %s in configmap %s/%s: %w",
+				key, configMap.Namespace, configMap.Name, err)
+		}
+		records[key] = record
+	}
+	return records, nil
+}
@@ -0,0 +1,56 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPrincipalRegistryTrack(t *testing.T) {
+	ctx := context.Background()
+	registry := &principalRegistry{
+		client:      fake.NewClientBuilder().Build(),
+		secretClass: "kerberos",
+		key:         client.ObjectKey{Name: "kerberos-principals", Namespace: "kubedoop"},
+	}
+	pod := PrincipalOwner{Namespace: "default", Name: "web-0", UID: "1"}
+	existing := "HTTP/kdc.example.com@EXAMPLE.COM"
+
+	// principals which existed in the KDC are not tracked
+	if err := registry.Track(ctx, []string{testPrincipal, existing}, []string{testPrincipal}, pod, nil); err != nil {
+		t.Fatalf("Track() error = %v", err)
+	}
+	records, err := registry.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Principal != testPrincipal {
+		t.Fatalf("List() = %v, want only the created principal %s", records, testPrincipal)
+	}
+
+	// tracked principals get the owners of later volumes, without being created by them
+	other := PrincipalOwner{Namespace: "default", Name: "web-1", UID: "2"}
+	if err := registry.Track(ctx, []string{testPrincipal, existing}, nil, other, nil); err != nil {
+		t.Fatalf("Track() error = %v", err)
+	}
+	if records, _ = registry.List(ctx); len(records) != 1 || len(records[0].Pods) != 2 {
+		t.Fatalf("List() = %v, want %s used by two pods", records, testPrincipal)
+	}
+
+	// principals marked by the garbage collector can not be used
+	err = registry.update(ctx, func(records map[string]*PrincipalRecord) bool {
+		now := metav1.Now()
+		records[keytabCacheKey(testPrincipal)].DeletingAt = &now
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Track(ctx, []string{testPrincipal}, nil, pod, nil); !errors.Is(err, errPrincipalDeleting) {
+		t.Errorf("Track() error = %v, want %v", err, errPrincipalDeleting)
+	}
+}
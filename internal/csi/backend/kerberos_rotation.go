@@ -0,0 +1,323 @@
+package backend
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/zncdatadev/operator-go/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1alpha1 "github.com/zncdatadev/secret-operator/api/v1alpha1"
+	"github.com/zncdatadev/secret-operator/pkg/kerberos"
+)
+
+const (
+	KeyRotationSecretSuffix       = "-keys"
+	DefaultKeyRotationKeyAge      = 30 * 24 * time.Hour
+	DefaultKeyRotationGracePeriod = 24 * time.Hour
+
+	// keyRotationClaimTimeout is how long a node may take to rotate claimed principals,
+	// a claim older than this is considered failed and the principal is claimed again.
+	keyRotationClaimTimeout = 10 * time.Minute
+	keyRotationClaimSuffix  = ".rotating"
+)
+
+// keyRotation stores the keys of every principal in a secret, one keytab per principal as in the keytab cache.
+// The timestamp of a stored key is the time its key version was first seen, which is the creation time
+// of keys rotated by the backend.
+//
+// The secret is the source of truth for delivered keytabs: it holds the current key version and the
+// previous key versions within the grace period. Before a node rotates a principal, it claims the rotation
+// in the secret, so nodes mounting the same principal never rotate it twice.
+type keyRotation struct {
+	client      client.Client
+	secretClass string
+	key         client.ObjectKey
+	keyAge      time.Duration
+	gracePeriod time.Duration
+}
+
+func newKeyRotation(c client.Client, secretClass string, spec *secretsv1alpha1.KerberosKeytabSpec) (*keyRotation, error) {
+	rotationSpec := spec.KeyRotation
+
+	keyAge := DefaultKeyRotationKeyAge
+	if rotationSpec.KeyAge != "" {
+		d, err := time.ParseDuration(rotationSpec.KeyAge)
+		if err != nil {
+			return nil, err
+		}
+		keyAge = d
+	}
+
+	gracePeriod := DefaultKeyRotationGracePeriod
+	if rotationSpec.GracePeriod != "" {
+		d, err := time.ParseDuration(rotationSpec.GracePeriod)
+		if err != nil {
+			return nil, err
+		}
+		gracePeriod = d
+	}
+
+	key := client.ObjectKey{Name: secretClass + KeyRotationSecretSuffix, Namespace: spec.AdminKeytabSecret.Namespace}
+	if rotationSpec.Secret != nil {
+		key = client.ObjectKey{Name: rotationSpec.Secret.Name, Namespace: rotationSpec.Secret.Namespace}
+	}
+
+	return &keyRotation{client: c, secretClass: secretClass, key: key, keyAge: keyAge, gracePeriod: gracePeriod}, nil
+}
+
+func keyRotationClaimKey(principal string) string {
+	return keytabCacheKey(principal) + keyRotationClaimSuffix
+}
+
+// Rotate records the current keys of the principals, rotates the keys older than the key age with rekey,
+// and returns the keytab to deliver with the time its oldest current key reaches the key age.
+// Principals being rotated by another node are delivered with their stored keys, which stay valid for the
+// grace period, and the keytab expires once the claim times out, so the pod picks up the new keys.
+func (r *keyRotation) Rotate(
+	ctx context.Context,
+	principals []string,
+	current *kerberos.Keytab,
+	rekey func(principals []string) (*kerberos.Keytab, error),
+) (*kerberos.Keytab, *time.Time, error) {
+	now := time.Now()
+
+	var due, rotating []string
+	stored := make(map[string]*kerberos.Keytab, len(principals))
+	err := r.update(ctx, func(secret *corev1.Secret) (bool, error) {
+		due, rotating = due[:0], rotating[:0]
+		changed := false
+		for _, principal := range principals {
+			keytab, recorded, err := r.recordKeys(secret, principal, current.FilterByPrincipal(principal), now)
+			if err != nil {
+				return false, err
+			}
+			changed = changed || recorded
+			stored[principal] = keytab
+
+			if _, created := latestKeyVersion(keytab); len(keytab.Entries) == 0 || now.Sub(created) < r.keyAge {
+				continue
+			}
+
+			if claim, ok := secret.Data[keyRotationClaimKey(principal)]; ok {
+				claimedAt, err := time.Parse(time.RFC3339, string(claim))
+				if err == nil && now.Sub(claimedAt) < keyRotationClaimTimeout {
+					rotating = append(rotating, principal)
+					continue
+				}
+			}
+			secret.Data[keyRotationClaimKey(principal)] = []byte(now.Format(time.RFC3339))
+			changed = true
+			due = append(due, principal)
+		}
+		return changed, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(due) > 0 {
+		logger.Info("rotate kerberos keys", "principals", due, "keyAge", r.keyAge)
+		rekeyed, rekeyErr := rekey(due)
+
+		// release the claims even if the rotation failed, so the next volume retries it
+		err := r.update(ctx, func(secret *corev1.Secret) (bool, error) {
+			for _, principal := range due {
+				delete(secret.Data, keyRotationClaimKey(principal))
+				if rekeyErr != nil {
+					continue
+				}
+				keytab, _, err := r.recordKeys(secret, principal, rekeyed.FilterByPrincipal(principal), now)
+				if err != nil {
+					return false, err
+				}
+				stored[principal] = keytab
+			}
+			return true, nil
+		})
+		if rekeyErr != nil {
+			return nil, nil, rekeyErr
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	keytab := &kerberos.Keytab{}
+	var expiresTime *time.Time
+	for _, principal := range principals {
+		if len(stored[principal].Entries) == 0 {
+			continue
+		}
+		keytab = kerberos.MergeKeytabs(keytab, stored[principal])
+		_, created := latestKeyVersion(stored[principal])
+		expires := created.Add(r.keyAge)
+		if slices.Contains(rotating, principal) {
+			expires = now.Add(keyRotationClaimTimeout)
+		}
+		if expiresTime == nil || expires.Before(*expiresTime) {
+			expiresTime = &expires
+		}
+	}
+	if len(rotating) > 0 {
+		logger.Info("kerberos keys are being rotated by another node, deliver the current keys",
+			"principals", rotating, "expiresTime", expiresTime)
+	}
+	return keytab, expiresTime, nil
+}
+
+// recordKeys adds the keys of a key version newer than the stored ones, and drops previous key versions
+// which left the grace period. It returns the stored keys of the principal and whether they changed.
+func (r *keyRotation) recordKeys(
+	secret *corev1.Secret,
+	principal string,
+	current *kerberos.Keytab,
+	now time.Time,
+) (*kerberos.Keytab, bool, error) {
+	stored := &kerberos.Keytab{}
+	if data, ok := secret.Data[keytabCacheKey(principal)]; ok {
+		keytab, err := kerberos.ParseKeytab(data)
+		if err != nil {
+			logger.Error(err, "invalid stored keys, record the current keys again", "principal", principal)
+		} else {
+			stored = keytab
+		}
+	}
+
+	updated, changed := recordKeyVersions(stored, current, now, r.gracePeriod)
+	if !changed {
+		return stored, false, nil
+	}
+
+	data, err := updated.Marshal()
+	if err != nil {
+		return nil, false, err
+	}
+	secret.Data[keytabCacheKey(principal)] = data
+	return updated, true, nil
+}
+
+// recordKeyVersions adds the keys of current if their key version is newer than all stored key versions,
+// stamped with now as the creation time. A previous key version is superseded when the next key version
+// is created, and dropped once it has been superseded for the grace period.
+// Keys of current with an older key version, e.g. from a stale keytab cache, are ignored.
+func recordKeyVersions(stored, current *kerberos.Keytab, now time.Time, gracePeriod time.Duration) (*kerberos.Keytab, bool) {
+	changed := false
+	latest, _ := latestKeyVersion(stored)
+
+	entries := slices.Clone(stored.Entries)
+	for _, entry := range current.Entries {
+		if len(stored.Entries) > 0 && entry.KVNO <= latest {
+			continue
+		}
+		stamped := *entry
+		stamped.Timestamp = now
+		entries = append(entries, &stamped)
+		changed = true
+	}
+
+	created := make(map[uint32]time.Time)
+	for _, entry := range entries {
+		if t, ok := created[entry.KVNO]; !ok || entry.Timestamp.Before(t) {
+			created[entry.KVNO] = entry.Timestamp
+		}
+	}
+	versions := make([]uint32, 0, len(created))
+	for kvno := range created {
+		versions = append(versions, kvno)
+	}
+	slices.Sort(versions)
+
+	expired := make(map[uint32]bool)
+	for i := 0; i < len(versions)-1; i++ {
+		if supersededAt := created[versions[i+1]]; now.Sub(supersededAt) >= gracePeriod {
+			expired[versions[i]] = true
+		}
+	}
+	if len(expired) > 0 {
+		entries = slices.DeleteFunc(entries, func(entry *kerberos.KeytabEntry) bool { return expired[entry.KVNO] })
+		changed = true
+	}
+
+	return &kerberos.Keytab{Entries: entries}, changed
+}
+
+// latestKeyVersion returns the highest key version of the keytab and the time it was created.
+func latestKeyVersion(keytab *kerberos.Keytab) (uint32, time.Time) {
+	var kvno uint32
+	var created time.Time
+	for _, entry := range keytab.Entries {
+		if entry.KVNO > kvno || (entry.KVNO == kvno && entry.Timestamp.Before(created)) {
+			kvno = entry.KVNO
+			created = entry.Timestamp
+		}
+	}
+	return kvno, created
+}
+
+// Delete removes the stored keys of the principals, e.g. when the principals are deleted from the KDC.
+func (r *keyRotation) Delete(ctx context.Context, principals []string) error {
+	return r.update(ctx, func(secret *corev1.Secret) (bool, error) {
+		if secret.ResourceVersion == "" {
+			return false, nil
+		}
+		changed := false
+		for _, principal := range principals {
+			for _, key := range []string{keytabCacheKey(principal), keyRotationClaimKey(principal)} {
+				if _, ok := secret.Data[key]; ok {
+					delete(secret.Data, key)
+					changed = true
+				}
+			}
+		}
+		return changed, nil
+	})
+}
+
+// update applies mutate to the secret and saves it when mutate returns true.
+// The secret is shared by all csi nodes, the update is retried when another node modified or created it.
+func (r *keyRotation) update(ctx context.Context, mutate func(secret *corev1.Secret) (bool, error)) error {
+	return retry.OnError(retry.DefaultRetry, func(err error) bool {
+		return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+	}, func() error {
+		secret := &corev1.Secret{}
+		if err := r.client.Get(ctx, r.key, secret); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      r.key.Name,
+					Namespace: r.key.Namespace,
+					Labels: map[string]string{
+						constants.LabelKubernetesManagedBy: "secret-operator",
+					},
+					Annotations: map[string]string{
+						constants.AnnotationSecretsClass: r.secretClass,
+					},
+				},
+				Type: corev1.SecretTypeOpaque,
+			}
+		}
+		if secret.Data == nil {
+			secret.Data = make(map[string][]byte)
+		}
+
+		changed, err := mutate(secret)
+		if err != nil || !changed {
+			return err
+		}
+
+		if secret.ResourceVersion == "" {
+			logger.V(1).Info("create kerberos keys secret", "name", r.key.Name, "namespace", r.key.Namespace)
+			return r.client.Create(ctx, secret)
+		}
+		logger.V(1).Info("update kerberos keys secret", "name", r.key.Name, "namespace", r.key.Namespace)
+		return r.client.Update(ctx, secret)
+	})
+}
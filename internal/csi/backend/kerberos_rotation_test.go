@@ -0,0 +1,105 @@
+package backend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/zncdatadev/secret-operator/pkg/kerberos"
+)
+
+const testPrincipal = "HTTP/web.default.svc.cluster.local@EXAMPLE.COM"
+
+func newTestKeytab(kvno uint32, timestamp time.Time) *kerberos.Keytab {
+	return &kerberos.Keytab{Entries: []*kerberos.KeytabEntry{{
+		Principal: kerberos.ParsePrincipal(testPrincipal),
+		Timestamp: timestamp,
+		KVNO:      kvno,
+		EncType:   18,
+		Key:       []byte{byte(kvno)},
+	}}}
+}
+
+func kvnos(keytab *kerberos.Keytab) []uint32 {
+	versions := make([]uint32, 0, len(keytab.Entries))
+	for _, entry := range keytab.Entries {
+		versions = append(versions, entry.KVNO)
+	}
+	return versions
+}
+
+func TestRecordKeyVersions(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	grace := 24 * time.Hour
+
+	// first seen keys are stamped with now
+	stored, changed := recordKeyVersions(&kerberos.Keytab{}, newTestKeytab(1, now.Add(-time.Hour)), now, grace)
+	if !changed || len(stored.Entries) != 1 || !stored.Entries[0].Timestamp.Equal(now) {
+		t.Fatalf("recordKeyVersions() = %v, %v, want kvno 1 stamped with now", stored.Entries, changed)
+	}
+
+	// the same key version is not recorded again
+	if _, changed := recordKeyVersions(stored, newTestKeytab(1, now), now.Add(time.Hour), grace); changed {
+		t.Errorf("recordKeyVersions() changed for an already stored key version")
+	}
+
+	// an older key version from a stale cache is ignored
+	if _, changed := recordKeyVersions(stored, newTestKeytab(0, now), now.Add(time.Hour), grace); changed {
+		t.Errorf("recordKeyVersions() changed for an older key version")
+	}
+
+	// a rotated key keeps the previous key version within the grace period
+	rotatedAt := now.Add(30 * 24 * time.Hour)
+	stored, _ = recordKeyVersions(stored, newTestKeytab(2, rotatedAt), rotatedAt, grace)
+	if got := kvnos(stored); len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Fatalf("recordKeyVersions() kvnos = %v, want [1 2]", got)
+	}
+	if kvno, created := latestKeyVersion(stored); kvno != 2 || !created.Equal(rotatedAt) {
+		t.Errorf("latestKeyVersion() = %d, %v, want 2, %v", kvno, created, rotatedAt)
+	}
+
+	// the previous key version is dropped after the grace period
+	stored, changed = recordKeyVersions(stored, &kerberos.Keytab{}, rotatedAt.Add(grace), grace)
+	if got := kvnos(stored); !changed || len(got) != 1 || got[0] != 2 {
+		t.Errorf("recordKeyVersions() kvnos = %v, want [2]", got)
+	}
+}
+
+func TestRotateClaimedByAnotherNode(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	key := client.ObjectKey{Name: "kerberos-keys", Namespace: "kubedoop"}
+
+	stored, err := newTestKeytab(1, now.Add(-48*time.Hour)).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+		Data: map[string][]byte{
+			keytabCacheKey(testPrincipal):      stored,
+			keyRotationClaimKey(testPrincipal): []byte(now.Add(-time.Minute).Format(time.RFC3339)),
+		},
+	}).Build()
+	rotation := &keyRotation{client: c, secretClass: "kerberos", key: key, keyAge: 24 * time.Hour, gracePeriod: time.Hour}
+
+	keytab, expiresTime, err := rotation.Rotate(ctx, []string{testPrincipal}, newTestKeytab(1, now),
+		func(principals []string) (*kerberos.Keytab, error) {
+			t.Fatalf("rekey() called for %v, the rotation is claimed by another node", principals)
+			return nil, nil
+		})
+	if err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	if got := kvnos(keytab); len(got) != 1 || got[0] != 1 {
+		t.Errorf("Rotate() kvnos = %v, want the stored kvno 1", got)
+	}
+	if expiresTime == nil || expiresTime.After(now.Add(keyRotationClaimTimeout+time.Minute)) {
+		t.Errorf("Rotate() expiresTime = %v, want the claim timeout", expiresTime)
+	}
+}
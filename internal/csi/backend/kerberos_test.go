@@ -0,0 +1,26 @@
+package backend
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	secretsv1alpha1 "github.com/zncdatadev/secret-operator/api/v1alpha1"
+)
+
+func TestNewKerberosBackendHeimdalEncryptionTypes(t *testing.T) {
+	secretClass := &secretsv1alpha1.SecretClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "kerberos"},
+		Spec: secretsv1alpha1.SecretClassSpec{Backend: &secretsv1alpha1.BackendSpec{
+			KerberosKeytab: &secretsv1alpha1.KerberosKeytabSpec{
+				Admin:           &secretsv1alpha1.AdminServerSpec{Heimdal: &secretsv1alpha1.HeimdalSpec{KadminServer: "kdc"}},
+				RealmName:       "EXAMPLE.COM",
+				EncryptionTypes: []string{"aes256-cts-hmac-sha1-96"},
+			},
+		}},
+	}
+
+	if _, err := NewKerberosBackend(&BackendConfig{SecretClass: secretClass}); err == nil {
+		t.Errorf("NewKerberosBackend() expected error for encryption types with a heimdal admin server")
+	}
+}
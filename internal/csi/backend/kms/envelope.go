@@ -0,0 +1,136 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// EnvelopePEMType is the PEM block type of sealed data.
+	EnvelopePEMType = "SECRET-OPERATOR ENCRYPTED DATA"
+
+	keyIDHeader            = "Key-Id"
+	encryptedDEKHeader     = "Encrypted-Dek"
+	annotationHeaderPrefix = "Annotation-"
+
+	dekLength = 32
+)
+
+// Seal encrypts plaintext with AES-256-GCM and a new data encryption key, which is encrypted by the service.
+// The result is a PEM block with the encrypted data encryption key in its headers.
+func Seal(ctx context.Context, service Service, plaintext []byte) ([]byte, error) {
+	dek := make([]byte, dekLength)
+	if _, err := rand.Read(dek); err != nil {
+		return nil, err
+	}
+	ciphertext, err := aesGCMSeal(dek, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedDEK, err := service.Encrypt(ctx, dek)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt data encryption key: %w", err)
+	}
+	if strings.ContainsAny(encryptedDEK.KeyID, "\r\n") {
+		return nil, fmt.Errorf("invalid key id %q of key encryption key", encryptedDEK.KeyID)
+	}
+
+	headers := map[string]string{
+		keyIDHeader:        encryptedDEK.KeyID,
+		encryptedDEKHeader: base64.StdEncoding.EncodeToString(encryptedDEK.Ciphertext),
+	}
+	for key, value := range encryptedDEK.Annotations {
+		headers[annotationHeaderPrefix+key] = base64.StdEncoding.EncodeToString(value)
+	}
+	var sealed bytes.Buffer
+	if err := pem.Encode(&sealed, &pem.Block{Type: EnvelopePEMType, Headers: headers, Bytes: ciphertext}); err != nil {
+		return nil, err
+	}
+	return sealed.Bytes(), nil
+}
+
+// IsSealed returns whether data was sealed by Seal.
+func IsSealed(data []byte) bool {
+	return bytes.Contains(data, []byte("-----BEGIN "+EnvelopePEMType+"-----"))
+}
+
+// Open decrypts sealed data in memory, and returns the id of the key encryption key it was sealed with.
+func Open(ctx context.Context, service Service, data []byte) ([]byte, string, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != EnvelopePEMType {
+		return nil, "", errors.New("no sealed data")
+	}
+
+	encryptedDEK := &EncryptedData{KeyID: block.Headers[keyIDHeader]}
+	var err error
+	if encryptedDEK.Ciphertext, err = base64.StdEncoding.DecodeString(block.Headers[encryptedDEKHeader]); err != nil {
+		return nil, "", fmt.Errorf("invalid %s header: %w", encryptedDEKHeader, err)
+	}
+	for header, value := range block.Headers {
+		key, ok := strings.CutPrefix(header, annotationHeaderPrefix)
+		if !ok {
+			continue
+		}
+		if encryptedDEK.Annotations == nil {
+			encryptedDEK.Annotations = make(map[string][]byte)
+		}
+		if encryptedDEK.Annotations[key], err = base64.StdEncoding.DecodeString(value); err != nil {
+			return nil, "", fmt.Errorf("invalid %s header: %w", header, err)
+		}
+	}
+
+	dek, err := service.Decrypt(ctx, encryptedDEK)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decrypt data encryption key of key %s: %w", encryptedDEK.KeyID, err)
+	}
+	plaintext, err := aesGCMOpen(dek, block.Bytes)
+	if err != nil {
+		return nil, "", err
+	}
+	return plaintext, encryptedDEK.KeyID, nil
+}
+
+// aesGCMSeal encrypts plaintext with a random nonce, which is prepended to the ciphertext.
+func aesGCMSeal(key, plaintext []byte) ([]byte, error) {
+	aead, err := newAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func aesGCMOpen(key, data []byte) ([]byte, error) {
+	aead, err := newAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
+
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != dekLength {
+		return nil, fmt.Errorf("invalid key length %d, want %d", len(key), dekLength)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,49 @@
+package kms
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1alpha1 "github.com/zncdatadev/secret-operator/api/v1alpha1"
+)
+
+const DefaultPluginTimeout = 10 * time.Second
+
+var logger = ctrl.Log.WithName("kms")
+
+// Service encrypts data encryption keys with a key encryption key, e.g. of a KMS.
+type Service interface {
+	// KeyID returns the id of the current key encryption key,
+	// data encrypted with another key is stale and should be encrypted again.
+	KeyID(ctx context.Context) (string, error)
+	Encrypt(ctx context.Context, plaintext []byte) (*EncryptedData, error)
+	Decrypt(ctx context.Context, data *EncryptedData) ([]byte, error)
+}
+
+// EncryptedData is data encrypted by a Service, with the id of the key encryption key
+// and the annotations the service needs to decrypt it.
+type EncryptedData struct {
+	Ciphertext  []byte
+	KeyID       string
+	Annotations map[string][]byte
+}
+
+// NewService returns the service of the key encryption spec.
+func NewService(ctx context.Context, c client.Client, spec *secretsv1alpha1.KeyEncryptionSpec) (Service, error) {
+	switch {
+	case spec.Secret != nil:
+		kek, previous, err := loadSecretKEK(ctx, c, spec.Secret)
+		if err != nil {
+			return nil, err
+		}
+		return NewLocalService(kek, previous...)
+	case spec.KMSPlugin != nil:
+		return NewPluginService(spec.KMSPlugin)
+	default:
+		return nil, errors.New("one of secret or kmsPlugin is required in keyEncryption")
+	}
+}
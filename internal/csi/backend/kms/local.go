@@ -0,0 +1,156 @@
+package kms
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/zncdatadev/operator-go/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1alpha1 "github.com/zncdatadev/secret-operator/api/v1alpha1"
+)
+
+const (
+	// KEKSecretKey is the key of the key encryption key in the secret of a local service.
+	KEKSecretKey = "kek"
+	// PreviousKEKSecretKeyPrefix is the prefix of previous key encryption keys in the secret, e.g. `kek.1`,
+	// they only decrypt data encrypted before the key was replaced.
+	PreviousKEKSecretKeyPrefix = KEKSecretKey + "."
+)
+
+var _ Service = &LocalService{}
+
+// LocalService encrypts with an AES-256-GCM key encryption key held in memory,
+// and decrypts with it or one of the previous key encryption keys.
+type LocalService struct {
+	kek      []byte
+	keyID    string
+	previous map[string][]byte
+}
+
+// NewLocalService returns a service for the 32 byte key encryption key,
+// its key id is derived from the key so a replaced key makes data stale.
+// Data encrypted with one of the previous keys is decrypted, so it can be encrypted again with the current key.
+func NewLocalService(kek []byte, previous ...[]byte) (*LocalService, error) {
+	keyID, err := LocalKeyID(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	service := &LocalService{kek: kek, keyID: keyID, previous: make(map[string][]byte, len(previous))}
+	for _, previousKEK := range previous {
+		previousKeyID, err := LocalKeyID(previousKEK)
+		if err != nil {
+			return nil, fmt.Errorf("previous key encryption key: %w", err)
+		}
+		service.previous[previousKeyID] = previousKEK
+	}
+	return service, nil
+}
+
+// LocalKeyID returns the key id of a LocalService with the key encryption key.
+func LocalKeyID(kek []byte) (string, error) {
+	if len(kek) != dekLength {
+		return "", fmt.Errorf("invalid key encryption key length %d, want %d", len(kek), dekLength)
+	}
+	sum := sha256.Sum256(kek)
+	return "local/" + hex.EncodeToString(sum[:8]), nil
+}
+
+func (l *LocalService) KeyID(ctx context.Context) (string, error) {
+	return l.keyID, nil
+}
+
+func (l *LocalService) Encrypt(ctx context.Context, plaintext []byte) (*EncryptedData, error) {
+	ciphertext, err := aesGCMSeal(l.kek, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return &EncryptedData{Ciphertext: ciphertext, KeyID: l.keyID}, nil
+}
+
+func (l *LocalService) Decrypt(ctx context.Context, data *EncryptedData) ([]byte, error) {
+	if data.KeyID == l.keyID {
+		return aesGCMOpen(l.kek, data.Ciphertext)
+	}
+	if kek, ok := l.previous[data.KeyID]; ok {
+		return aesGCMOpen(kek, data.Ciphertext)
+	}
+	return nil, fmt.Errorf("data is encrypted with key %s, but the key encryption key is %s and no previous key matches",
+		data.KeyID, l.keyID)
+}
+
+// loadSecretKEK gets the key encryption key and the previous key encryption keys from the secret,
+// the secret is created with a random key when it does not exist.
+// To replace the key, move it to a `kek.<n>` key and set a new `kek`, data is encrypted again with the new key
+// when it is loaded. The previous key can be removed once all data was encrypted again.
+func loadSecretKEK(ctx context.Context, c client.Client, spec *secretsv1alpha1.SecretSpec) ([]byte, [][]byte, error) {
+	var kek []byte
+	var previous [][]byte
+	err := retry.OnError(retry.DefaultRetry, apierrors.IsAlreadyExists, func() error {
+		secret := &corev1.Secret{}
+		err := c.Get(ctx, client.ObjectKey{Name: spec.Name, Namespace: spec.Namespace}, secret)
+		if err == nil {
+			var ok bool
+			if kek, ok = secret.Data[KEKSecretKey]; !ok {
+				return fmt.Errorf("could not find %s in key encryption key secret %s/%s", KEKSecretKey, spec.Namespace, spec.Name)
+			}
+			previous = previousSecretKEKs(secret.Data)
+			return nil
+		}
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+
+		kek = make([]byte, dekLength)
+		if _, err := rand.Read(kek); err != nil {
+			return err
+		}
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      spec.Name,
+				Namespace: spec.Namespace,
+				Labels: map[string]string{
+					constants.LabelKubernetesManagedBy: "secret-operator",
+				},
+			},
+			Type: corev1.SecretTypeOpaque,
+			Data: map[string][]byte{KEKSecretKey: kek},
+		}
+		if err := c.Create(ctx, secret); err != nil {
+			return err
+		}
+		logger.Info("created key encryption key secret", "name", spec.Name, "namespace", spec.Namespace)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return kek, previous, nil
+}
+
+// previousSecretKEKs returns the `kek.<n>` keys of the secret data, sorted by key.
+func previousSecretKEKs(data map[string][]byte) [][]byte {
+	names := make([]string, 0, len(data))
+	for name := range data {
+		if strings.HasPrefix(name, PreviousKEKSecretKeyPrefix) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	previous := make([][]byte, 0, len(names))
+	for _, name := range names {
+		previous = append(previous, data[name])
+	}
+	return previous
+}
@@ -0,0 +1,123 @@
+package kms
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	secretsv1alpha1 "github.com/zncdatadev/secret-operator/api/v1alpha1"
+	kmsv2 "github.com/zncdatadev/secret-operator/internal/csi/backend/kms/v2"
+)
+
+// kmsPluginAPIVersions are the versions of the KMS v2 API a plugin may report in its status.
+var kmsPluginAPIVersions = []string{"v2", "v2beta1"}
+
+// pluginConnections are the connections to KMS plugins by endpoint, shared by all secret classes.
+var pluginConnections = &pluginConnectionCache{connections: make(map[string]*grpc.ClientConn)}
+
+type pluginConnectionCache struct {
+	mu          sync.Mutex
+	connections map[string]*grpc.ClientConn
+}
+
+func (c *pluginConnectionCache) get(endpoint string) (*grpc.ClientConn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if conn, ok := c.connections[endpoint]; ok {
+		return conn, nil
+	}
+	// KMS plugins listen on a unix socket, like for the kube-apiserver
+	conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to kms plugin %s: %w", endpoint, err)
+	}
+	c.connections[endpoint] = conn
+	return conn, nil
+}
+
+var _ Service = &PluginService{}
+
+// PluginService encrypts with a KMS plugin implementing the Kubernetes KMS v2 API.
+type PluginService struct {
+	client   kmsv2.KeyManagementServiceClient
+	endpoint string
+	timeout  time.Duration
+}
+
+func NewPluginService(spec *secretsv1alpha1.KMSPluginSpec) (*PluginService, error) {
+	timeout := DefaultPluginTimeout
+	if spec.Timeout != "" {
+		d, err := time.ParseDuration(spec.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		timeout = d
+	}
+
+	conn, err := pluginConnections.get(spec.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &PluginService{client: kmsv2.NewKeyManagementServiceClient(conn), endpoint: spec.Endpoint, timeout: timeout}, nil
+}
+
+// KeyID returns the key id of the plugin status, a plugin which is not healthy fails.
+func (p *PluginService) KeyID(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	status, err := p.client.Status(ctx, &kmsv2.StatusRequest{})
+	if err != nil {
+		return "", fmt.Errorf("kms plugin %s: %w", p.endpoint, err)
+	}
+	if !slices.Contains(kmsPluginAPIVersions, status.Version) {
+		return "", fmt.Errorf("kms plugin %s: unsupported api version %q", p.endpoint, status.Version)
+	}
+	if status.Healthz != "ok" {
+		return "", fmt.Errorf("kms plugin %s is not healthy: %s", p.endpoint, status.Healthz)
+	}
+	if status.KeyId == "" {
+		return "", fmt.Errorf("kms plugin %s: empty key id in status", p.endpoint)
+	}
+	return status.KeyId, nil
+}
+
+func (p *PluginService) Encrypt(ctx context.Context, plaintext []byte) (*EncryptedData, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	response, err := p.client.Encrypt(ctx, &kmsv2.EncryptRequest{Plaintext: plaintext, Uid: uuid.NewString()})
+	if err != nil {
+		return nil, fmt.Errorf("kms plugin %s: %w", p.endpoint, err)
+	}
+	if len(response.Ciphertext) == 0 || response.KeyId == "" {
+		return nil, fmt.Errorf("kms plugin %s: empty ciphertext or key id in encrypt response", p.endpoint)
+	}
+	return &EncryptedData{Ciphertext: response.Ciphertext, KeyID: response.KeyId, Annotations: response.Annotations}, nil
+}
+
+func (p *PluginService) Decrypt(ctx context.Context, data *EncryptedData) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	response, err := p.client.Decrypt(ctx, &kmsv2.DecryptRequest{
+		Ciphertext:  data.Ciphertext,
+		Uid:         uuid.NewString(),
+		KeyId:       data.KeyID,
+		Annotations: data.Annotations,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("kms plugin %s: %w", p.endpoint, err)
+	}
+	if len(response.Plaintext) == 0 {
+		return nil, fmt.Errorf("kms plugin %s: empty plaintext in decrypt response", p.endpoint)
+	}
+	return response.Plaintext, nil
+}
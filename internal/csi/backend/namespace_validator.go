@@ -80,6 +80,45 @@ func ValidateCrossNamespaceReferences(secretClass *secretsv1alpha1.SecretClass,
 		}
 	}
 
+	// Validate Kerberos backend: KeytabCache.Secret.Namespace
+	if backend.KerberosKeytab != nil && backend.KerberosKeytab.KeytabCache != nil && backend.KerberosKeytab.KeytabCache.Secret != nil {
+		ns := backend.KerberosKeytab.KeytabCache.Secret.Namespace
+		if !isAllowedNamespace(ns, allowed) {
+			return &NamespaceValidationError{
+				PodNamespace:       podNamespace,
+				RequestedNamespace: ns,
+				SecretClassName:    className,
+				Field:              "kerberosKeytab.keytabCache.secret.namespace",
+			}
+		}
+	}
+
+	// Validate Kerberos backend: KeyRotation.Secret.Namespace
+	if backend.KerberosKeytab != nil && backend.KerberosKeytab.KeyRotation != nil && backend.KerberosKeytab.KeyRotation.Secret != nil {
+		ns := backend.KerberosKeytab.KeyRotation.Secret.Namespace
+		if !isAllowedNamespace(ns, allowed) {
+			return &NamespaceValidationError{
+				PodNamespace:       podNamespace,
+				RequestedNamespace: ns,
+				SecretClassName:    className,
+				Field:              "kerberosKeytab.keyRotation.secret.namespace",
+			}
+		}
+	}
+
+	// Validate Kerberos backend: PrincipalGC.ConfigMap.Namespace
+	if backend.KerberosKeytab != nil && backend.KerberosKeytab.PrincipalGC != nil && backend.KerberosKeytab.PrincipalGC.ConfigMap != nil {
+		ns := backend.KerberosKeytab.PrincipalGC.ConfigMap.Namespace
+		if !isAllowedNamespace(ns, allowed) {
+			return &NamespaceValidationError{
+				PodNamespace:       podNamespace,
+				RequestedNamespace: ns,
+				SecretClassName:    className,
+				Field:              "kerberosKeytab.principalGc.configMap.namespace",
+			}
+		}
+	}
+
 	// Validate AutoTLS backend: CA.Secret.Namespace and AdditionalTrustRoots
 	if backend.AutoTls != nil {
 		// CA Secret
@@ -95,6 +134,19 @@ func ValidateCrossNamespaceReferences(secretClass *secretsv1alpha1.SecretClass,
 			}
 		}
 
+		// Key encryption key Secret
+		if backend.AutoTls.CA != nil && backend.AutoTls.CA.KeyEncryption != nil && backend.AutoTls.CA.KeyEncryption.Secret != nil {
+			ns := backend.AutoTls.CA.KeyEncryption.Secret.Namespace
+			if !isAllowedNamespace(ns, allowed) {
+				return &NamespaceValidationError{
+					PodNamespace:       podNamespace,
+					RequestedNamespace: ns,
+					SecretClassName:    className,
+					Field:              "autoTls.ca.keyEncryption.secret.namespace",
+				}
+			}
+		}
+
 		// Additional Trust Roots
 		for i, root := range backend.AutoTls.AdditionalTrustRoots {
 			fieldPrefix := fmt.Sprintf("autoTls.additionalTrustRoots[%d]", i)
@@ -125,6 +177,73 @@ func ValidateCrossNamespaceReferences(secretClass *secretsv1alpha1.SecretClass,
 		}
 	}
 
+	// Validate SSH CA backend: CA.Secret.Namespace
+	if backend.SSHCA != nil && backend.SSHCA.CA != nil && backend.SSHCA.CA.Secret != nil {
+		ns := backend.SSHCA.CA.Secret.Namespace
+		if !isAllowedNamespace(ns, allowed) {
+			return &NamespaceValidationError{
+				PodNamespace:       podNamespace,
+				RequestedNamespace: ns,
+				SecretClassName:    className,
+				Field:              "sshCa.ca.secret.namespace",
+			}
+		}
+	}
+
+	// Validate JWT backend: SigningKeys.Secret.Namespace and JWKSConfigMap.Namespace
+	if backend.JWT != nil {
+		if backend.JWT.SigningKeys != nil && backend.JWT.SigningKeys.Secret != nil {
+			ns := backend.JWT.SigningKeys.Secret.Namespace
+			if !isAllowedNamespace(ns, allowed) {
+				return &NamespaceValidationError{
+					PodNamespace:       podNamespace,
+					RequestedNamespace: ns,
+					SecretClassName:    className,
+					Field:              "jwt.signingKeys.secret.namespace",
+				}
+			}
+		}
+
+		if backend.JWT.JWKSConfigMap != nil {
+			ns := backend.JWT.JWKSConfigMap.Namespace
+			if !isAllowedNamespace(ns, allowed) {
+				return &NamespaceValidationError{
+					PodNamespace:       podNamespace,
+					RequestedNamespace: ns,
+					SecretClassName:    className,
+					Field:              "jwt.jwksConfigMap.namespace",
+				}
+			}
+		}
+	}
+
+	// Validate Plugin backend: TLS.CAConfigMap.Namespace and TLS.ClientCertSecret.Namespace
+	if backend.Plugin != nil && backend.Plugin.TLS != nil {
+		if backend.Plugin.TLS.CAConfigMap != nil {
+			ns := backend.Plugin.TLS.CAConfigMap.Namespace
+			if !isAllowedNamespace(ns, allowed) {
+				return &NamespaceValidationError{
+					PodNamespace:       podNamespace,
+					RequestedNamespace: ns,
+					SecretClassName:    className,
+					Field:              "plugin.tls.caConfigMap.namespace",
+				}
+			}
+		}
+
+		if backend.Plugin.TLS.ClientCertSecret != nil {
+			ns := backend.Plugin.TLS.ClientCertSecret.Namespace
+			if !isAllowedNamespace(ns, allowed) {
+				return &NamespaceValidationError{
+					PodNamespace:       podNamespace,
+					RequestedNamespace: ns,
+					SecretClassName:    className,
+					Field:              "plugin.tls.clientCertSecret.namespace",
+				}
+			}
+		}
+	}
+
 	// Validate K8sSearch backend: searchNamespace.Name (explicit namespace only)
 	// searchNamespace.Pod is safe — it uses the Pod's own namespace implicitly.
 	if backend.K8sSearch != nil && backend.K8sSearch.SearchNamespace != nil {
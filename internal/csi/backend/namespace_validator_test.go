@@ -137,6 +137,188 @@ func TestValidateCrossNamespaceReferences_Denied(t *testing.T) {
 			expectedField: "kerberosKeytab.adminKeytabSecret.namespace",
 			expectedReqNs: "ns-b",
 		},
+		{
+			name:         "kerberos keytab cache cross-namespace denied",
+			podNamespace: testNamespaceA,
+			volumeCtx:    &volume.SecretVolumeContext{PodNamespace: testNamespaceA},
+			secretClass: &secretsv1alpha1.SecretClass{
+				ObjectMeta: metav1.ObjectMeta{Name: testSecretClass},
+				Spec: secretsv1alpha1.SecretClassSpec{
+					Backend: &secretsv1alpha1.BackendSpec{
+						KerberosKeytab: &secretsv1alpha1.KerberosKeytabSpec{
+							AdminKeytabSecret: &secretsv1alpha1.KeytabSecretSpec{
+								Name:      "my-keytab",
+								Namespace: testNamespaceA,
+							},
+							KeytabCache: &secretsv1alpha1.KeytabCacheSpec{
+								Secret: &secretsv1alpha1.SecretSpec{
+									Name:      "my-keytab-cache",
+									Namespace: "ns-b",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedField: "kerberosKeytab.keytabCache.secret.namespace",
+			expectedReqNs: "ns-b",
+		},
+		{
+			name:         "kerberos key rotation secret cross-namespace denied",
+			podNamespace: testNamespaceA,
+			volumeCtx:    &volume.SecretVolumeContext{PodNamespace: testNamespaceA},
+			secretClass: &secretsv1alpha1.SecretClass{
+				ObjectMeta: metav1.ObjectMeta{Name: testSecretClass},
+				Spec: secretsv1alpha1.SecretClassSpec{
+					Backend: &secretsv1alpha1.BackendSpec{
+						KerberosKeytab: &secretsv1alpha1.KerberosKeytabSpec{
+							AdminKeytabSecret: &secretsv1alpha1.KeytabSecretSpec{
+								Name:      "my-keytab",
+								Namespace: testNamespaceA,
+							},
+							KeyRotation: &secretsv1alpha1.KeyRotationSpec{
+								Secret: &secretsv1alpha1.SecretSpec{
+									Name:      "my-keys",
+									Namespace: "ns-b",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedField: "kerberosKeytab.keyRotation.secret.namespace",
+			expectedReqNs: "ns-b",
+		},
+		{
+			name:         "kerberos principal gc configmap cross-namespace denied",
+			podNamespace: testNamespaceA,
+			volumeCtx:    &volume.SecretVolumeContext{PodNamespace: testNamespaceA},
+			secretClass: &secretsv1alpha1.SecretClass{
+				ObjectMeta: metav1.ObjectMeta{Name: testSecretClass},
+				Spec: secretsv1alpha1.SecretClassSpec{
+					Backend: &secretsv1alpha1.BackendSpec{
+						KerberosKeytab: &secretsv1alpha1.KerberosKeytabSpec{
+							AdminKeytabSecret: &secretsv1alpha1.KeytabSecretSpec{
+								Name:      "my-keytab",
+								Namespace: testNamespaceA,
+							},
+							PrincipalGC: &secretsv1alpha1.PrincipalGCSpec{
+								ConfigMap: &secretsv1alpha1.ConfigMapSpec{
+									Name:      "my-principals",
+									Namespace: "ns-b",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedField: "kerberosKeytab.principalGc.configMap.namespace",
+			expectedReqNs: "ns-b",
+		},
+		{
+			name:         "ssh ca secret cross-namespace denied",
+			podNamespace: testNamespaceA,
+			volumeCtx:    &volume.SecretVolumeContext{PodNamespace: testNamespaceA},
+			secretClass: &secretsv1alpha1.SecretClass{
+				ObjectMeta: metav1.ObjectMeta{Name: testSecretClass},
+				Spec: secretsv1alpha1.SecretClassSpec{
+					Backend: &secretsv1alpha1.BackendSpec{
+						SSHCA: &secretsv1alpha1.SSHCASpec{
+							CA: &secretsv1alpha1.SSHCAKeySpec{
+								Secret: &secretsv1alpha1.SecretSpec{
+									Name:      "ssh-ca",
+									Namespace: "ns-b",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedField: "sshCa.ca.secret.namespace",
+			expectedReqNs: "ns-b",
+		},
+		{
+			name:         "jwt signing keys secret cross-namespace denied",
+			podNamespace: testNamespaceA,
+			volumeCtx:    &volume.SecretVolumeContext{PodNamespace: testNamespaceA},
+			secretClass: &secretsv1alpha1.SecretClass{
+				ObjectMeta: metav1.ObjectMeta{Name: testSecretClass},
+				Spec: secretsv1alpha1.SecretClassSpec{
+					Backend: &secretsv1alpha1.BackendSpec{
+						JWT: &secretsv1alpha1.JWTSpec{
+							Issuer: "https://issuer.example.com",
+							SigningKeys: &secretsv1alpha1.JWTSigningKeysSpec{
+								Secret: &secretsv1alpha1.SecretSpec{Name: "jwt-keys", Namespace: "ns-b"},
+							},
+						},
+					},
+				},
+			},
+			expectedField: "jwt.signingKeys.secret.namespace",
+			expectedReqNs: "ns-b",
+		},
+		{
+			name:         "jwt jwks configmap cross-namespace denied",
+			podNamespace: testNamespaceA,
+			volumeCtx:    &volume.SecretVolumeContext{PodNamespace: testNamespaceA},
+			secretClass: &secretsv1alpha1.SecretClass{
+				ObjectMeta: metav1.ObjectMeta{Name: testSecretClass},
+				Spec: secretsv1alpha1.SecretClassSpec{
+					Backend: &secretsv1alpha1.BackendSpec{
+						JWT: &secretsv1alpha1.JWTSpec{
+							Issuer: "https://issuer.example.com",
+							SigningKeys: &secretsv1alpha1.JWTSigningKeysSpec{
+								Secret: &secretsv1alpha1.SecretSpec{Name: "jwt-keys", Namespace: testNamespaceA},
+							},
+							JWKSConfigMap: &secretsv1alpha1.ConfigMapSpec{Name: "jwks", Namespace: "ns-b"},
+						},
+					},
+				},
+			},
+			expectedField: "jwt.jwksConfigMap.namespace",
+			expectedReqNs: "ns-b",
+		},
+		{
+			name:         "plugin tls ca configmap cross-namespace denied",
+			podNamespace: testNamespaceA,
+			volumeCtx:    &volume.SecretVolumeContext{PodNamespace: testNamespaceA},
+			secretClass: &secretsv1alpha1.SecretClass{
+				ObjectMeta: metav1.ObjectMeta{Name: testSecretClass},
+				Spec: secretsv1alpha1.SecretClassSpec{
+					Backend: &secretsv1alpha1.BackendSpec{
+						Plugin: &secretsv1alpha1.PluginSpec{
+							Endpoint: "dns:///vault-plugin.ns-b.svc:9000",
+							TLS: &secretsv1alpha1.PluginTLSSpec{
+								CAConfigMap: &secretsv1alpha1.ConfigMapSpec{Name: "vault-plugin-ca", Namespace: "ns-b"},
+							},
+						},
+					},
+				},
+			},
+			expectedField: "plugin.tls.caConfigMap.namespace",
+			expectedReqNs: "ns-b",
+		},
+		{
+			name:         "plugin tls client cert secret cross-namespace denied",
+			podNamespace: testNamespaceA,
+			volumeCtx:    &volume.SecretVolumeContext{PodNamespace: testNamespaceA},
+			secretClass: &secretsv1alpha1.SecretClass{
+				ObjectMeta: metav1.ObjectMeta{Name: testSecretClass},
+				Spec: secretsv1alpha1.SecretClassSpec{
+					Backend: &secretsv1alpha1.BackendSpec{
+						Plugin: &secretsv1alpha1.PluginSpec{
+							Endpoint: "dns:///vault-plugin.ns-a.svc:9000",
+							TLS: &secretsv1alpha1.PluginTLSSpec{
+								CAConfigMap:      &secretsv1alpha1.ConfigMapSpec{Name: "vault-plugin-ca", Namespace: testNamespaceA},
+								ClientCertSecret: &secretsv1alpha1.SecretSpec{Name: "vault-plugin-client", Namespace: "ns-b"},
+							},
+						},
+					},
+				},
+			},
+			expectedField: "plugin.tls.clientCertSecret.namespace",
+			expectedReqNs: "ns-b",
+		},
 		{
 			name:         "autotls CA secret cross-namespace denied",
 			podNamespace: testNamespaceA,
@@ -159,6 +341,34 @@ func TestValidateCrossNamespaceReferences_Denied(t *testing.T) {
 			expectedField: "autoTls.ca.secret.namespace",
 			expectedReqNs: "platform",
 		},
+		{
+			name:         "autotls key encryption secret cross-namespace denied",
+			podNamespace: testNamespaceA,
+			volumeCtx:    &volume.SecretVolumeContext{PodNamespace: testNamespaceA},
+			secretClass: &secretsv1alpha1.SecretClass{
+				ObjectMeta: metav1.ObjectMeta{Name: testSecretClass},
+				Spec: secretsv1alpha1.SecretClassSpec{
+					Backend: &secretsv1alpha1.BackendSpec{
+						AutoTls: &secretsv1alpha1.AutoTlsSpec{
+							CA: &secretsv1alpha1.CASpec{
+								Secret: &secretsv1alpha1.SecretSpec{
+									Name:      testCASecret,
+									Namespace: testNamespaceA,
+								},
+								KeyEncryption: &secretsv1alpha1.KeyEncryptionSpec{
+									Secret: &secretsv1alpha1.SecretSpec{
+										Name:      "ca-kek",
+										Namespace: "platform",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedField: "autoTls.ca.keyEncryption.secret.namespace",
+			expectedReqNs: "platform",
+		},
 		{
 			name:         "autotls additional trust root configmap cross-namespace denied",
 			podNamespace: testNamespaceA,
@@ -0,0 +1,253 @@
+package backend
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1alpha1 "github.com/zncdatadev/secret-operator/api/v1alpha1"
+	pluginv1alpha1 "github.com/zncdatadev/secret-operator/pkg/plugin/v1alpha1"
+	"github.com/zncdatadev/secret-operator/pkg/pod_info"
+	"github.com/zncdatadev/secret-operator/pkg/util"
+	"github.com/zncdatadev/secret-operator/pkg/volume"
+)
+
+const (
+	DefaultPluginTimeout = 30 * time.Second
+	PluginCAConfigMapKey = "ca.crt"
+)
+
+var _ IBackend = &PluginBackend{}
+
+// pluginConnections are the connections to backend plugins by endpoint, shared by all volumes.
+var pluginConnections = &pluginConnectionCache{connections: make(map[string]*pluginConnection)}
+
+type pluginConnection struct {
+	conn *grpc.ClientConn
+	// credentialsHash identifies the tls settings the connection was created with
+	credentialsHash string
+}
+
+type pluginConnectionCache struct {
+	mu          sync.Mutex
+	connections map[string]*pluginConnection
+}
+
+// get returns the connection to the endpoint, a connection with outdated tls settings is replaced.
+func (c *pluginConnectionCache) get(endpoint string, tlsConfig *tls.Config, credentialsHash string) (*grpc.ClientConn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if existing, ok := c.connections[endpoint]; ok {
+		if existing.credentialsHash == credentialsHash {
+			return existing.conn, nil
+		}
+		logger.V(1).Info("tls settings of backend plugin changed, reconnect", "endpoint", endpoint)
+		if err := existing.conn.Close(); err != nil {
+			logger.Error(err, "failed to close backend plugin connection", "endpoint", endpoint)
+		}
+		delete(c.connections, endpoint)
+	}
+
+	transportCredentials := insecure.NewCredentials()
+	if tlsConfig != nil {
+		transportCredentials = credentials.NewTLS(tlsConfig)
+	}
+	conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(transportCredentials))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to backend plugin %s: %w", endpoint, err)
+	}
+	c.connections[endpoint] = &pluginConnection{conn: conn, credentialsHash: credentialsHash}
+	return conn, nil
+}
+
+type PluginBackend struct {
+	client        pluginv1alpha1.BackendClient
+	podInfo       *pod_info.PodInfo
+	volumeContext *volume.SecretVolumeContext
+	endpoint      string
+	parameters    map[string]string
+	timeout       time.Duration
+}
+
+func NewPluginBackend(config *BackendConfig) (IBackend, error) {
+	spec := config.SecretClass.Spec.Backend.Plugin
+	// the secrets would be sent in plaintext to any listener of the endpoint
+	if spec.TLS == nil && !strings.HasPrefix(spec.Endpoint, "unix:") {
+		return nil, fmt.Errorf("tls is required for backend plugin endpoint %s, only unix sockets can be used without tls",
+			spec.Endpoint)
+	}
+
+	timeout := DefaultPluginTimeout
+	if spec.Timeout != "" {
+		d, err := time.ParseDuration(spec.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		timeout = d
+	}
+
+	var tlsConfig *tls.Config
+	var credentialsHash string
+	if spec.TLS != nil {
+		var err error
+		if tlsConfig, credentialsHash, err = pluginTLSConfig(config.ctx, config.Client, spec.TLS); err != nil {
+			return nil, err
+		}
+	}
+
+	conn, err := pluginConnections.get(spec.Endpoint, tlsConfig, credentialsHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PluginBackend{
+		client:        pluginv1alpha1.NewBackendClient(conn),
+		podInfo:       config.PodInfo,
+		volumeContext: config.VolumeContext,
+		endpoint:      spec.Endpoint,
+		parameters:    spec.Parameters,
+		timeout:       timeout,
+	}, nil
+}
+
+// pluginTLSConfig returns the tls config verifying the plugin with the trust roots of the configmap,
+// presenting the client certificate of the secret if set, and a hash identifying the settings.
+func pluginTLSConfig(ctx context.Context, c client.Client, spec *secretsv1alpha1.PluginTLSSpec) (*tls.Config, string, error) {
+	if spec.CAConfigMap == nil {
+		return nil, "", fmt.Errorf("caConfigMap is nil in plugin tls")
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := c.Get(ctx, client.ObjectKey{Name: spec.CAConfigMap.Name, Namespace: spec.CAConfigMap.Namespace}, configMap); err != nil {
+		return nil, "", err
+	}
+	caPEM, ok := configMap.Data[PluginCAConfigMapKey]
+	if !ok {
+		return nil, "", fmt.Errorf("could not find %s in configmap %s/%s", PluginCAConfigMapKey, configMap.Namespace, configMap.Name)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(caPEM)) {
+		return nil, "", fmt.Errorf("no certificates in %s of configmap %s/%s", PluginCAConfigMapKey, configMap.Namespace, configMap.Name)
+	}
+
+	tlsConfig := &tls.Config{RootCAs: pool, ServerName: spec.ServerName, MinVersion: tls.VersionTLS12}
+	hash := sha256.New()
+	hash.Write([]byte(spec.ServerName + "\n" + caPEM))
+
+	if spec.ClientCertSecret != nil {
+		secret := &corev1.Secret{}
+		if err := c.Get(ctx, client.ObjectKey{Name: spec.ClientCertSecret.Name, Namespace: spec.ClientCertSecret.Namespace}, secret); err != nil {
+			return nil, "", err
+		}
+		certificate, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid client certificate in secret %s/%s: %w", secret.Namespace, secret.Name, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+		hash.Write(secret.Data[corev1.TLSCertKey])
+		hash.Write(secret.Data[corev1.TLSPrivateKeyKey])
+	}
+
+	return tlsConfig, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// GetQualifiedNodeNames implements Backend.
+func (p *PluginBackend) GetQualifiedNodeNames(ctx context.Context) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	response, err := p.client.GetQualifiedNodeNames(ctx, &pluginv1alpha1.GetQualifiedNodeNamesRequest{
+		Pod:           p.pluginPodInfo(nil),
+		VolumeContext: p.pluginVolumeContext(),
+		Parameters:    p.parameters,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("backend plugin %s: %w", p.endpoint, err)
+	}
+	return response.NodeNames, nil
+}
+
+// GetSecretData implements Backend.
+func (p *PluginBackend) GetSecretData(ctx context.Context) (*util.SecretContent, error) {
+	addresses, err := p.podInfo.GetScopedAddresses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	response, err := p.client.GetSecretData(ctx, &pluginv1alpha1.GetSecretDataRequest{
+		Pod:           p.pluginPodInfo(addresses),
+		VolumeContext: p.pluginVolumeContext(),
+		Parameters:    p.parameters,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("backend plugin %s: %w", p.endpoint, err)
+	}
+
+	content := &util.SecretContent{Data: make(map[string]string, len(response.Data))}
+	for name, value := range response.Data {
+		content.Data[name] = string(value)
+	}
+	if response.ExpiresTime != nil {
+		expiresTime := response.ExpiresTime.AsTime()
+		content.ExpiresTime = &expiresTime
+	}
+	logger.V(1).Info("got secret data from backend plugin", "endpoint", p.endpoint, "files", len(content.Data),
+		"expiresTime", content.ExpiresTime)
+	return content, nil
+}
+
+func (p *PluginBackend) pluginPodInfo(addresses []pod_info.Address) *pluginv1alpha1.PodInfo {
+	pod := p.podInfo.Pod
+	podInfo := &pluginv1alpha1.PodInfo{
+		Name:               pod.Name,
+		Namespace:          pod.Namespace,
+		Uid:                string(pod.UID),
+		ServiceAccountName: pod.Spec.ServiceAccountName,
+		NodeName:           pod.Spec.NodeName,
+		Labels:             pod.Labels,
+		Annotations:        pod.Annotations,
+	}
+	for _, podIP := range pod.Status.PodIPs {
+		podInfo.PodIps = append(podInfo.PodIps, podIP.IP)
+	}
+	for _, address := range addresses {
+		pluginAddress := &pluginv1alpha1.Address{Hostname: address.Hostname}
+		if address.IP != nil {
+			pluginAddress.Ip = address.IP.String()
+		}
+		podInfo.ScopedAddresses = append(podInfo.ScopedAddresses, pluginAddress)
+	}
+	return podInfo
+}
+
+func (p *PluginBackend) pluginVolumeContext() *pluginv1alpha1.VolumeContext {
+	scope := p.volumeContext.Scope
+	return &pluginv1alpha1.VolumeContext{
+		Class: p.volumeContext.Class,
+		Scope: &pluginv1alpha1.Scope{
+			Pod:             scope.Pod != "",
+			Node:            scope.Node != "",
+			Services:        scope.Services,
+			ListenerVolumes: scope.ListenerVolumes,
+		},
+		Format:     string(p.volumeContext.Format),
+		Attributes: p.volumeContext.ToMap(),
+	}
+}
@@ -0,0 +1,86 @@
+package backend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	secretsv1alpha1 "github.com/zncdatadev/secret-operator/api/v1alpha1"
+	"github.com/zncdatadev/secret-operator/internal/csi/backend/ca"
+	"github.com/zncdatadev/secret-operator/pkg/pod_info"
+)
+
+func TestNewPluginBackendRequiresTLS(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		wantErr  bool
+	}{
+		{endpoint: "unix:///var/run/secret-operator/plugins/vault.sock"},
+		{endpoint: "dns:///vault-plugin.secret-operator.svc:9000", wantErr: true},
+		{endpoint: "vault-plugin.secret-operator.svc:9000", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			secretClass := &secretsv1alpha1.SecretClass{
+				ObjectMeta: metav1.ObjectMeta{Name: "vault"},
+				Spec: secretsv1alpha1.SecretClassSpec{Backend: &secretsv1alpha1.BackendSpec{
+					Plugin: &secretsv1alpha1.PluginSpec{Endpoint: tt.endpoint},
+				}},
+			}
+			_, err := NewPluginBackend(&BackendConfig{ctx: context.Background(), SecretClass: secretClass})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewPluginBackend() error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPluginTLSConfigClientCertificate(t *testing.T) {
+	notAfter := time.Now().Add(time.Hour)
+	authority, err := ca.NewSelfSignedCertificateAuthority(notAfter, nil, nil, 2048)
+	if err != nil {
+		t.Fatalf("NewSelfSignedCertificateAuthority() error = %v", err)
+	}
+	clientCert, err := authority.SignClientCertificate([]pod_info.Address{{Hostname: "csi"}}, notAfter)
+	if err != nil {
+		t.Fatalf("SignClientCertificate() error = %v", err)
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "plugin-ca", Namespace: "secret-operator"},
+		Data:       map[string]string{PluginCAConfigMapKey: string(authority.CertificatePEM())},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "plugin-client", Namespace: "secret-operator"},
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       clientCert.CertificatePEM(),
+			corev1.TLSPrivateKeyKey: clientCert.PrivateKeyPEM(),
+		},
+	}
+	c := fake.NewClientBuilder().WithObjects(configMap, secret).Build()
+
+	spec := &secretsv1alpha1.PluginTLSSpec{
+		CAConfigMap: &secretsv1alpha1.ConfigMapSpec{Name: "plugin-ca", Namespace: "secret-operator"},
+	}
+	_, serverOnlyHash, err := pluginTLSConfig(context.Background(), c, spec)
+	if err != nil {
+		t.Fatalf("pluginTLSConfig() error = %v", err)
+	}
+
+	spec.ClientCertSecret = &secretsv1alpha1.SecretSpec{Name: "plugin-client", Namespace: "secret-operator"}
+	tlsConfig, hash, err := pluginTLSConfig(context.Background(), c, spec)
+	if err != nil {
+		t.Fatalf("pluginTLSConfig() error = %v", err)
+	}
+	if len(tlsConfig.Certificates) != 1 {
+		t.Errorf("pluginTLSConfig() certificates = %d, want the client certificate", len(tlsConfig.Certificates))
+	}
+	// the connection is recreated when the client certificate is set or renewed
+	if hash == serverOnlyHash {
+		t.Error("pluginTLSConfig() hash does not change with the client certificate")
+	}
+}
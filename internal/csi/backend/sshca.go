@@ -0,0 +1,184 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/zncdatadev/secret-operator/internal/csi/backend/sshca"
+	"github.com/zncdatadev/secret-operator/internal/metrics"
+	"github.com/zncdatadev/secret-operator/pkg/pod_info"
+	"github.com/zncdatadev/secret-operator/pkg/util"
+	"github.com/zncdatadev/secret-operator/pkg/volume"
+)
+
+const (
+	SSHKnownHostsFileName  = "known_hosts"
+	SSHCAPublicKeyFileName = "ssh_ca.pub"
+
+	DefaultSSHCertificateLifeTime = 7 * 24 * time.Hour
+)
+
+var _ IBackend = &SSHCABackend{}
+
+type SSHCABackend struct {
+	client              client.Client
+	podInfo             *pod_info.PodInfo
+	volumeContext       *volume.SecretVolumeContext
+	certificateLifeTime time.Duration
+	userCertificate     bool
+	keyType             string
+	class               string
+
+	ca *sshca.CertificateAuthority
+}
+
+func NewSSHCABackend(config *BackendConfig) (IBackend, error) {
+	spec := config.SecretClass.Spec.Backend.SSHCA
+	if spec.CA == nil || spec.CA.Secret == nil {
+		return nil, errors.New("ca secret is nil in sshCa backend")
+	}
+
+	certificateLifeTime := DefaultSSHCertificateLifeTime
+	if spec.CertificateLifeTime != "" {
+		d, err := time.ParseDuration(spec.CertificateLifeTime)
+		if err != nil {
+			return nil, err
+		}
+		certificateLifeTime = d
+	}
+
+	ca, err := sshca.LoadCertificateAuthority(config.ctx, config.Client, spec.CA)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SSHCABackend{
+		client:              config.Client,
+		podInfo:             config.PodInfo,
+		volumeContext:       config.VolumeContext,
+		certificateLifeTime: certificateLifeTime,
+		userCertificate:     spec.UserCertificate,
+		keyType:             spec.CA.KeyType,
+		class:               config.SecretClass.Name,
+		ca:                  ca,
+	}, nil
+}
+
+func (s *SSHCABackend) GetQualifiedNodeNames(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
+// GetSecretData implements Backend.
+// It generates a host key with a certificate for the scoped addresses of the pod, and optionally
+// a user key with a certificate for the service account of the pod.
+func (s *SSHCABackend) GetSecretData(ctx context.Context) (*util.SecretContent, error) {
+	principals, err := s.getHostPrincipals(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(principals) == 0 {
+		return nil, errors.New("no scoped addresses for ssh host certificate, add node, pod, service or listener-volume scope")
+	}
+
+	pod := s.podInfo.Pod
+	notAfter := time.Now().Add(s.certificateLifeTime)
+	suffix := sshca.KeyFileSuffix(s.keyType)
+
+	data := map[string]string{
+		SSHKnownHostsFileName:  s.ca.KnownHostsLine(),
+		SSHCAPublicKeyFileName: string(sshca.MarshalPublicKey(s.ca.PublicKey())),
+	}
+
+	hostKeyID := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
+	if err := s.issue(data, "ssh_host_"+suffix+"_key", ssh.HostCert, hostKeyID, principals, notAfter); err != nil {
+		return nil, err
+	}
+
+	if s.userCertificate {
+		serviceAccount := pod.Spec.ServiceAccountName
+		if serviceAccount == "" {
+			serviceAccount = "default"
+		}
+		userKeyID := fmt.Sprintf("%s/%s", pod.Namespace, serviceAccount)
+		principal := SSHUserPrincipal(pod.Namespace, serviceAccount)
+		if err := s.issue(data, "id_"+suffix, ssh.UserCert, userKeyID, []string{principal}, notAfter); err != nil {
+			return nil, err
+		}
+	}
+
+	// restart the pod when 80% of the certificate lifetime has passed
+	restartAt := notAfter.Add(-s.certificateLifeTime / 5)
+
+	return &util.SecretContent{
+		Data:        data,
+		ExpiresTime: &restartAt,
+	}, nil
+}
+
+// SSHUserPrincipal returns the principal of the user certificate of a service account, `<namespace>.<serviceaccount>`.
+// Service accounts of the same name in other namespaces get other principals, so a host only authorizes the
+// namespaces it lists. Namespace names contain no dots, the principal is split at its first dot.
+func SSHUserPrincipal(namespace, serviceAccount string) string {
+	return namespace + "." + serviceAccount
+}
+
+// issue generates a key and its certificate, and adds them to data as `<name>`, `<name>.pub` and `<name>-cert.pub`.
+func (s *SSHCABackend) issue(
+	data map[string]string,
+	name string,
+	certType uint32,
+	keyID string,
+	principals []string,
+	notAfter time.Time,
+) error {
+	key, err := sshca.GenerateKey(s.keyType)
+	if err != nil {
+		return err
+	}
+	publicKey, err := ssh.NewPublicKey(key.Public())
+	if err != nil {
+		return err
+	}
+
+	cert, err := s.ca.Sign(publicKey, certType, keyID, principals, notAfter)
+	if err != nil {
+		return err
+	}
+	metrics.CertificatesIssued.WithLabelValues(string(SSHCAType), s.class).Inc()
+
+	privateKey, err := sshca.MarshalPrivateKey(key, keyID)
+	if err != nil {
+		return err
+	}
+
+	data[name] = string(privateKey)
+	data[name+".pub"] = string(sshca.MarshalPublicKey(publicKey))
+	data[name+"-cert.pub"] = string(sshca.MarshalPublicKey(cert))
+	return nil
+}
+
+// getHostPrincipals returns the hostnames and ip addresses of the scoped addresses.
+func (s *SSHCABackend) getHostPrincipals(ctx context.Context) ([]string, error) {
+	addresses, err := s.podInfo.GetScopedAddresses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	principals := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		principal := address.Hostname
+		if address.IP != nil {
+			principal = address.IP.String()
+		}
+		if principal != "" && !slices.Contains(principals, principal) {
+			principals = append(principals, principal)
+		}
+	}
+	return principals, nil
+}
@@ -0,0 +1,250 @@
+package sshca
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zncdatadev/operator-go/pkg/constants"
+	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	secretsv1alpha1 "github.com/zncdatadev/secret-operator/api/v1alpha1"
+)
+
+const (
+	KeyTypeEd25519 = "Ed25519"
+	KeyTypeECDSA   = "ECDSA"
+	KeyTypeRSA     = "RSA"
+
+	// CAPrivateKeyName and CAPublicKeyName are the keys of the certificate authority in the secret
+	CAPrivateKeyName = "ssh_ca"
+	CAPublicKeyName  = "ssh_ca.pub"
+
+	rsaKeyLength = 3072
+
+	// clockSkew backdates certificates, so hosts with a clock slightly behind accept new certificates
+	clockSkew = 5 * time.Minute
+)
+
+var (
+	logger = ctrl.Log.WithName("ssh-ca")
+)
+
+// GenerateKey generates a private key of the key type, Ed25519 if empty.
+func GenerateKey(keyType string) (crypto.Signer, error) {
+	switch keyType {
+	case KeyTypeEd25519, "":
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	case KeyTypeECDSA:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case KeyTypeRSA:
+		return rsa.GenerateKey(rand.Reader, rsaKeyLength)
+	}
+	return nil, fmt.Errorf("unsupported ssh key type %q", keyType)
+}
+
+// KeyFileSuffix returns the key type as used in OpenSSH key file names, e.g. `ssh_host_ed25519_key`.
+func KeyFileSuffix(keyType string) string {
+	if keyType == "" {
+		return "ed25519"
+	}
+	return strings.ToLower(keyType)
+}
+
+// MarshalPrivateKey returns the private key in OpenSSH PEM format.
+func MarshalPrivateKey(key crypto.Signer, comment string) ([]byte, error) {
+	block, err := ssh.MarshalPrivateKey(key, comment)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(block), nil
+}
+
+// MarshalPublicKey returns the public key in authorized_keys format, with a trailing newline.
+func MarshalPublicKey(key ssh.PublicKey) []byte {
+	return ssh.MarshalAuthorizedKey(key)
+}
+
+// CertificateAuthority signs OpenSSH host and user certificates.
+type CertificateAuthority struct {
+	signer ssh.Signer
+}
+
+// NewCertificateAuthority creates a certificate authority from its private key.
+// RSA keys sign with rsa-sha2-512, OpenSSH rejects certificates signed with ssh-rsa (SHA-1).
+func NewCertificateAuthority(key crypto.Signer) (*CertificateAuthority, error) {
+	signer, err := ssh.NewSignerFromSigner(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if signer.PublicKey().Type() == ssh.KeyAlgoRSA {
+		algorithmSigner, ok := signer.(ssh.AlgorithmSigner)
+		if !ok {
+			return nil, fmt.Errorf("rsa signer does not support signature algorithms")
+		}
+		if signer, err = ssh.NewSignerWithAlgorithms(algorithmSigner, []string{ssh.KeyAlgoRSASHA512}); err != nil {
+			return nil, err
+		}
+	}
+	return &CertificateAuthority{signer: signer}, nil
+}
+
+// ParseCertificateAuthority creates a certificate authority from an OpenSSH private key.
+func ParseCertificateAuthority(privateKeyPEM []byte) (*CertificateAuthority, error) {
+	key, err := ssh.ParseRawPrivateKey(privateKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	// ed25519 keys are parsed as pointer, which is not a crypto.Signer
+	if k, ok := key.(*ed25519.PrivateKey); ok {
+		key = *k
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported ssh certificate authority key %T", key)
+	}
+	return NewCertificateAuthority(signer)
+}
+
+func (c *CertificateAuthority) PublicKey() ssh.PublicKey {
+	return c.signer.PublicKey()
+}
+
+// KnownHostsLine returns a known_hosts line trusting host certificates of the certificate authority for all hosts.
+func (c *CertificateAuthority) KnownHostsLine() string {
+	return "@cert-authority * " + string(MarshalPublicKey(c.PublicKey()))
+}
+
+// Sign issues a certificate of the public key, valid for the principals until notAfter.
+// certType is ssh.HostCert or ssh.UserCert, user certificates get the default permissions of ssh-keygen.
+func (c *CertificateAuthority) Sign(
+	key ssh.PublicKey,
+	certType uint32,
+	keyID string,
+	principals []string,
+	notAfter time.Time,
+) (*ssh.Certificate, error) {
+	var serial [8]byte
+	if _, err := rand.Read(serial[:]); err != nil {
+		return nil, err
+	}
+
+	cert := &ssh.Certificate{
+		Key:             key,
+		Serial:          binary.BigEndian.Uint64(serial[:]),
+		CertType:        certType,
+		KeyId:           keyID,
+		ValidPrincipals: principals,
+		ValidAfter:      uint64(time.Now().Add(-clockSkew).Unix()),
+		ValidBefore:     uint64(notAfter.Unix()),
+	}
+	if certType == ssh.UserCert {
+		cert.Extensions = map[string]string{
+			"permit-X11-forwarding":   "",
+			"permit-agent-forwarding": "",
+			"permit-port-forwarding":  "",
+			"permit-pty":              "",
+			"permit-user-rc":          "",
+		}
+	}
+
+	if err := cert.SignCert(rand.Reader, c.signer); err != nil {
+		return nil, err
+	}
+	logger.V(1).Info("signed ssh certificate", "keyId", keyID, "serial", cert.Serial, "principals", principals, "notAfter", notAfter)
+	return cert, nil
+}
+
+// LoadCertificateAuthority gets the certificate authority from the secret of the spec.
+// If the secret has no certificate authority and auto generate is enabled, a new one is generated and saved.
+func LoadCertificateAuthority(ctx context.Context, c client.Client, spec *secretsv1alpha1.SSHCAKeySpec) (*CertificateAuthority, error) {
+	var ca *CertificateAuthority
+	err := retry.OnError(retry.DefaultRetry, func(err error) bool {
+		return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+	}, func() error {
+		secret := &corev1.Secret{}
+		if err := c.Get(ctx, client.ObjectKey{Name: spec.Secret.Name, Namespace: spec.Secret.Namespace}, secret); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      spec.Secret.Name,
+					Namespace: spec.Secret.Namespace,
+					Labels: map[string]string{
+						constants.LabelKubernetesManagedBy: "secret-operator",
+					},
+				},
+				Type: corev1.SecretTypeOpaque,
+			}
+		}
+
+		if privateKey, ok := secret.Data[CAPrivateKeyName]; ok {
+			var err error
+			ca, err = ParseCertificateAuthority(privateKey)
+			if err != nil {
+				return fmt.Errorf("invalid ssh certificate authority in secret %s/%s: %w", secret.Namespace, secret.Name, err)
+			}
+			return nil
+		}
+
+		if !spec.AutoGenerate {
+			return fmt.Errorf("could not find ssh certificate authority %q in secret %s/%s, and auto-generate is false, please create manually",
+				CAPrivateKeyName, spec.Secret.Namespace, spec.Secret.Name)
+		}
+
+		key, err := GenerateKey(spec.KeyType)
+		if err != nil {
+			return err
+		}
+		generated, err := NewCertificateAuthority(key)
+		if err != nil {
+			return err
+		}
+		privateKey, err := MarshalPrivateKey(key, "secret-operator ssh ca")
+		if err != nil {
+			return err
+		}
+
+		if secret.Data == nil {
+			secret.Data = make(map[string][]byte)
+		}
+		secret.Data[CAPrivateKeyName] = privateKey
+		secret.Data[CAPublicKeyName] = MarshalPublicKey(generated.PublicKey())
+
+		if secret.ResourceVersion == "" {
+			err = c.Create(ctx, secret)
+		} else {
+			err = c.Update(ctx, secret)
+		}
+		if err != nil {
+			return err
+		}
+		logger.Info("generated ssh certificate authority", "name", secret.Name, "namespace", secret.Namespace,
+			"keyType", spec.KeyType, "fingerprint", ssh.FingerprintSHA256(generated.PublicKey()))
+		ca = generated
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ca, nil
+}
@@ -0,0 +1,57 @@
+package sshca
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestSign(t *testing.T) {
+	for _, keyType := range []string{KeyTypeEd25519, KeyTypeECDSA, KeyTypeRSA} {
+		t.Run(keyType, func(t *testing.T) {
+			caKey, err := GenerateKey(keyType)
+			if err != nil {
+				t.Fatalf("GenerateKey() error = %v", err)
+			}
+			privateKey, err := MarshalPrivateKey(caKey, "test")
+			if err != nil {
+				t.Fatalf("MarshalPrivateKey() error = %v", err)
+			}
+			ca, err := ParseCertificateAuthority(privateKey)
+			if err != nil {
+				t.Fatalf("ParseCertificateAuthority() error = %v", err)
+			}
+
+			hostKey, err := GenerateKey(keyType)
+			if err != nil {
+				t.Fatalf("GenerateKey() error = %v", err)
+			}
+			publicKey, err := ssh.NewPublicKey(hostKey.Public())
+			if err != nil {
+				t.Fatalf("NewPublicKey() error = %v", err)
+			}
+
+			principals := []string{"web-0.web.default.svc.cluster.local", "10.244.0.5"}
+			cert, err := ca.Sign(publicKey, ssh.HostCert, "default/web-0", principals, time.Now().Add(time.Hour))
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			if keyType == KeyTypeRSA && cert.Signature.Format != ssh.KeyAlgoRSASHA512 {
+				t.Errorf("Sign() signature format = %s, want %s", cert.Signature.Format, ssh.KeyAlgoRSASHA512)
+			}
+
+			checker := &ssh.CertChecker{
+				IsHostAuthority: func(auth ssh.PublicKey, _ string) bool {
+					return string(auth.Marshal()) == string(ca.PublicKey().Marshal())
+				},
+			}
+			if err := checker.CheckCert(principals[0], cert); err != nil {
+				t.Errorf("CheckCert() error = %v", err)
+			}
+			if err := checker.CheckCert("other.example.com", cert); err == nil {
+				t.Error("CheckCert() expected error for a host not in the principals")
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package backend
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/zncdatadev/secret-operator/internal/csi/backend/sshca"
+	"github.com/zncdatadev/secret-operator/pkg/pod_info"
+	"github.com/zncdatadev/secret-operator/pkg/volume"
+)
+
+func TestSSHCAUserCertificatePrincipal(t *testing.T) {
+	caKey, err := sshca.GenerateKey(sshca.KeyTypeEd25519)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	authority, err := sshca.NewCertificateAuthority(caKey)
+	if err != nil {
+		t.Fatalf("NewCertificateAuthority() error = %v", err)
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup-0", Namespace: "team-a"},
+		Spec:       corev1.PodSpec{ServiceAccountName: "backup"},
+		Status:     corev1.PodStatus{PodIPs: []corev1.PodIP{{IP: "10.244.0.5"}}},
+	}
+	backend := &SSHCABackend{
+		podInfo:             pod_info.NewPodInfo(nil, pod, &volume.SecretScope{Pod: volume.ScopePod}),
+		certificateLifeTime: time.Hour,
+		userCertificate:     true,
+		keyType:             sshca.KeyTypeEd25519,
+		class:               "ssh",
+		ca:                  authority,
+	}
+
+	content, err := backend.GetSecretData(context.Background())
+	if err != nil {
+		t.Fatalf("GetSecretData() error = %v", err)
+	}
+
+	publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(content.Data["id_ed25519-cert.pub"]))
+	if err != nil {
+		t.Fatalf("ParseAuthorizedKey() error = %v", err)
+	}
+	cert, ok := publicKey.(*ssh.Certificate)
+	if !ok {
+		t.Fatalf("id_ed25519-cert.pub is %T, want a certificate", publicKey)
+	}
+	if cert.CertType != ssh.UserCert {
+		t.Errorf("certificate type = %d, want user certificate", cert.CertType)
+	}
+	if want := []string{"team-a.backup"}; !slices.Equal(cert.ValidPrincipals, want) {
+		t.Errorf("certificate principals = %v, want %v", cert.ValidPrincipals, want)
+	}
+	if cert.KeyId != "team-a/backup" {
+		t.Errorf("certificate key id = %s, want team-a/backup", cert.KeyId)
+	}
+}
@@ -5,11 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"time"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	"github.com/go-logr/logr"
 	"github.com/zncdatadev/operator-go/pkg/constants"
+	secretsv1alpha1 "github.com/zncdatadev/secret-operator/api/v1alpha1"
 	"github.com/zncdatadev/secret-operator/internal/csi/backend"
+	"github.com/zncdatadev/secret-operator/internal/metrics"
 	"github.com/zncdatadev/secret-operator/pkg/pod_info"
 	"github.com/zncdatadev/secret-operator/pkg/volume"
 	"google.golang.org/grpc/codes"
@@ -51,7 +54,13 @@ func NewControllerServer(client client.Client) *ControllerServer {
 	return &ControllerServer{client: client, logger: logger}
 }
 
-func (c *ControllerServer) CreateVolume(ctx context.Context, request *csi.CreateVolumeRequest) (*csi.CreateVolumeResponse, error) {
+func (c *ControllerServer) CreateVolume(ctx context.Context, request *csi.CreateVolumeRequest) (_ *csi.CreateVolumeResponse, err error) {
+	start := time.Now()
+	var backendType, className string
+	defer func() {
+		metrics.ObserveVolumeOperation(metrics.OperationCreateVolume, backendType, className, start, err)
+	}()
+
 	if err := validateCreateVolumeRequest(request); err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
@@ -79,6 +88,13 @@ func (c *ControllerServer) CreateVolume(ctx context.Context, request *csi.Create
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
+	className = volumeContext.Class
+
+	secretClass := &secretsv1alpha1.SecretClass{}
+	if err := c.client.Get(ctx, client.ObjectKey{Name: volumeContext.Class}, secretClass); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	backendType = string(backend.DetermineBackendType(secretClass))
 
 	// get accessible topology
 	accessibleTopology, err := c.getAssibleTopology(ctx, pvc, volumeContext)
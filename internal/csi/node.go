@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"time"
@@ -21,6 +22,7 @@ import (
 	"github.com/zncdatadev/operator-go/pkg/constants"
 	secretsv1alpha1 "github.com/zncdatadev/secret-operator/api/v1alpha1"
 	secretbackend "github.com/zncdatadev/secret-operator/internal/csi/backend"
+	"github.com/zncdatadev/secret-operator/internal/metrics"
 
 	"github.com/zncdatadev/secret-operator/pkg/pod_info"
 	"github.com/zncdatadev/secret-operator/pkg/volume"
@@ -47,7 +49,13 @@ func NewNodeServer(
 	}
 }
 
-func (n *NodeServer) NodePublishVolume(ctx context.Context, request *csi.NodePublishVolumeRequest) (*csi.NodePublishVolumeResponse, error) {
+func (n *NodeServer) NodePublishVolume(ctx context.Context, request *csi.NodePublishVolumeRequest) (_ *csi.NodePublishVolumeResponse, err error) {
+	start := time.Now()
+	var backendType, className string
+	defer func() {
+		metrics.ObserveVolumeOperation(metrics.OperationNodePublishVolume, backendType, className, start, err)
+	}()
+
 	// check requests
 	// 	- volume ID missing in request
 	// 	- target path missing in request
@@ -84,6 +92,7 @@ func (n *NodeServer) NodePublishVolume(ctx context.Context, request *csi.NodePub
 	if volumeContext.Class == "" {
 		return nil, status.Error(codes.InvalidArgument, "Secret class name missing in request")
 	}
+	className = volumeContext.Class
 
 	secretClass := &secretsv1alpha1.SecretClass{}
 	// get the secret class
@@ -93,6 +102,7 @@ func (n *NodeServer) NodePublishVolume(ctx context.Context, request *csi.NodePub
 	}, secretClass); err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
+	backendType = string(secretbackend.DetermineBackendType(secretClass))
 
 	// Validate cross-namespace references in SecretClass spec.
 	// This prevents a Pod in namespace A from reading secrets/configmaps
@@ -122,6 +132,11 @@ func (n *NodeServer) NodePublishVolume(ctx context.Context, request *csi.NodePub
 	// get the secret data
 	backend, err := secretbackend.NewBackend(ctx, n.client, podInfo, volumeContext)
 	if err != nil {
+		// composite backends check their secret classes against the cross-namespace policy
+		var validationErr *secretbackend.NamespaceValidationError
+		if errors.As(err, &validationErr) {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 	secretContent, err := backend.GetSecretData(ctx)
@@ -144,6 +159,10 @@ func (n *NodeServer) NodePublishVolume(ctx context.Context, request *csi.NodePub
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
+	if secretContent.ExpiresTime != nil {
+		metrics.SecretTimeToRestart.WithLabelValues(backendType, className).Observe(time.Until(*secretContent.ExpiresTime).Seconds())
+	}
+
 	logger.Info("volume published", "volumeID", volumeID, "targetPath", targetPath)
 	return &csi.NodePublishVolumeResponse{}, nil
 }
@@ -187,10 +206,19 @@ func (n *NodeServer) updatePod(ctx context.Context, pod *corev1.Pod, volumeID st
 // writeData writes the data to the target path.
 // The data is a map of key-value pairs.
 // The key is the file name, and the value is the file content.
+// A file name may contain subdirectories, e.g. from a composite backend, which are created.
 func (n *NodeServer) writeData(targetPath string, data map[string]string) error {
 	logger.V(1).Info("writing data", "target", targetPath)
 	for name, content := range data {
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("file name %q is not a relative path within the volume", name)
+		}
 		fileName := filepath.Join(targetPath, name)
+		if dir := filepath.Dir(fileName); dir != targetPath {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return err
+			}
+		}
 		if err := os.WriteFile(fileName, []byte(content), fs.FileMode(0644)); err != nil {
 			return err
 		}
@@ -0,0 +1,124 @@
+// Package metrics defines the Prometheus metrics of the secret-operator. They are registered in the
+// controller-runtime registry, so the manager of each binary serves them on its metrics-bind-address.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/status"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const namespace = "secret_operator"
+
+// Operations of VolumeOperationDuration and VolumeOperationErrors.
+const (
+	OperationNodePublishVolume = "NodePublishVolume"
+	OperationCreateVolume      = "CreateVolume"
+)
+
+// Operations of KadminInvocations and KadminFailures.
+const (
+	KadminOperationProvision = "provision"
+	KadminOperationRekey     = "rekey"
+	KadminOperationDelete    = "delete"
+)
+
+var (
+	// VolumeOperationDuration is the latency of csi volume operations, by backend and SecretClass.
+	VolumeOperationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "volume_operation_duration_seconds",
+		Help:      "Duration of csi volume operations by operation, backend and SecretClass.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"operation", "backend", "secret_class"})
+
+	// VolumeOperationErrors counts failed csi volume operations, by grpc status code.
+	VolumeOperationErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "volume_operation_errors_total",
+		Help:      "Failed csi volume operations by operation, backend, SecretClass and grpc status code.",
+	}, []string{"operation", "backend", "secret_class", "code"})
+
+	// CertificatesIssued counts the certificates signed for volumes.
+	CertificatesIssued = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "certificates_issued_total",
+		Help:      "Certificates issued for volumes by backend and SecretClass.",
+	}, []string{"backend", "secret_class"})
+
+	// KadminInvocations counts the kadmin sessions started by kerberos backends.
+	KadminInvocations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kadmin_invocations_total",
+		Help:      "Kadmin sessions by SecretClass and operation.",
+	}, []string{"secret_class", "operation"})
+
+	// KadminFailures counts the kadmin sessions which failed.
+	KadminFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kadmin_failures_total",
+		Help:      "Failed kadmin sessions by SecretClass and operation.",
+	}, []string{"secret_class", "operation"})
+
+	// K8sSearchMisses counts the k8sSearch volumes no object matched, including volumes of generated secrets.
+	K8sSearchMisses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "k8s_search_misses_total",
+		Help:      "K8sSearch volumes without a matching Secret or ConfigMap by SecretClass.",
+	}, []string{"secret_class"})
+
+	// CACertificateNotAfter is the expiry of the certificate authorities of AutoTls SecretClasses,
+	// exported by the ca metrics controller of the csi controller.
+	CACertificateNotAfter = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ca_certificate_not_after_timestamp_seconds",
+		Help:      "Expiry of the certificate authorities by SecretClass and serial number, as unix timestamp.",
+	}, []string{"secret_class", "serial_number"})
+
+	// SecretTimeToRestart is the time between publishing a volume and the restart of its pod for fresh secrets.
+	SecretTimeToRestart = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "secret_time_to_restart_seconds",
+		Help:      "Time from publishing a volume until its pod is restarted for fresh secrets, by backend and SecretClass.",
+		Buckets:   prometheus.ExponentialBuckets(3600, 2, 12),
+	}, []string{"backend", "secret_class"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		VolumeOperationDuration,
+		VolumeOperationErrors,
+		CertificatesIssued,
+		KadminInvocations,
+		KadminFailures,
+		K8sSearchMisses,
+		CACertificateNotAfter,
+		SecretTimeToRestart,
+	)
+}
+
+// ObserveVolumeOperation records the duration of a volume operation started at start, and err when it failed.
+func ObserveVolumeOperation(operation, backend, secretClass string, start time.Time, err error) {
+	VolumeOperationDuration.WithLabelValues(operation, backend, secretClass).Observe(time.Since(start).Seconds())
+	if err != nil {
+		VolumeOperationErrors.WithLabelValues(operation, backend, secretClass, status.Code(err).String()).Inc()
+	}
+}
+
+// ObserveKadmin records a kadmin session of the SecretClass, and err when it failed.
+func ObserveKadmin(secretClass, operation string, err error) {
+	KadminInvocations.WithLabelValues(secretClass, operation).Inc()
+	if err != nil {
+		KadminFailures.WithLabelValues(secretClass, operation).Inc()
+	}
+}
+
+// SetCACertificateNotAfter replaces the expiry of the certificate authorities of the SecretClass.
+func SetCACertificateNotAfter(secretClass string, notAfter map[string]time.Time) {
+	CACertificateNotAfter.DeletePartialMatch(prometheus.Labels{"secret_class": secretClass})
+	for serialNumber, t := range notAfter {
+		CACertificateNotAfter.WithLabelValues(secretClass, serialNumber).Set(float64(t.Unix()))
+	}
+}
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestObserveVolumeOperation(t *testing.T) {
+	ObserveVolumeOperation(OperationNodePublishVolume, "AutoTls", "test-tls", time.Now(), nil)
+	ObserveVolumeOperation(OperationNodePublishVolume, "AutoTls", "test-tls", time.Now(), status.Error(codes.PermissionDenied, "denied"))
+	ObserveVolumeOperation(OperationNodePublishVolume, "AutoTls", "test-tls", time.Now(), errors.New("failed"))
+
+	if got := testutil.CollectAndCount(VolumeOperationDuration, "secret_operator_volume_operation_duration_seconds"); got != 1 {
+		t.Errorf("volume operation duration series = %d, want 1", got)
+	}
+	for code, want := range map[string]float64{"PermissionDenied": 1, "Unknown": 1} {
+		counter := VolumeOperationErrors.WithLabelValues(OperationNodePublishVolume, "AutoTls", "test-tls", code)
+		if got := testutil.ToFloat64(counter); got != want {
+			t.Errorf("volume operation errors with code %s = %v, want %v", code, got, want)
+		}
+	}
+}
+
+func TestSetCACertificateNotAfter(t *testing.T) {
+	now := time.Now()
+	SetCACertificateNotAfter("test-ca", map[string]time.Time{"01": now, "02": now.Add(time.Hour)})
+	SetCACertificateNotAfter("test-ca", map[string]time.Time{"02": now.Add(time.Hour), "03": now.Add(2 * time.Hour)})
+
+	if got := testutil.CollectAndCount(CACertificateNotAfter); got != 2 {
+		t.Errorf("ca certificate not after series = %d, want 2 without the removed certificate authority", got)
+	}
+	if got := testutil.ToFloat64(CACertificateNotAfter.WithLabelValues("test-ca", "03")); got != float64(now.Add(2*time.Hour).Unix()) {
+		t.Errorf("ca certificate not after = %v, want %v", got, now.Add(2*time.Hour).Unix())
+	}
+}
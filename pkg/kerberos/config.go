@@ -3,9 +3,12 @@ package kerberos
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
 	"os"
 	"path"
 	"strings"
+
+	"github.com/zncdatadev/secret-operator/pkg/util"
 )
 
 /*
@@ -45,37 +48,118 @@ type Krb5Config struct {
 	AdminServer string
 	KDC         string
 
+	// AdditionalKDCs are listed after KDC, clients fail over to them in order.
+	AdditionalKDCs     []string
+	MasterKDC          string
+	KpasswdServer      string
+	PermittedEnctypes  []string
+	TicketLifetime     string
+	RenewLifetime      string
+	DNSLookupKDC       *bool
+	UDPPreferenceLimit *int
+
+	// Realms are additional realms, e.g. for cross-realm trust.
+	Realms []Realm
+	// DomainRealms are additional [domain_realm] mappings, appended after the cluster domain.
+	DomainRealms []DomainRealm
+	// ClusterDomain is mapped to the realm in [domain_realm], `cluster.local` if empty.
+	ClusterDomain string
+
 	hashed string
 }
 
+type Realm struct {
+	Name        string
+	KDCs        []string
+	AdminServer string
+}
+
+type DomainRealm struct {
+	Domain string
+	Realm  string
+}
+
 func (c *Krb5Config) GetRealm() string {
 	return strings.ToUpper(c.Realm)
 }
 
+func (c *Krb5Config) getClusterDomain() string {
+	if c.ClusterDomain == "" {
+		return util.DefaultClusterDomain
+	}
+	return c.ClusterDomain
+}
+
+func (c *Krb5Config) getDNSLookupKDC() bool {
+	if c.DNSLookupKDC == nil {
+		return true
+	}
+	return *c.DNSLookupKDC
+}
+
+func (c *Krb5Config) getUDPPreferenceLimit() int {
+	if c.UDPPreferenceLimit == nil {
+		return 1
+	}
+	return *c.UDPPreferenceLimit
+}
+
 // ref: https://web.mit.edu/kerberos/krb5-latest/doc/admin/conf_files/krb5_conf.html#sample-krb5-conf-file
 func (c *Krb5Config) Content() string {
-	content := `# krb5.conf generated by secrets.kubedoop.dev
-# It will be overwritten by the secret-operator
-
-[libdefaults]
-  default_realm = ` + c.GetRealm() + `
-  dns_lookup_realm = false
-  dns_lookup_kdc = true
-  rdns = false
-  udp_preference_limit = 1
+	var b strings.Builder
+
+	b.WriteString("# krb5.conf generated by secrets.kubedoop.dev\n")
+	b.WriteString("# It will be overwritten by the secret-operator\n\n")
+
+	b.WriteString("[libdefaults]\n")
+	fmt.Fprintf(&b, "  default_realm = %s\n", c.GetRealm())
+	b.WriteString("  dns_lookup_realm = false\n")
+	fmt.Fprintf(&b, "  dns_lookup_kdc = %t\n", c.getDNSLookupKDC())
+	b.WriteString("  rdns = false\n")
+	fmt.Fprintf(&b, "  udp_preference_limit = %d\n", c.getUDPPreferenceLimit())
+	if c.TicketLifetime != "" {
+		fmt.Fprintf(&b, "  ticket_lifetime = %s\n", c.TicketLifetime)
+	}
+	if c.RenewLifetime != "" {
+		fmt.Fprintf(&b, "  renew_lifetime = %s\n", c.RenewLifetime)
+	}
+	if len(c.PermittedEnctypes) > 0 {
+		fmt.Fprintf(&b, "  permitted_enctypes = %s\n", strings.Join(c.PermittedEnctypes, " "))
+	}
 
-[realms]
-  ` + c.GetRealm() + ` = {
-    kdc = ` + c.KDC + `
-    admin_server = ` + c.AdminServer + `
-  }
+	b.WriteString("\n[realms]\n")
+	fmt.Fprintf(&b, "  %s = {\n", c.GetRealm())
+	for _, kdc := range append([]string{c.KDC}, c.AdditionalKDCs...) {
+		fmt.Fprintf(&b, "    kdc = %s\n", kdc)
+	}
+	if c.MasterKDC != "" {
+		fmt.Fprintf(&b, "    master_kdc = %s\n", c.MasterKDC)
+	}
+	if c.KpasswdServer != "" {
+		fmt.Fprintf(&b, "    kpasswd_server = %s\n", c.KpasswdServer)
+	}
+	fmt.Fprintf(&b, "    admin_server = %s\n", c.AdminServer)
+	b.WriteString("  }\n")
+	for _, realm := range c.Realms {
+		fmt.Fprintf(&b, "  %s = {\n", strings.ToUpper(realm.Name))
+		for _, kdc := range realm.KDCs {
+			fmt.Fprintf(&b, "    kdc = %s\n", kdc)
+		}
+		if realm.AdminServer != "" {
+			fmt.Fprintf(&b, "    admin_server = %s\n", realm.AdminServer)
+		}
+		b.WriteString("  }\n")
+	}
 
-[domain_realm]
-  cluster.local = ` + c.GetRealm() + `
-  .cluster.local = ` + c.GetRealm() + `
+	b.WriteString("\n[domain_realm]\n")
+	fmt.Fprintf(&b, "  %s = %s\n", c.getClusterDomain(), c.GetRealm())
+	fmt.Fprintf(&b, "  .%s = %s\n", c.getClusterDomain(), c.GetRealm())
+	for _, domainRealm := range c.DomainRealms {
+		fmt.Fprintf(&b, "  %s = %s\n", domainRealm.Domain, strings.ToUpper(domainRealm.Realm))
+	}
+	b.WriteString("\n")
 
-`
-	return content
+	return b.String()
 }
 
 // Save write krb5.conf file
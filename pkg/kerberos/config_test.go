@@ -0,0 +1,81 @@
+package kerberos
+
+import (
+	"strings"
+	"testing"
+)
+
+const (
+	testRealm       = "EXAMPLE.COM"
+	testKDC         = "kdc.example.com:88"
+	testAdminServer = "kdc.example.com:749"
+)
+
+func TestKrb5ConfigContentDefault(t *testing.T) {
+	c := &Krb5Config{Realm: "example.com", KDC: testKDC, AdminServer: testAdminServer}
+
+	want := `# krb5.conf generated by secrets.kubedoop.dev
+# It will be overwritten by the secret-operator
+
+[libdefaults]
+  default_realm = EXAMPLE.COM
+  dns_lookup_realm = false
+  dns_lookup_kdc = true
+  rdns = false
+  udp_preference_limit = 1
+
+[realms]
+  EXAMPLE.COM = {
+    kdc = kdc.example.com:88
+    admin_server = kdc.example.com:749
+  }
+
+[domain_realm]
+  cluster.local = EXAMPLE.COM
+  .cluster.local = EXAMPLE.COM
+
+`
+	if got := c.Content(); got != want {
+		t.Errorf("Content() = %q, want %q", got, want)
+	}
+}
+
+func TestKrb5ConfigContentCustom(t *testing.T) {
+	dnsLookupKDC := false
+	udpPreferenceLimit := 0
+	c := &Krb5Config{
+		Realm:              testRealm,
+		KDC:                testKDC,
+		AdminServer:        testAdminServer,
+		AdditionalKDCs:     []string{"kdc2.example.com:88"},
+		MasterKDC:          "kdc.example.com",
+		KpasswdServer:      "kdc.example.com:464",
+		PermittedEnctypes:  []string{"aes256-cts-hmac-sha1-96", "aes128-cts-hmac-sha1-96"},
+		TicketLifetime:     "10h",
+		RenewLifetime:      "7d",
+		DNSLookupKDC:       &dnsLookupKDC,
+		UDPPreferenceLimit: &udpPreferenceLimit,
+		Realms:             []Realm{{Name: "corp.example.com", KDCs: []string{"ad.corp.example.com"}}},
+		DomainRealms:       []DomainRealm{{Domain: ".corp.example.com", Realm: "corp.example.com"}},
+		ClusterDomain:      "k8s.example.com",
+	}
+
+	content := c.Content()
+	for _, line := range []string{
+		"  dns_lookup_kdc = false\n",
+		"  udp_preference_limit = 0\n",
+		"  ticket_lifetime = 10h\n",
+		"  renew_lifetime = 7d\n",
+		"  permitted_enctypes = aes256-cts-hmac-sha1-96 aes128-cts-hmac-sha1-96\n",
+		"    kdc = kdc.example.com:88\n    kdc = kdc2.example.com:88\n",
+		"    master_kdc = kdc.example.com\n",
+		"    kpasswd_server = kdc.example.com:464\n",
+		"  CORP.EXAMPLE.COM = {\n    kdc = ad.corp.example.com\n  }\n",
+		"  k8s.example.com = EXAMPLE.COM\n  .k8s.example.com = EXAMPLE.COM\n",
+		"  .corp.example.com = CORP.EXAMPLE.COM\n",
+	} {
+		if !strings.Contains(content, line) {
+			t.Errorf("Content() does not contain %q, got:\n%s", line, content)
+		}
+	}
+}
@@ -0,0 +1,357 @@
+package kerberos
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// DefaultHeimdalKadminCommand is the default Heimdal kadmin binary.
+	// Most distributions install Heimdal kadmin as kadmin.heimdal to avoid conflicting with MIT kerberos.
+	DefaultHeimdalKadminCommand = "kadmin.heimdal"
+)
+
+var heimdalKadminCommand = DefaultHeimdalKadminCommand
+
+// SetHeimdalKadminCommand sets the name or path of the Heimdal kadmin binary.
+// The csi-plugin image only ships MIT kerberos, Heimdal admin servers need an image with the Heimdal client tools.
+// It must be called before any kadmin session is started.
+func SetHeimdalKadminCommand(command string) {
+	if command == "" {
+		command = DefaultHeimdalKadminCommand
+	}
+	heimdalKadminCommand = command
+}
+
+var _ AdminClient = &HeimdalKadmin{}
+
+// heimdalAttributes maps MIT principal flags to heimdal attributes.
+// MIT "allow" flags are set by default, heimdal has the inverted "disallow" attributes instead.
+var heimdalAttributes = map[string]struct {
+	attribute string
+	inverted  bool
+}{
+	"requires_preauth":          {attribute: "requires-pre-auth"},
+	"requires_hwauth":           {attribute: "requires-hw-auth"},
+	"ok_as_delegate":            {attribute: "ok-as-delegate"},
+	"ok_to_auth_as_delegate":    {attribute: "trusted-for-delegation"},
+	"password_changing_service": {attribute: "pwchange-service"},
+	"no_auth_data_required":     {attribute: "no-auth-data-reqd"},
+	"allow_postdated":           {attribute: "disallow-postdated", inverted: true},
+	"allow_forwardable":         {attribute: "disallow-forwardable", inverted: true},
+	"allow_tgs_req":             {attribute: "disallow-tgt-based", inverted: true},
+	"allow_renewable":           {attribute: "disallow-renewable", inverted: true},
+	"allow_proxiable":           {attribute: "disallow-proxiable", inverted: true},
+	"allow_dup_skey":            {attribute: "disallow-dup-skey", inverted: true},
+	"allow_tix":                 {attribute: "disallow-all-tix", inverted: true},
+	"allow_svr":                 {attribute: "disallow-svr", inverted: true},
+}
+
+// HeimdalKadmin is the Heimdal kerberos kadmin client.
+// Heimdal kadmin accepts the command as trailing arguments instead of "-q",
+// and uses different sub commands than MIT kerberos:
+//   - "add --random-key" instead of "addprinc -randkey"
+//   - "ext_keytab" instead of "ktadd -norandkey", ext_keytab never changes the keys of the principal
+//
+// Encryption types are not supported, new keys get the default_keys of the kadmin server.
+type HeimdalKadmin struct {
+	*Kadmin
+}
+
+func NewHeimdalKadmin(
+	krb5Config *Krb5Config,
+	adminPrincipal *string,
+	adminKeytab []byte,
+) *HeimdalKadmin {
+	return &HeimdalKadmin{Kadmin: NewKadmin(krb5Config, adminPrincipal, adminKeytab)}
+}
+
+func (k *HeimdalKadmin) baseArgs(adminKeytabPath string) []string {
+	return []string{"-K", adminKeytabPath, "-p", *k.GetAdminPrincipal(), "-r", k.krb5Config.GetRealm()}
+}
+
+// Query executes a heimdal kadmin command
+// Example:
+//
+//	kadmin.heimdal -K admin.keytab -p admin/admin -r EXAMPLE.COM list '*'
+//	kadmin.heimdal -K admin.keytab -p admin/admin -r EXAMPLE.COM ext_keytab -k user1.keytab user1
+//
+// The output is returned even if the command fails, so the caller can inspect the reason.
+func (k *HeimdalKadmin) Query(args ...string) (result string, err error) {
+	return k.execute(heimdalKadminCommand, func(adminKeytabPath string) []string {
+		return append(k.baseArgs(adminKeytabPath), args...)
+	}, "")
+}
+
+// Batch executes kadmin commands in one kadmin session, one command per line of stdin.
+func (k *HeimdalKadmin) Batch(commands ...string) (result string, err error) {
+	return k.execute(heimdalKadminCommand, k.baseArgs, strings.Join(commands, "\n")+"\n")
+}
+
+func extKeytabArgs(keytab string, principals []string) []string {
+	args := make([]string, 0, 3+len(principals))
+	args = append(args, "ext_keytab", "-k", keytab)
+	return append(args, principals...)
+}
+
+// heimdalAttributeArgs returns the options of heimdal kadmin add and modify.
+// Heimdal applies "+attribute" and "-attribute" to the current attributes of the principal.
+func heimdalAttributeArgs(a *PrincipalAttributes) ([]string, error) {
+	if a.isEmpty() {
+		return nil, nil
+	}
+
+	args := make([]string, 0)
+	if a.Policy != "" {
+		args = append(args, "--policy="+a.Policy)
+	}
+	if a.MaxLife != 0 {
+		args = append(args, fmt.Sprintf("--max-ticket-life=%ds", int64(a.MaxLife.Seconds())))
+	}
+	if a.MaxRenewLife != 0 {
+		args = append(args, fmt.Sprintf("--max-renewable-life=%ds", int64(a.MaxRenewLife.Seconds())))
+	}
+
+	attributes := make([]string, 0, len(a.Flags))
+	for _, flag := range a.Flags {
+		set := !strings.HasPrefix(flag, "-")
+		mapping, ok := heimdalAttributes[strings.TrimLeft(flag, "+-")]
+		if !ok {
+			return nil, fmt.Errorf("principal flag %q is not supported by heimdal", flag)
+		}
+		if mapping.inverted {
+			set = !set
+		}
+		if set {
+			attributes = append(attributes, "+"+mapping.attribute)
+		} else {
+			attributes = append(attributes, "-"+mapping.attribute)
+		}
+	}
+	if len(attributes) > 0 {
+		args = append(args, "--attributes="+strings.Join(attributes, ","))
+	}
+	return args, nil
+}
+
+func addArgs(principal string, attributeArgs []string) []string {
+	args := append([]string{"add", "--random-key", "--use-defaults"}, attributeArgs...)
+	return append(args, principal)
+}
+
+func modifyArgs(principal string, attributeArgs []string) []string {
+	args := append([]string{"modify"}, attributeArgs...)
+	return append(args, principal)
+}
+
+func cpwArgs(principal string) []string {
+	return []string{"cpw", "--random-key", principal}
+}
+
+func deleteArgs(principal string) []string {
+	return []string{"delete", principal}
+}
+
+// Ktadd generates a keytab file for the given principals with the current keys
+// Usage: ext_keytab [-k keytab] principal...
+func (k *HeimdalKadmin) Ktadd(principals ...string) ([]byte, error) {
+	keytab := newKeytabPath()
+	defer func() {
+		if err := os.RemoveAll(keytab); err != nil {
+			logger.Error(err, "Failed to remove keytab")
+		}
+	}()
+
+	output, err := k.Query(extKeytabArgs(keytab, principals)...)
+	if err != nil {
+		logger.Error(err, "Failed to save keytab", "principals", principals, "keytab", keytab)
+		return nil, err
+	}
+
+	logger.V(1).Info("saved keytab", "principal", principals, "keytab", keytab, "output", output)
+
+	return os.ReadFile(keytab)
+}
+
+// AddPrincipal adds a new principal with a random key
+// Unlike MIT kerberos, heimdal kadmin exits with non-zero code when the principal already exists,
+// so the error is ignored in that case.
+// usage: add --random-key --use-defaults principal
+func (k *HeimdalKadmin) AddPrincipal(principal string) error {
+	unlock := principalLocks.Lock(principal)
+	defer unlock()
+
+	attributeArgs, err := heimdalAttributeArgs(k.attributes)
+	if err != nil {
+		return err
+	}
+
+	// Existing output:
+	// 	kadmin: kadm5_create_principal: Principal or policy already exists
+	output, err := k.Query(addArgs(principal, attributeArgs)...)
+	if err != nil {
+		if !strings.Contains(output, "already exists") {
+			logger.Error(err, "Failed to add principal", "principal", principal)
+			return err
+		}
+		logger.V(1).Info("principal already exists", "principal", principal)
+
+		if len(attributeArgs) > 0 {
+			if output, err = k.Query(modifyArgs(principal, attributeArgs)...); err != nil {
+				logger.Error(err, "Failed to modify principal", "principal", principal, "output", output)
+				return err
+			}
+		}
+		return nil
+	}
+
+	logger.V(1).Info("created a new principal", "principal", principal, "output", output)
+	return nil
+}
+
+// ProvisionKeytab adds the principals and extracts their keys in one kadmin session.
+// Heimdal does not name the principal in the output of add, so every principal is looked up with get
+// before it is added in the same session, the principals which did not exist are the created ones.
+func (k *HeimdalKadmin) ProvisionKeytab(principals ...string) ([]byte, []string, error) {
+	unlock := principalLocks.Lock(principals...)
+	defer unlock()
+
+	keytab := newKeytabPath()
+	defer func() {
+		if err := os.RemoveAll(keytab); err != nil {
+			logger.Error(err, "Failed to remove keytab")
+		}
+	}()
+
+	attributeArgs, err := heimdalAttributeArgs(k.attributes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	commands := make([]string, 0, 3*len(principals)+1)
+	for _, principal := range principals {
+		commands = append(commands, "get "+principal)
+		commands = append(commands, strings.Join(addArgs(principal, attributeArgs), " "))
+		// add does not modify existing principals
+		if len(attributeArgs) > 0 {
+			commands = append(commands, strings.Join(modifyArgs(principal, attributeArgs), " "))
+		}
+	}
+	commands = append(commands, strings.Join(extKeytabArgs(keytab, principals), " "))
+
+	// heimdal kadmin reports the error of an existing principal and continues with the next command,
+	// a missing key is detected when verifying the keytab.
+	output, err := k.Batch(commands...)
+	if err != nil {
+		logger.Error(err, "Failed to provision keytab", "principals", principals, "keytab", keytab, "output", output)
+		return nil, nil, err
+	}
+
+	existing := parseKeyVersions(output, "Principal:", heimdalKeyVersion)
+	created := make([]string, 0)
+	for _, principal := range principals {
+		if _, ok := existing[principal]; !ok {
+			created = append(created, principal)
+		}
+	}
+	logger.V(1).Info("provisioned keytab", "principals", principals, "created", created, "keytab", keytab, "output", output)
+
+	data, err := os.ReadFile(keytab)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, created, nil
+}
+
+// RekeyPrincipals randomizes the keys of the principals and extracts the new keys in one kadmin session.
+// usage: cpw --random-key principal
+func (k *HeimdalKadmin) RekeyPrincipals(principals ...string) ([]byte, error) {
+	unlock := principalLocks.Lock(principals...)
+	defer unlock()
+
+	attributeArgs, err := heimdalAttributeArgs(k.attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	keytab := newKeytabPath()
+	defer func() {
+		if err := os.RemoveAll(keytab); err != nil {
+			logger.Error(err, "Failed to remove keytab")
+		}
+	}()
+
+	commands := make([]string, 0, 2*len(principals)+1)
+	for _, principal := range principals {
+		if len(attributeArgs) > 0 {
+			commands = append(commands, strings.Join(modifyArgs(principal, attributeArgs), " "))
+		}
+		commands = append(commands, strings.Join(cpwArgs(principal), " "))
+	}
+	commands = append(commands, strings.Join(extKeytabArgs(keytab, principals), " "))
+
+	output, err := k.Batch(commands...)
+	if err != nil {
+		logger.Error(err, "Failed to rekey principals", "principals", principals, "keytab", keytab, "output", output)
+		return nil, err
+	}
+
+	logger.V(1).Info("rekeyed principals", "principals", principals, "keytab", keytab, "output", output)
+
+	return os.ReadFile(keytab)
+}
+
+// DeletePrincipals deletes the principals in one kadmin session.
+// usage: delete principal...
+func (k *HeimdalKadmin) DeletePrincipals(principals ...string) error {
+	unlock := principalLocks.Lock(principals...)
+	defer unlock()
+
+	commands := make([]string, 0, len(principals))
+	for _, principal := range principals {
+		commands = append(commands, strings.Join(deleteArgs(principal), " "))
+	}
+
+	// heimdal kadmin reports the error of a missing principal and continues with the next command.
+	output, err := k.Batch(commands...)
+	if err != nil {
+		logger.Error(err, "Failed to delete principals", "principals", principals, "output", output)
+		return err
+	}
+
+	logger.V(1).Info("deleted principals", "principals", principals, "output", output)
+	return nil
+}
+
+// KeyVersions gets the principals in one kadmin session and returns their current key version numbers.
+// usage: get principal...
+func (k *HeimdalKadmin) KeyVersions(principals ...string) (map[string]uint32, error) {
+	commands := make([]string, 0, len(principals))
+	for _, principal := range principals {
+		commands = append(commands, "get "+principal)
+	}
+
+	// Existing output:
+	// 	            Principal: foo@EXAMPLE.COM
+	// 	    Principal expires: never
+	// 	...
+	// 	                 Kvno: 3
+	// heimdal kadmin reports the error of a missing principal and continues with the next command.
+	output, err := k.Batch(commands...)
+	if err != nil {
+		logger.Error(err, "Failed to get principals", "principals", principals, "output", output)
+		return nil, err
+	}
+
+	return parseKeyVersions(output, "Principal:", heimdalKeyVersion), nil
+}
+
+// heimdalKeyVersion parses the kvno line of heimdal get, e.g. "Kvno: 3"
+func heimdalKeyVersion(line string) (uint32, bool) {
+	vno, ok := strings.CutPrefix(line, "Kvno:")
+	if !ok {
+		return 0, false
+	}
+	return parseKVNO(vno)
+}
@@ -5,8 +5,9 @@ import (
 	"os"
 	"os/exec"
 	"path"
+	"regexp"
+	"strconv"
 	"strings"
-	"sync"
 
 	"github.com/google/uuid"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -14,9 +15,29 @@ import (
 
 var (
 	logger = ctrl.Log.WithName("kadmin")
-	mutex  sync.Mutex
 )
 
+// AdminClient manages principals and extracts keytabs through a kadmin server.
+type AdminClient interface {
+	AddPrincipal(principal string) error
+	Ktadd(principals ...string) ([]byte, error)
+	// ProvisionKeytab creates the principals if they do not exist and extracts their keys,
+	// all in one kadmin session. It returns the keytab and the principals created by the session,
+	// principals which already existed are not in created.
+	ProvisionKeytab(principals ...string) (keytab []byte, created []string, err error)
+	// RekeyPrincipals replaces the keys of the principals with new random keys and extracts them,
+	// the key version numbers are incremented.
+	RekeyPrincipals(principals ...string) ([]byte, error)
+	// DeletePrincipals deletes the principals in one kadmin session, principals which do not exist are ignored.
+	DeletePrincipals(principals ...string) error
+	// KeyVersions returns the current key version numbers of the principals in one kadmin session,
+	// principals which do not exist are missing in the result.
+	KeyVersions(principals ...string) (map[string]uint32, error)
+}
+
+var _ AdminClient = &Kadmin{}
+
+// Kadmin is the MIT kerberos kadmin client.
 type Kadmin struct {
 	// ref: https://web.mit.edu/kerberos/krb5-latest/doc/admin/conf_files/kadm5_acl.html#kadm5-acl-5
 	// Admin user must have permission with "xe" in kadm5.acl
@@ -29,6 +50,11 @@ type Kadmin struct {
 	// if the field is not empty, it will use the existing keytab file,
 	// 	when the file is not found, it will create the file.
 	adminKeytabPath string
+
+	// attributes are applied to principals when they are created or their keys are extracted
+	attributes *PrincipalAttributes
+	// enctypes are the encryption types of keys created for principals, the KDC defaults if empty
+	enctypes []string
 }
 
 func NewKadmin(
@@ -43,6 +69,30 @@ func NewKadmin(
 	}
 }
 
+// SetPrincipalAttributes sets the attributes applied to the principals added or provisioned by the client.
+func (k *Kadmin) SetPrincipalAttributes(attributes *PrincipalAttributes) {
+	k.attributes = attributes
+}
+
+// SetEncryptionTypes sets the encryption types of the keys created when principals are added or rekeyed.
+// Extracting existing keys is not affected, kadmin does not allow "-e" with "-norandkey".
+func (k *Kadmin) SetEncryptionTypes(enctypes []string) {
+	k.enctypes = enctypes
+}
+
+// keysaltArgs returns the "-e" option with the keysalt list of the encryption types, e.g.
+// "-e aes256-cts-hmac-sha1-96:normal,aes128-cts-hmac-sha1-96:normal"
+func (k *Kadmin) keysaltArgs() []string {
+	if len(k.enctypes) == 0 {
+		return nil
+	}
+	keysalts := make([]string, 0, len(k.enctypes))
+	for _, enctype := range k.enctypes {
+		keysalts = append(keysalts, enctype+":normal")
+	}
+	return []string{"-e", strings.Join(keysalts, ",")}
+}
+
 func (k *Kadmin) GetAdminPrincipal() *string {
 	return k.adminPrincipal
 }
@@ -80,6 +130,49 @@ func (k *Kadmin) GetAdminKeytabPath() (string, error) {
 	return k.adminKeytabPath, nil
 }
 
+// execute runs a kadmin command in a worker of the kadmin worker pool.
+// args returns the command arguments for the admin keytab path.
+// When stdin is not empty, it is passed to kadmin, kadmin executes one request per line of stdin.
+func (k *Kadmin) execute(command string, args func(adminKeytabPath string) []string, stdin string) (result string, err error) {
+	krb5Path, err := k.krb5Config.GetTempPath()
+	if err != nil {
+		logger.Error(err, "Failed to get krb5 path")
+		return "", err
+	}
+
+	err = workers.Do(func() error {
+		adminKeytabPath, err := k.GetAdminKeytabPath()
+		defer func() {
+			if err := os.RemoveAll(adminKeytabPath); err != nil {
+				logger.Error(err, "Failed to remove keytab")
+			}
+		}()
+
+		if err != nil {
+			logger.Error(err, "Failed to get admin keytab path")
+			return err
+		}
+
+		cmd := exec.Command(command, args(adminKeytabPath)...)
+		// https://web.mit.edu/kerberos/krb5-latest/doc/admin/install_kdc.html#edit-kdc-configuration-files
+		cmd.Env = append(os.Environ(), "KRB5_CONFIG="+krb5Path)
+		if stdin != "" {
+			cmd.Stdin = strings.NewReader(stdin)
+		}
+		output, err := cmd.CombinedOutput()
+		result = string(output)
+
+		if err != nil {
+			logger.Error(err, "Failed to execute kadmin", "cmd", cmd.String(), "stdin", stdin, "output", result)
+			return err
+		}
+		logger.V(5).Info("executed kadmin", "cmd", cmd.String(), "stdin", stdin, "output", result)
+		return nil
+	})
+
+	return result, err
+}
+
 // Query executes a kadmin query
 // Example:
 //
@@ -91,55 +184,72 @@ func (k *Kadmin) GetAdminKeytabPath() (string, error) {
 //	When generating keytab file, use "-norandkey" flag, the admin user must
 //	have "e" permission in kadm5.acl.
 func (k *Kadmin) Query(query string) (result string, err error) {
-	krb5Path, err := k.krb5Config.GetTempPath()
+	result, err = k.execute("kadmin", func(adminKeytabPath string) []string {
+		return []string{"-kt", adminKeytabPath, "-p", *k.GetAdminPrincipal(), "-q", query}
+	}, "")
 	if err != nil {
-		logger.Error(err, "Failed to get krb5 path")
 		return "", err
 	}
+	return result, nil
+}
 
-	adminKeytabPath, err := k.GetAdminKeytabPath()
-	defer func() {
-		if err := os.RemoveAll(adminKeytabPath); err != nil {
-			logger.Error(err, "Failed to remove keytab")
-		}
-	}()
+// Batch executes kadmin queries in one kadmin session, one query per line of stdin.
+// kadmin reports errors of a query in the output and continues with the next query.
+func (k *Kadmin) Batch(queries ...string) (result string, err error) {
+	return k.execute("kadmin", func(adminKeytabPath string) []string {
+		return []string{"-kt", adminKeytabPath, "-p", *k.GetAdminPrincipal()}
+	}, strings.Join(queries, "\n")+"\n")
+}
 
-	if err != nil {
-		logger.Error(err, "Failed to get admin keytab path")
-		return "", err
-	}
+func newKeytabPath() string {
+	return path.Join(os.TempDir(), fmt.Sprintf("%s.keytab", uuid.New().String()))
+}
 
-	cmd := exec.Command("kadmin", "-kt", adminKeytabPath, "-p", *k.GetAdminPrincipal(), "-q", query)
-	// https://web.mit.edu/kerberos/krb5-latest/doc/admin/install_kdc.html#edit-kdc-configuration-files
-	cmd.Env = append(os.Environ(), "KRB5_CONFIG="+krb5Path)
-	output, err := cmd.CombinedOutput()
-	result = string(output)
+func ktaddQuery(keytab string, principals []string) string {
+	queries := make([]string, 0, 4+len(principals))
+	queries = append(queries, "ktadd", "-k", keytab, "-norandkey")
+	queries = append(queries, principals...)
+	return strings.Join(queries, " ")
+}
 
-	if err != nil {
-		logger.Error(err, "Failed to execute kadmin query", "cmd", cmd.String(), "output", result)
-		return "", err
+// "-randkey" flag is used to generate a random key for the principal
+func (k *Kadmin) addprincQuery(principal string) string {
+	args := append([]string{"addprinc", "-randkey"}, k.keysaltArgs()...)
+	args = append(args, k.attributes.mitArgs()...)
+	return strings.Join(append(args, principal), " ")
+}
+
+// principalQueries returns the queries to add the principal, and to apply the attributes
+// when the principal already exists, because addprinc does not modify existing principals.
+func (k *Kadmin) principalQueries(principal string) []string {
+	queries := []string{k.addprincQuery(principal)}
+	if !k.attributes.isEmpty() {
+		queries = append(queries, k.modprincQuery(principal))
 	}
-	logger.V(5).Info("executed kadmin query", "cmd", cmd.String(), "output", result)
+	return queries
+}
 
-	return result, nil
+func (k *Kadmin) modprincQuery(principal string) string {
+	args := append([]string{"modprinc"}, k.attributes.mitArgs()...)
+	return strings.Join(append(args, principal), " ")
+}
+
+// "-force" flag skips the confirmation prompt of kadmin
+func delprincQuery(principal string) string {
+	return strings.Join([]string{"delprinc", "-force", principal}, " ")
 }
 
 // Ktadd generates a keytab file for the given principals
 // Usage: ktadd [-k[eytab] keytab] [-q] [-e keysaltlist] [-norandkey] [principal | -glob princ-exp] [...]
 func (k *Kadmin) Ktadd(principals ...string) ([]byte, error) {
-	keytab := path.Join(os.TempDir(), fmt.Sprintf("%s.keytab", uuid.New().String()))
+	keytab := newKeytabPath()
 	defer func() {
 		if err := os.RemoveAll(keytab); err != nil {
 			logger.Error(err, "Failed to remove keytab")
 		}
 	}()
 
-	queries := make([]string, 0, 4+len(principals))
-	queries = append(queries, "ktadd", "-k", keytab, "-norandkey")
-
-	queries = append(queries, principals...)
-
-	output, err := k.Query(strings.Join(queries, " "))
+	output, err := k.Query(ktaddQuery(keytab, principals))
 	if err != nil {
 		logger.Error(err, "Failed to save keytab", "principals", principals, "keytab", keytab)
 		return nil, err
@@ -154,16 +264,11 @@ func (k *Kadmin) Ktadd(principals ...string) ([]byte, error) {
 // If a principal already exists, it kadmind not return an error.
 // usage: https://web.mit.edu/kerberos/krb5-latest/doc/admin/admin_commands/kadmin_local.html#add-principal
 func (k *Kadmin) AddPrincipal(principal string) error {
-	// Add a mutex to avoid adding the same principal concurrently
-	mutex.Lock()
-	defer mutex.Unlock()
-	queries := []string{
-		"addprinc",
-		"-randkey", // "-randkey" flag is used to generate a random key for the principal
-		principal,
-	}
+	// Lock the principal to avoid adding the same principal concurrently
+	unlock := principalLocks.Lock(principal)
+	defer unlock()
 
-	// When execute: kadmin -kt /tmp/foo/admin.keytab -p admin/admin -q "addprinc -randkey foo"
+	// When execute: kadmin -kt /tmp/foo/admin.keytab -p admin/admin with query "addprinc -randkey foo"
 	// Added output:
 	// 	Authenticating as principal admin/admin with keytab /tmp/foo/admin.keytab.
 	// 	No policy specified for foo@EXAMPLE.COM; defaulting to no policy
@@ -176,7 +281,7 @@ func (k *Kadmin) AddPrincipal(principal string) error {
 	// 	add_principal: Principal or policy already exists while creating "foo@EXAMPLE.COM".
 	// exit code 0
 	//
-	output, err := k.Query(strings.Join(queries, " "))
+	output, err := k.Batch(k.principalQueries(principal)...)
 	if err != nil {
 		logger.Error(err, "Failed to add principal", "principal", principal)
 		return err
@@ -185,3 +290,173 @@ func (k *Kadmin) AddPrincipal(principal string) error {
 	logger.V(1).Info("created a new principal", "principal", principal, "output", output)
 	return nil
 }
+
+// ProvisionKeytab adds the principals and extracts their keys in one kadmin session.
+// Existing principals are kept as is, kadmin reports them as already existing and continues.
+// The created principals are parsed from the output of addprinc, see AddPrincipal.
+func (k *Kadmin) ProvisionKeytab(principals ...string) ([]byte, []string, error) {
+	unlock := principalLocks.Lock(principals...)
+	defer unlock()
+
+	keytab := newKeytabPath()
+	defer func() {
+		if err := os.RemoveAll(keytab); err != nil {
+			logger.Error(err, "Failed to remove keytab")
+		}
+	}()
+
+	queries := make([]string, 0, len(principals)+1)
+	for _, principal := range principals {
+		queries = append(queries, k.principalQueries(principal)...)
+	}
+	queries = append(queries, ktaddQuery(keytab, principals))
+
+	output, err := k.Batch(queries...)
+	if err != nil {
+		logger.Error(err, "Failed to provision keytab", "principals", principals, "keytab", keytab)
+		return nil, nil, err
+	}
+
+	created := parseCreatedPrincipals(output)
+	logger.V(1).Info("provisioned keytab", "principals", principals, "created", created, "keytab", keytab, "output", output)
+
+	data, err := os.ReadFile(keytab)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, created, nil
+}
+
+// createdPrincipalPattern matches the addprinc output of a created principal, e.g. `Principal "foo@EXAMPLE.COM" created.`
+var createdPrincipalPattern = regexp.MustCompile(`(?m)^Principal "(.+)" created\.$`)
+
+// parseCreatedPrincipals returns the principals created by addprinc queries in the kadmin output.
+func parseCreatedPrincipals(output string) []string {
+	created := make([]string, 0)
+	for _, match := range createdPrincipalPattern.FindAllStringSubmatch(output, -1) {
+		created = append(created, match[1])
+	}
+	return created
+}
+
+// RekeyPrincipals randomizes the keys of the principals and extracts the new keys in one kadmin session.
+// ktadd without "-norandkey" randomizes the keys, the attributes are applied first as when provisioning.
+func (k *Kadmin) RekeyPrincipals(principals ...string) ([]byte, error) {
+	unlock := principalLocks.Lock(principals...)
+	defer unlock()
+
+	keytab := newKeytabPath()
+	defer func() {
+		if err := os.RemoveAll(keytab); err != nil {
+			logger.Error(err, "Failed to remove keytab")
+		}
+	}()
+
+	queries := make([]string, 0, len(principals)+1)
+	if !k.attributes.isEmpty() {
+		for _, principal := range principals {
+			queries = append(queries, k.modprincQuery(principal))
+		}
+	}
+	args := append([]string{"ktadd", "-k", keytab}, k.keysaltArgs()...)
+	queries = append(queries, strings.Join(append(args, principals...), " "))
+
+	output, err := k.Batch(queries...)
+	if err != nil {
+		logger.Error(err, "Failed to rekey principals", "principals", principals, "keytab", keytab)
+		return nil, err
+	}
+
+	logger.V(1).Info("rekeyed principals", "principals", principals, "keytab", keytab, "output", output)
+
+	return os.ReadFile(keytab)
+}
+
+// DeletePrincipals deletes the principals in one kadmin session.
+// usage: https://web.mit.edu/kerberos/krb5-latest/doc/admin/admin_commands/kadmin_local.html#delete-principal
+func (k *Kadmin) DeletePrincipals(principals ...string) error {
+	unlock := principalLocks.Lock(principals...)
+	defer unlock()
+
+	// Not existing output:
+	// 	delete_principal: Principal does not exist while deleting principal "foo@EXAMPLE.COM"
+	// kadmin continues with the next query, and exits with code 0
+	queries := make([]string, 0, len(principals))
+	for _, principal := range principals {
+		queries = append(queries, delprincQuery(principal))
+	}
+
+	output, err := k.Batch(queries...)
+	if err != nil {
+		logger.Error(err, "Failed to delete principals", "principals", principals)
+		return err
+	}
+
+	logger.V(1).Info("deleted principals", "principals", principals, "output", output)
+	return nil
+}
+
+// KeyVersions gets the principals in one kadmin session and returns their current key version numbers.
+// usage: https://web.mit.edu/kerberos/krb5-latest/doc/admin/admin_commands/kadmin_local.html#get-principal
+func (k *Kadmin) KeyVersions(principals ...string) (map[string]uint32, error) {
+	queries := make([]string, 0, len(principals))
+	for _, principal := range principals {
+		queries = append(queries, "getprinc "+principal)
+	}
+
+	// Existing output:
+	// 	Principal: foo@EXAMPLE.COM
+	// 	...
+	// 	Number of keys: 2
+	// 	Key: vno 3, aes256-cts-hmac-sha1-96
+	// 	Key: vno 3, aes128-cts-hmac-sha1-96
+	// Not existing output:
+	// 	get_principal: Principal does not exist while retrieving "foo@EXAMPLE.COM".
+	output, err := k.Batch(queries...)
+	if err != nil {
+		logger.Error(err, "Failed to get principals", "principals", principals)
+		return nil, err
+	}
+
+	return parseKeyVersions(output, "Principal:", mitKeyVersion), nil
+}
+
+// mitKeyVersion parses the kvno of a key line of MIT getprinc, e.g. "Key: vno 3, aes256-cts-hmac-sha1-96"
+func mitKeyVersion(line string) (uint32, bool) {
+	vno, ok := strings.CutPrefix(line, "Key: vno ")
+	if !ok {
+		return 0, false
+	}
+	vno, _, _ = strings.Cut(vno, ",")
+	return parseKVNO(vno)
+}
+
+// parseKeyVersions parses the output of kadmin get principal commands, a line starting with principalPrefix
+// starts the output of a principal, and kvno returns the key version number found in a line.
+// The highest key version number of each principal is returned.
+func parseKeyVersions(output string, principalPrefix string, kvno func(line string) (uint32, bool)) map[string]uint32 {
+	versions := make(map[string]uint32)
+	principal := ""
+	for line := range strings.Lines(output) {
+		line = strings.TrimSpace(line)
+		if name, ok := strings.CutPrefix(line, principalPrefix); ok {
+			principal = strings.TrimSpace(name)
+			continue
+		}
+		if principal == "" {
+			continue
+		}
+		if vno, ok := kvno(line); ok && vno >= versions[principal] {
+			versions[principal] = vno
+		}
+	}
+	return versions
+}
+
+func parseKVNO(s string) (uint32, bool) {
+	vno, err := strconv.ParseUint(strings.TrimSpace(s), 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint32(vno), true
+}
@@ -0,0 +1,72 @@
+package kerberos
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKeyVersions(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		parse  func(line string) (uint32, bool)
+		want   map[string]uint32
+	}{
+		{
+			name: "mit",
+			output: `Authenticating as principal admin/admin with keytab /tmp/admin.keytab.
+Principal: HTTP/foo.default.svc.cluster.local@EXAMPLE.COM
+Expiration date: [never]
+Last password change: Mon Jan 05 10:00:00 UTC 2026
+Number of keys: 2
+Key: vno 3, aes256-cts-hmac-sha1-96
+Key: vno 3, aes128-cts-hmac-sha1-96
+MKey: vno 1
+Policy: [none]
+get_principal: Principal does not exist while retrieving "user@EXAMPLE.COM".
+Principal: hdfs/foo.default.svc.cluster.local@EXAMPLE.COM
+Number of keys: 1
+Key: vno 1, aes256-cts-hmac-sha1-96
+`,
+			parse: mitKeyVersion,
+			want: map[string]uint32{
+				"HTTP/foo.default.svc.cluster.local@EXAMPLE.COM": 3,
+				"hdfs/foo.default.svc.cluster.local@EXAMPLE.COM": 1,
+			},
+		},
+		{
+			name: "heimdal",
+			output: `            Principal: HTTP/foo.default.svc.cluster.local@EXAMPLE.COM
+    Principal expires: never
+ Last password change: 2026-01-05 10:00:00 UTC
+                 Kvno: 4
+                Mkvno: unknown
+kadmin: get user@EXAMPLE.COM: Principal does not exist
+`,
+			parse: heimdalKeyVersion,
+			want:  map[string]uint32{"HTTP/foo.default.svc.cluster.local@EXAMPLE.COM": 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseKeyVersions(tt.output, "Principal:", tt.parse); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseKeyVersions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCreatedPrincipals(t *testing.T) {
+	output := `Authenticating as principal admin/admin with keytab /tmp/admin.keytab.
+No policy specified for HTTP/foo.default.svc.cluster.local@EXAMPLE.COM; defaulting to no policy
+Principal "HTTP/foo.default.svc.cluster.local@EXAMPLE.COM" created.
+No policy specified for user@EXAMPLE.COM; defaulting to no policy
+add_principal: Principal or policy already exists while creating "user@EXAMPLE.COM".
+Entry for principal HTTP/foo.default.svc.cluster.local@EXAMPLE.COM with kvno 1, encryption type aes256-cts-hmac-sha1-96 added to keytab WRFILE:/tmp/foo.keytab.
+`
+	want := []string{"HTTP/foo.default.svc.cluster.local@EXAMPLE.COM"}
+	if got := parseCreatedPrincipals(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseCreatedPrincipals() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,396 @@
+package kerberos
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+/*
+Keytab file format, all integers are big-endian.
+ref: https://web.mit.edu/kerberos/krb5-latest/doc/formats/keytab_file_format.html
+
+	keytab {
+	    uint16_t file_format_version;                    # 0x502
+	    keytab_entry entries[*];
+	};
+
+	keytab_entry {
+	    int32_t size;                                    # negative size is a hole of deleted entry
+	    uint16_t num_components;                         # sub 1 if version 0x501
+	    counted_octet_string realm;
+	    counted_octet_string components[num_components];
+	    uint32_t name_type;                              # not present if version 0x501
+	    uint32_t timestamp;
+	    uint8_t vno8;
+	    keyblock key;
+	    uint32_t vno;                                    # only present if >= 4 bytes left in entry
+	};
+
+	counted_octet_string {
+	    uint16_t length;
+	    uint8_t data[length];
+	};
+
+	keyblock {
+	    uint16_t type;
+	    counted_octet_string;
+	};
+*/
+
+const (
+	KeytabVersion501 uint16 = 0x0501
+	KeytabVersion502 uint16 = 0x0502
+
+	// KRB5_NT_PRINCIPAL, just the name of the principal
+	NameTypePrincipal uint32 = 1
+	// KRB5_NT_SRV_HST, service with host name as instance
+	NameTypeServiceHost uint32 = 3
+)
+
+// Encryption types
+// ref: https://www.iana.org/assignments/kerberos-parameters/kerberos-parameters.xhtml#kerberos-parameters-1
+var encryptionTypes = map[int32]string{
+	1:  "des-cbc-crc",
+	3:  "des-cbc-md5",
+	16: "des3-cbc-sha1",
+	17: "aes128-cts-hmac-sha1-96",
+	18: "aes256-cts-hmac-sha1-96",
+	19: "aes128-cts-hmac-sha256-128",
+	20: "aes256-cts-hmac-sha384-192",
+	23: "arcfour-hmac",
+	24: "arcfour-hmac-exp",
+	25: "camellia128-cts-cmac",
+	26: "camellia256-cts-cmac",
+}
+
+// encryptionTypeAliases are the alternative names accepted by kerberos libraries
+var encryptionTypeAliases = map[string]int32{
+	"rc4-hmac":         23,
+	"arcfour-hmac-md5": 23,
+	"des3-cbc-sha1-kd": 16,
+	"des3-hmac-sha1":   16,
+	"aes128-cts":       17,
+	"aes256-cts":       18,
+	"aes128-sha2":      19,
+	"aes256-sha2":      20,
+	"camellia128-cts":  25,
+	"camellia256-cts":  26,
+}
+
+// EncryptionTypeName returns the name of the encryption type, or the number if it is unknown.
+func EncryptionTypeName(enctype int32) string {
+	if name, ok := encryptionTypes[enctype]; ok {
+		return name
+	}
+	return fmt.Sprintf("enctype-%d", enctype)
+}
+
+// ParseEncryptionType returns the number of the encryption type name.
+// Names are case-insensitive, and common aliases such as "rc4-hmac" are accepted.
+func ParseEncryptionType(name string) (int32, error) {
+	name = strings.ToLower(name)
+	for enctype, n := range encryptionTypes {
+		if n == name {
+			return enctype, nil
+		}
+	}
+	if enctype, ok := encryptionTypeAliases[name]; ok {
+		return enctype, nil
+	}
+	return 0, fmt.Errorf("unknown encryption type %q", name)
+}
+
+type Principal struct {
+	Components []string
+	Realm      string
+	NameType   uint32
+}
+
+// ParsePrincipal parses a principal in the form of "primary/instance@REALM".
+func ParsePrincipal(principal string) Principal {
+	name, realm, _ := strings.Cut(principal, "@")
+	components := strings.Split(name, "/")
+	nameType := NameTypePrincipal
+	if len(components) > 1 {
+		nameType = NameTypeServiceHost
+	}
+	return Principal{Components: components, Realm: realm, NameType: nameType}
+}
+
+func (p Principal) String() string {
+	return strings.Join(p.Components, "/") + "@" + p.Realm
+}
+
+type KeytabEntry struct {
+	Principal Principal
+	Timestamp time.Time
+	KVNO      uint32
+	EncType   int32
+	Key       []byte
+}
+
+func (e *KeytabEntry) String() string {
+	return fmt.Sprintf("%s kvno=%d enctype=%s", e.Principal, e.KVNO, EncryptionTypeName(e.EncType))
+}
+
+// Keytab is a kerberos keytab in the v0x502 format.
+type Keytab struct {
+	Entries []*KeytabEntry
+}
+
+// ParseKeytab parses a keytab in v0x501 or v0x502 format.
+func ParseKeytab(data []byte) (*Keytab, error) {
+	if len(data) < 2 {
+		return nil, errors.New("keytab is too short")
+	}
+
+	version := binary.BigEndian.Uint16(data[:2])
+	if version != KeytabVersion501 && version != KeytabVersion502 {
+		return nil, fmt.Errorf("unsupported keytab version 0x%x", version)
+	}
+
+	keytab := &Keytab{}
+	r := bytes.NewReader(data[2:])
+	for r.Len() > 0 {
+		var size int32
+		if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+			return nil, fmt.Errorf("failed to read keytab entry size: %w", err)
+		}
+
+		// skip the hole of deleted entry
+		if size < 0 {
+			if _, err := r.Seek(int64(-size), io.SeekCurrent); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if size == 0 {
+			break
+		}
+		if int(size) > r.Len() {
+			return nil, fmt.Errorf("keytab entry size %d exceeds remaining %d bytes", size, r.Len())
+		}
+
+		buf := make([]byte, size)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+
+		entry, err := parseKeytabEntry(buf, version)
+		if err != nil {
+			return nil, err
+		}
+		keytab.Entries = append(keytab.Entries, entry)
+	}
+
+	return keytab, nil
+}
+
+func parseKeytabEntry(data []byte, version uint16) (*KeytabEntry, error) {
+	r := bytes.NewReader(data)
+	entry := &KeytabEntry{}
+
+	var numComponents uint16
+	if err := binary.Read(r, binary.BigEndian, &numComponents); err != nil {
+		return nil, err
+	}
+	if version == KeytabVersion501 {
+		numComponents--
+	}
+
+	realm, err := readCountedString(r)
+	if err != nil {
+		return nil, err
+	}
+	entry.Principal.Realm = string(realm)
+
+	for range numComponents {
+		component, err := readCountedString(r)
+		if err != nil {
+			return nil, err
+		}
+		entry.Principal.Components = append(entry.Principal.Components, string(component))
+	}
+
+	entry.Principal.NameType = NameTypePrincipal
+	if version == KeytabVersion502 {
+		if err := binary.Read(r, binary.BigEndian, &entry.Principal.NameType); err != nil {
+			return nil, err
+		}
+	}
+
+	var timestamp uint32
+	if err := binary.Read(r, binary.BigEndian, &timestamp); err != nil {
+		return nil, err
+	}
+	entry.Timestamp = time.Unix(int64(timestamp), 0)
+
+	var vno8 uint8
+	if err := binary.Read(r, binary.BigEndian, &vno8); err != nil {
+		return nil, err
+	}
+	entry.KVNO = uint32(vno8)
+
+	var enctype uint16
+	if err := binary.Read(r, binary.BigEndian, &enctype); err != nil {
+		return nil, err
+	}
+	entry.EncType = int32(int16(enctype))
+
+	if entry.Key, err = readCountedString(r); err != nil {
+		return nil, err
+	}
+
+	// the 32-bit kvno supersedes the 8-bit one when present and not zero
+	if r.Len() >= 4 {
+		var vno uint32
+		if err := binary.Read(r, binary.BigEndian, &vno); err != nil {
+			return nil, err
+		}
+		if vno != 0 {
+			entry.KVNO = vno
+		}
+	}
+
+	return entry, nil
+}
+
+func readCountedString(r *bytes.Reader) ([]byte, error) {
+	var length uint16
+	if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+		return nil, err
+	}
+	if int(length) > r.Len() {
+		return nil, fmt.Errorf("counted string length %d exceeds remaining %d bytes", length, r.Len())
+	}
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func writeCountedString(w *bytes.Buffer, data []byte) {
+	_ = binary.Write(w, binary.BigEndian, uint16(len(data)))
+	w.Write(data)
+}
+
+// Marshal encodes the keytab in v0x502 format.
+func (k *Keytab) Marshal() ([]byte, error) {
+	out := &bytes.Buffer{}
+	_ = binary.Write(out, binary.BigEndian, KeytabVersion502)
+
+	for _, entry := range k.Entries {
+		buf := &bytes.Buffer{}
+		_ = binary.Write(buf, binary.BigEndian, uint16(len(entry.Principal.Components)))
+		writeCountedString(buf, []byte(entry.Principal.Realm))
+		for _, component := range entry.Principal.Components {
+			writeCountedString(buf, []byte(component))
+		}
+		_ = binary.Write(buf, binary.BigEndian, entry.Principal.NameType)
+		_ = binary.Write(buf, binary.BigEndian, uint32(entry.Timestamp.Unix()))
+		_ = binary.Write(buf, binary.BigEndian, uint8(entry.KVNO))
+		_ = binary.Write(buf, binary.BigEndian, uint16(entry.EncType))
+		writeCountedString(buf, entry.Key)
+		_ = binary.Write(buf, binary.BigEndian, entry.KVNO)
+
+		if buf.Len() > int(^uint32(0)>>1) {
+			return nil, fmt.Errorf("keytab entry %s is too large", entry)
+		}
+		_ = binary.Write(out, binary.BigEndian, int32(buf.Len()))
+		out.Write(buf.Bytes())
+	}
+
+	return out.Bytes(), nil
+}
+
+// Principals returns the distinct principals in the keytab, in order of appearance.
+func (k *Keytab) Principals() []string {
+	principals := make([]string, 0)
+	for _, entry := range k.Entries {
+		principal := entry.Principal.String()
+		if !slices.Contains(principals, principal) {
+			principals = append(principals, principal)
+		}
+	}
+	return principals
+}
+
+// Filter returns a new keytab with the entries matching the predicate.
+func (k *Keytab) Filter(predicate func(entry *KeytabEntry) bool) *Keytab {
+	filtered := &Keytab{}
+	for _, entry := range k.Entries {
+		if predicate(entry) {
+			filtered.Entries = append(filtered.Entries, entry)
+		}
+	}
+	return filtered
+}
+
+// FilterByPrincipal returns a new keytab with the entries of the given principals.
+func (k *Keytab) FilterByPrincipal(principals ...string) *Keytab {
+	return k.Filter(func(entry *KeytabEntry) bool {
+		return slices.Contains(principals, entry.Principal.String())
+	})
+}
+
+// FilterByEncryptionType returns a new keytab with the entries of the given encryption types.
+func (k *Keytab) FilterByEncryptionType(enctypes ...int32) *Keytab {
+	return k.Filter(func(entry *KeytabEntry) bool {
+		return slices.Contains(enctypes, entry.EncType)
+	})
+}
+
+// MaxKVNO returns the highest key version number of the principal in the keytab, 0 if it has no keys.
+func (k *Keytab) MaxKVNO(principal string) uint32 {
+	var kvno uint32
+	for _, entry := range k.Entries {
+		if entry.Principal.String() == principal && entry.KVNO > kvno {
+			kvno = entry.KVNO
+		}
+	}
+	return kvno
+}
+
+// Verify checks that the keytab contains at least one key for each of the given principals.
+func (k *Keytab) Verify(principals ...string) error {
+	existing := k.Principals()
+	missing := make([]string, 0)
+	for _, principal := range principals {
+		if !slices.Contains(existing, principal) {
+			missing = append(missing, principal)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("keytab does not contain principals %v, found %v", missing, existing)
+	}
+	return nil
+}
+
+// MergeKeytabs merges keytabs into a new keytab.
+// Entries with the same principal, kvno and encryption type are deduplicated, the later one wins.
+func MergeKeytabs(keytabs ...*Keytab) *Keytab {
+	merged := &Keytab{}
+	index := make(map[string]int)
+	for _, keytab := range keytabs {
+		if keytab == nil {
+			continue
+		}
+		for _, entry := range keytab.Entries {
+			key := fmt.Sprintf("%s/%d/%d", entry.Principal, entry.KVNO, entry.EncType)
+			if i, ok := index[key]; ok {
+				merged.Entries[i] = entry
+				continue
+			}
+			index[key] = len(merged.Entries)
+			merged.Entries = append(merged.Entries, entry)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,147 @@
+package kerberos
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const (
+	testPrincipalHTTP = "HTTP/foo.default.svc.cluster.local@EXAMPLE.COM"
+	testPrincipalHDFS = "hdfs/foo.default.svc.cluster.local@EXAMPLE.COM"
+)
+
+func newTestEntry(principal string, kvno uint32, enctype int32) *KeytabEntry {
+	return &KeytabEntry{
+		Principal: ParsePrincipal(principal),
+		Timestamp: time.Unix(1700000000, 0),
+		KVNO:      kvno,
+		EncType:   enctype,
+		Key:       bytes.Repeat([]byte{byte(kvno)}, 16),
+	}
+}
+
+func TestKeytabMarshalRoundTrip(t *testing.T) {
+	keytab := &Keytab{Entries: []*KeytabEntry{
+		newTestEntry(testPrincipalHTTP, 2, 18),
+		newTestEntry(testPrincipalHTTP, 2, 17),
+		newTestEntry("user@EXAMPLE.COM", 300, 18),
+	}}
+
+	data, err := keytab.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ParseKeytab(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, keytab) {
+		t.Errorf("ParseKeytab() = %v, want %v", got.Entries, keytab.Entries)
+	}
+}
+
+func TestParseKeytab(t *testing.T) {
+	// v0x502 keytab with a hole and an entry without the trailing 32-bit kvno
+	data := []byte{
+		0x05, 0x02,
+		0xff, 0xff, 0xff, 0xfc, 0x00, 0x00, 0x00, 0x00, // hole of 4 bytes
+		0x00, 0x00, 0x00, 0x26, // entry size 38
+		0x00, 0x01, // num components
+		0x00, 0x0b, 'E', 'X', 'A', 'M', 'P', 'L', 'E', '.', 'C', 'O', 'M',
+		0x00, 0x04, 'u', 's', 'e', 'r',
+		0x00, 0x00, 0x00, 0x01, // name type
+		0x00, 0x00, 0x00, 0x00, // timestamp
+		0x05,       // vno8
+		0x00, 0x11, // enctype 17
+		0x00, 0x04, 0x01, 0x02, 0x03, 0x04,
+	}
+
+	keytab, err := ParseKeytab(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(keytab.Entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(keytab.Entries))
+	}
+	entry := keytab.Entries[0]
+	if entry.Principal.String() != "user@EXAMPLE.COM" || entry.KVNO != 5 || entry.EncType != 17 {
+		t.Errorf("unexpected entry %s", entry)
+	}
+
+	if _, err := ParseKeytab([]byte{0x05, 0x03}); err == nil {
+		t.Error("expected error for unsupported version")
+	}
+	if _, err := ParseKeytab(data[:len(data)-2]); err == nil {
+		t.Error("expected error for truncated keytab")
+	}
+}
+
+func TestKeytabFilterAndVerify(t *testing.T) {
+	keytab := &Keytab{Entries: []*KeytabEntry{
+		newTestEntry(testPrincipalHTTP, 2, 18),
+		newTestEntry(testPrincipalHTTP, 2, 23),
+		newTestEntry(testPrincipalHDFS, 1, 18),
+	}}
+
+	if got := keytab.Principals(); !reflect.DeepEqual(got, []string{testPrincipalHTTP, testPrincipalHDFS}) {
+		t.Errorf("Principals() = %v", got)
+	}
+
+	if got := keytab.FilterByPrincipal(testPrincipalHDFS); len(got.Entries) != 1 {
+		t.Errorf("FilterByPrincipal() returned %d entries, want 1", len(got.Entries))
+	}
+
+	if got := keytab.FilterByEncryptionType(18); len(got.Entries) != 2 {
+		t.Errorf("FilterByEncryptionType() returned %d entries, want 2", len(got.Entries))
+	}
+
+	if err := keytab.Verify(testPrincipalHTTP, testPrincipalHDFS); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+	if err := keytab.Verify("zookeeper/foo@EXAMPLE.COM"); err == nil {
+		t.Error("Verify() expected error for missing principal")
+	}
+}
+
+func TestMergeKeytabs(t *testing.T) {
+	a := &Keytab{Entries: []*KeytabEntry{
+		newTestEntry(testPrincipalHTTP, 1, 18),
+		newTestEntry(testPrincipalHTTP, 2, 18),
+	}}
+	replaced := newTestEntry(testPrincipalHTTP, 2, 18)
+	replaced.Key = []byte("replaced")
+	b := &Keytab{Entries: []*KeytabEntry{
+		replaced,
+		newTestEntry(testPrincipalHDFS, 1, 18),
+	}}
+
+	merged := MergeKeytabs(a, nil, b)
+	if len(merged.Entries) != 3 {
+		t.Fatalf("MergeKeytabs() returned %d entries, want 3", len(merged.Entries))
+	}
+	if !bytes.Equal(merged.Entries[1].Key, []byte("replaced")) {
+		t.Errorf("MergeKeytabs() did not replace duplicated entry, got %s", merged.Entries[1])
+	}
+}
+
+func TestParseEncryptionType(t *testing.T) {
+	for name, want := range map[string]int32{
+		"aes256-cts-hmac-sha1-96": 18,
+		"AES128-CTS-HMAC-SHA1-96": 17,
+		"rc4-hmac":                23,
+	} {
+		got, err := ParseEncryptionType(name)
+		if err != nil || got != want {
+			t.Errorf("ParseEncryptionType(%q) = %d, %v, want %d", name, got, err, want)
+		}
+	}
+
+	if _, err := ParseEncryptionType("foo"); err == nil {
+		t.Error("ParseEncryptionType() expected error for unknown encryption type")
+	}
+}
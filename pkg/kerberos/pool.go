@@ -0,0 +1,94 @@
+package kerberos
+
+import (
+	"slices"
+	"sync"
+)
+
+const (
+	// DefaultKadminWorkers is the default number of kadmin sessions running concurrently.
+	DefaultKadminWorkers = 4
+)
+
+var (
+	workers = newWorkerPool(DefaultKadminWorkers)
+
+	principalLocks = newKeyedMutex()
+)
+
+// SetKadminWorkers sets the maximum number of kadmin sessions running concurrently.
+// It must be called before any kadmin session is started.
+func SetKadminWorkers(n int) {
+	if n <= 0 {
+		n = DefaultKadminWorkers
+	}
+	workers = newWorkerPool(n)
+}
+
+// workerPool bounds the number of kadmin processes, so a burst of volumes does not
+// start hundreds of kadmin processes against the kadmin server.
+type workerPool struct {
+	slots chan struct{}
+}
+
+func newWorkerPool(size int) *workerPool {
+	return &workerPool{slots: make(chan struct{}, size)}
+}
+
+// Do runs fn when a worker is available.
+func (p *workerPool) Do(fn func() error) error {
+	p.slots <- struct{}{}
+	defer func() { <-p.slots }()
+	return fn()
+}
+
+// keyedMutex locks by key, so operations on unrelated principals or realms do not block each other.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*refMutex
+}
+
+type refMutex struct {
+	sync.Mutex
+	refs int
+}
+
+func newKeyedMutex() *keyedMutex {
+	return &keyedMutex{locks: make(map[string]*refMutex)}
+}
+
+// Lock locks all keys and returns a function to unlock them.
+// Keys are locked in sorted order to avoid deadlock between callers locking overlapping keys.
+func (m *keyedMutex) Lock(keys ...string) (unlock func()) {
+	keys = slices.Clone(keys)
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+
+	locked := make([]*refMutex, 0, len(keys))
+	for _, key := range keys {
+		m.mu.Lock()
+		lock, ok := m.locks[key]
+		if !ok {
+			lock = &refMutex{}
+			m.locks[key] = lock
+		}
+		lock.refs++
+		m.mu.Unlock()
+
+		lock.Lock()
+		locked = append(locked, lock)
+	}
+
+	return func() {
+		for i, lock := range locked {
+			lock.Unlock()
+
+			m.mu.Lock()
+			lock.refs--
+			if lock.refs == 0 {
+				delete(m.locks, keys[i])
+			}
+			m.mu.Unlock()
+		}
+	}
+}
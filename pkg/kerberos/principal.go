@@ -0,0 +1,182 @@
+package kerberos
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// HostPlaceholder is the hadoop style placeholder for the hostname in a principal, e.g. `hdfs/_HOST@EXAMPLE.COM`.
+	HostPlaceholder = "_HOST"
+
+	// DefaultPrincipalTemplate is a service principal for every service name and hostname.
+	DefaultPrincipalTemplate = "${service}/${hostname}"
+)
+
+// PrincipalValues are the values a principal template is evaluated against.
+type PrincipalValues struct {
+	Realm          string
+	Namespace      string
+	Pod            string
+	ServiceAccount string
+	Node           string
+
+	// ServiceNames and Hostnames are lists, a template is expanded once for each of them.
+	ServiceNames []string
+	Hostnames    []string
+}
+
+func (v *PrincipalValues) lookup(name string) (string, bool) {
+	switch name {
+	case "realm":
+		return v.Realm, true
+	case "namespace":
+		return v.Namespace, true
+	case "pod":
+		return v.Pod, true
+	case "serviceAccount":
+		return v.ServiceAccount, true
+	case "node":
+		return v.Node, true
+	}
+	return "", false
+}
+
+// ExpandPrincipalTemplate evaluates a principal template, e.g. `${service}/${hostname}` or `${serviceAccount}`.
+//
+// Supported variables are `${realm}`, `${namespace}`, `${pod}`, `${serviceAccount}`, `${node}`,
+// `${service}` and `${hostname}`, `_HOST` is the same as `${hostname}`.
+// A template using `${service}` or `${hostname}` is expanded for every service name and hostname,
+// so it expands to no principal when the list is empty.
+// The realm is appended when the template has no realm.
+func ExpandPrincipalTemplate(template string, values *PrincipalValues) ([]string, error) {
+	template = strings.ReplaceAll(template, HostPlaceholder, "${hostname}")
+
+	var usesService, usesHostname bool
+	var expandErr error
+	os.Expand(template, func(name string) string {
+		switch name {
+		case "service":
+			usesService = true
+		case "hostname":
+			usesHostname = true
+		default:
+			value, ok := values.lookup(name)
+			if !ok {
+				expandErr = fmt.Errorf("unknown variable %q in principal template %q", name, template)
+			} else if value == "" && expandErr == nil {
+				expandErr = fmt.Errorf("variable %q in principal template %q is empty", name, template)
+			}
+		}
+		return ""
+	})
+	if expandErr != nil {
+		return nil, expandErr
+	}
+
+	services := []string{""}
+	if usesService {
+		services = values.ServiceNames
+	}
+	hostnames := []string{""}
+	if usesHostname {
+		hostnames = values.Hostnames
+	}
+
+	principals := make([]string, 0, len(services)*len(hostnames))
+	for _, service := range services {
+		for _, hostname := range hostnames {
+			principal := os.Expand(template, func(name string) string {
+				switch name {
+				case "service":
+					return service
+				case "hostname":
+					return hostname
+				}
+				value, _ := values.lookup(name)
+				return value
+			})
+
+			if !strings.Contains(principal, "@") {
+				principal += "@" + values.Realm
+			}
+			if err := validatePrincipal(principal); err != nil {
+				return nil, fmt.Errorf("invalid principal from template %q: %w", template, err)
+			}
+			if !slices.Contains(principals, principal) {
+				principals = append(principals, principal)
+			}
+		}
+	}
+	return principals, nil
+}
+
+// validatePrincipal rejects principals which kadmin would not parse as one principal argument,
+// principals are passed to kadmin as query arguments and batch lines.
+func validatePrincipal(principal string) error {
+	if strings.ContainsAny(principal, " \t\r\n\"'") {
+		return fmt.Errorf("principal %q contains whitespace or quotes", principal)
+	}
+	if strings.HasPrefix(principal, "-") {
+		return fmt.Errorf("principal %q starts with -", principal)
+	}
+	p := ParsePrincipal(principal)
+	if p.Realm == "" || strings.Contains(p.Realm, "@") {
+		return fmt.Errorf("principal %q has an invalid realm", principal)
+	}
+	if slices.Contains(p.Components, "") {
+		return fmt.Errorf("principal %q has an empty component", principal)
+	}
+	return nil
+}
+
+// IsIPHostname reports whether the hostname is an ip address, or derived from a pod ip address
+// as in the kubernetes pod dns records, e.g. `10-244-0-5.default.pod.cluster.local`.
+func IsIPHostname(hostname string) bool {
+	if net.ParseIP(hostname) != nil {
+		return true
+	}
+
+	label, _, _ := strings.Cut(hostname, ".")
+	if net.ParseIP(strings.ReplaceAll(label, "-", ".")) != nil {
+		return true
+	}
+	return net.ParseIP(strings.ReplaceAll(label, "-", ":")) != nil
+}
+
+// PrincipalAttributes are applied to principals when they are created, and when their keys are extracted.
+type PrincipalAttributes struct {
+	Policy string
+	// Flags in MIT kadmin syntax, e.g. `+requires_preauth`
+	Flags        []string
+	MaxLife      time.Duration
+	MaxRenewLife time.Duration
+}
+
+func (a *PrincipalAttributes) isEmpty() bool {
+	return a == nil || (a.Policy == "" && len(a.Flags) == 0 && a.MaxLife == 0 && a.MaxRenewLife == 0)
+}
+
+// mitArgs returns the options of MIT kadmin addprinc and modprinc.
+// Durations are passed in getdate format, quoted because kadmin splits arguments at spaces.
+func (a *PrincipalAttributes) mitArgs() []string {
+	if a.isEmpty() {
+		return nil
+	}
+
+	args := make([]string, 0)
+	if a.Policy != "" {
+		args = append(args, "-policy", a.Policy)
+	}
+	if a.MaxLife != 0 {
+		args = append(args, "-maxlife", fmt.Sprintf("\"%d seconds\"", int64(a.MaxLife.Seconds())))
+	}
+	if a.MaxRenewLife != 0 {
+		args = append(args, "-maxrenewlife", fmt.Sprintf("\"%d seconds\"", int64(a.MaxRenewLife.Seconds())))
+	}
+	return append(args, a.Flags...)
+}
@@ -0,0 +1,163 @@
+package kerberos
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestExpandPrincipalTemplate(t *testing.T) {
+	values := &PrincipalValues{
+		Realm:          testRealm,
+		Namespace:      "default",
+		Pod:            "web-0",
+		ServiceAccount: "spark",
+		Node:           "node1",
+		ServiceNames:   []string{"HTTP", "hdfs"},
+		Hostnames:      []string{"web-0.web.default.svc.cluster.local", "web.default.svc.cluster.local"},
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "default",
+			template: DefaultPrincipalTemplate,
+			want: []string{
+				"HTTP/web-0.web.default.svc.cluster.local@EXAMPLE.COM",
+				"HTTP/web.default.svc.cluster.local@EXAMPLE.COM",
+				"hdfs/web-0.web.default.svc.cluster.local@EXAMPLE.COM",
+				"hdfs/web.default.svc.cluster.local@EXAMPLE.COM",
+			},
+		},
+		{
+			name:     "user principal",
+			template: "${serviceAccount}",
+			want:     []string{"spark@EXAMPLE.COM"},
+		},
+		{
+			name:     "host placeholder",
+			template: "nn/_HOST@${realm}",
+			want: []string{
+				"nn/web-0.web.default.svc.cluster.local@EXAMPLE.COM",
+				"nn/web.default.svc.cluster.local@EXAMPLE.COM",
+			},
+		},
+		{
+			name:     "fixed instance",
+			template: "${service}/${namespace}@OTHER.COM",
+			want:     []string{"HTTP/default@OTHER.COM", "hdfs/default@OTHER.COM"},
+		},
+		{
+			name:     "unknown variable",
+			template: "${foo}/${hostname}",
+			wantErr:  true,
+		},
+		{
+			name:     "whitespace",
+			template: "${pod} delprinc admin",
+			wantErr:  true,
+		},
+		{
+			name:     "option",
+			template: "-glob",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandPrincipalTemplate(tt.template, values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExpandPrincipalTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ExpandPrincipalTemplate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandPrincipalTemplateNoHostnames(t *testing.T) {
+	got, err := ExpandPrincipalTemplate(DefaultPrincipalTemplate, &PrincipalValues{Realm: testRealm, ServiceNames: []string{"HTTP"}})
+	if err != nil {
+		t.Fatalf("ExpandPrincipalTemplate() error = %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("ExpandPrincipalTemplate() = %v, want no principals", got)
+	}
+}
+
+func TestIsIPHostname(t *testing.T) {
+	tests := map[string]bool{
+		"10.244.0.5":                           true,
+		"10-244-0-5.default.pod.cluster.local": true,
+		"fd00--5.default.pod.cluster.local":    true,
+		"web-0.web.default.svc.cluster.local":  false,
+		"ip-10-0-0-1.ec2.internal":             false,
+		"node1":                                false,
+	}
+	for hostname, want := range tests {
+		if got := IsIPHostname(hostname); got != want {
+			t.Errorf("IsIPHostname(%q) = %v, want %v", hostname, got, want)
+		}
+	}
+}
+
+func TestPrincipalAttributesArgs(t *testing.T) {
+	attributes := &PrincipalAttributes{
+		Policy:       "services",
+		Flags:        []string{"+requires_preauth", "-allow_svr"},
+		MaxLife:      10 * time.Hour,
+		MaxRenewLife: 7 * 24 * time.Hour,
+	}
+
+	wantMIT := []string{
+		"-policy", "services",
+		"-maxlife", `"36000 seconds"`,
+		"-maxrenewlife", `"604800 seconds"`,
+		"+requires_preauth", "-allow_svr",
+	}
+	if got := attributes.mitArgs(); !slices.Equal(got, wantMIT) {
+		t.Errorf("mitArgs() = %v, want %v", got, wantMIT)
+	}
+
+	wantHeimdal := []string{
+		"--policy=services",
+		"--max-ticket-life=36000s",
+		"--max-renewable-life=604800s",
+		"--attributes=+requires-pre-auth,+disallow-svr",
+	}
+	got, err := heimdalAttributeArgs(attributes)
+	if err != nil {
+		t.Fatalf("heimdalAttributeArgs() error = %v", err)
+	}
+	if !slices.Equal(got, wantHeimdal) {
+		t.Errorf("heimdalAttributeArgs() = %v, want %v", got, wantHeimdal)
+	}
+
+	if _, err := heimdalAttributeArgs(&PrincipalAttributes{Flags: []string{"+lockdown_keys"}}); err == nil {
+		t.Error("heimdalAttributeArgs() expected error for unsupported flag")
+	}
+
+	var empty *PrincipalAttributes
+	if got := empty.mitArgs(); got != nil {
+		t.Errorf("mitArgs() of nil attributes = %v, want nil", got)
+	}
+}
+
+func TestKeysaltArgs(t *testing.T) {
+	kadmin := &Kadmin{}
+	if got := kadmin.keysaltArgs(); got != nil {
+		t.Errorf("keysaltArgs() without encryption types = %v, want nil", got)
+	}
+
+	kadmin.SetEncryptionTypes([]string{"aes256-cts-hmac-sha1-96", "aes128-cts-hmac-sha1-96"})
+	want := "addprinc -randkey -e aes256-cts-hmac-sha1-96:normal,aes128-cts-hmac-sha1-96:normal HTTP/web@EXAMPLE.COM"
+	if got := kadmin.addprincQuery("HTTP/web@EXAMPLE.COM"); got != want {
+		t.Errorf("addprincQuery() = %q, want %q", got, want)
+	}
+}
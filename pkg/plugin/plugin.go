@@ -0,0 +1,185 @@
+// Package plugin is the Go SDK of out-of-process backend plugins.
+//
+// A plugin implements Backend and serves it with Serve, usually as a sidecar of the csi pods on a unix socket:
+//
+//	type vaultBackend struct {
+//		plugin.AnyNode
+//	}
+//
+//	func (v *vaultBackend) GetSecretData(ctx context.Context, request *plugin.Request) (*plugin.SecretContent, error) {
+//		...
+//	}
+//
+//	func main() {
+//		ctx := ctrl.SetupSignalHandler()
+//		if err := plugin.Serve(ctx, "unix:///var/run/secret-operator/plugins/vault.sock", &vaultBackend{}); err != nil {
+//			os.Exit(1)
+//		}
+//	}
+//
+// SecretClasses use the plugin with the `plugin` backend and the same endpoint.
+//
+// The protocol has no authentication of its own, the plugin returns the secrets to every client reaching the endpoint.
+// A unix socket is only reachable by the containers mounting it. A plugin served on TCP must authenticate the caller,
+// e.g. with ServerTLS requiring the client certificate set in the `clientCertSecret` of the SecretClass:
+//
+//	creds, err := plugin.ServerTLS("/tls/tls.crt", "/tls/tls.key", "/tls/client-ca.crt")
+//	...
+//	err = plugin.Serve(ctx, "tcp://:9000", &vaultBackend{}, creds)
+package plugin
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/log"
+
+	pluginv1alpha1 "github.com/zncdatadev/secret-operator/pkg/plugin/v1alpha1"
+)
+
+var (
+	logger = ctrl.Log.WithName("backend-plugin")
+)
+
+// Request is a request of the csi driver for a volume.
+type Request struct {
+	Pod           *pluginv1alpha1.PodInfo
+	VolumeContext *pluginv1alpha1.VolumeContext
+	// Parameters of the plugin from the SecretClass.
+	Parameters map[string]string
+}
+
+// SecretContent is the content of a volume.
+type SecretContent struct {
+	// Files of the volume by file name.
+	Data map[string][]byte
+	// The pod is restarted before this time to get new secrets, nil when the secrets do not expire.
+	ExpiresTime *time.Time
+}
+
+// Backend provides the secrets of the volumes of a SecretClass.
+type Backend interface {
+	GetSecretData(ctx context.Context, request *Request) (*SecretContent, error)
+	// GetQualifiedNodeNames returns the nodes a volume can be mounted on, nil when any node qualifies.
+	GetQualifiedNodeNames(ctx context.Context, request *Request) ([]string, error)
+}
+
+// AnyNode can be embedded in backends whose volumes can be mounted on any node.
+type AnyNode struct{}
+
+func (AnyNode) GetQualifiedNodeNames(ctx context.Context, request *Request) ([]string, error) {
+	return nil, nil
+}
+
+var _ pluginv1alpha1.BackendServer = &server{}
+
+type server struct {
+	pluginv1alpha1.UnimplementedBackendServer
+	backend Backend
+}
+
+// NewServer returns the gRPC server of the backend, to register it on a custom grpc.Server.
+func NewServer(backend Backend) pluginv1alpha1.BackendServer {
+	return &server{backend: backend}
+}
+
+func (s *server) GetSecretData(ctx context.Context, in *pluginv1alpha1.GetSecretDataRequest) (*pluginv1alpha1.GetSecretDataResponse, error) {
+	content, err := s.backend.GetSecretData(ctx, &Request{Pod: in.Pod, VolumeContext: in.VolumeContext, Parameters: in.Parameters})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &pluginv1alpha1.GetSecretDataResponse{Data: content.Data}
+	if content.ExpiresTime != nil {
+		response.ExpiresTime = timestamppb.New(*content.ExpiresTime)
+	}
+	return response, nil
+}
+
+func (s *server) GetQualifiedNodeNames(
+	ctx context.Context,
+	in *pluginv1alpha1.GetQualifiedNodeNamesRequest,
+) (*pluginv1alpha1.GetQualifiedNodeNamesResponse, error) {
+	nodeNames, err := s.backend.GetQualifiedNodeNames(ctx, &Request{Pod: in.Pod, VolumeContext: in.VolumeContext, Parameters: in.Parameters})
+	if err != nil {
+		return nil, err
+	}
+	return &pluginv1alpha1.GetQualifiedNodeNamesResponse{NodeNames: nodeNames}, nil
+}
+
+// Listen listens on the endpoint, `unix:///path/to/socket`, `tcp://host:port` or `host:port`.
+// An existing unix socket file is removed, e.g. left by a previous container of the plugin.
+func Listen(endpoint string) (net.Listener, error) {
+	if path, ok := strings.CutPrefix(endpoint, "unix://"); ok {
+		if !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("invalid endpoint %q, the unix socket path must be absolute: unix:///path/to/socket", endpoint)
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to remove existing unix socket: %w", err)
+		}
+		return net.Listen("unix", path)
+	}
+	return net.Listen("tcp", strings.TrimPrefix(endpoint, "tcp://"))
+}
+
+// ServerTLS returns the server option serving TLS with the certificate and key files, and requiring client
+// certificates signed by the PEM trust roots of the client CA file, so only the csi driver gets the secrets.
+func ServerTLS(certFile, keyFile, clientCAFile string) (grpc.ServerOption, error) {
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server certificate: %w", err)
+	}
+
+	clientCA, err := os.ReadFile(clientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client ca: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(clientCA) {
+		return nil, fmt.Errorf("no certificates in client ca %s", clientCAFile)
+	}
+
+	return grpc.Creds(credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	})), nil
+}
+
+// Serve serves the backend and the gRPC health service on the endpoint until the context is done,
+// then stops gracefully. Requests and responses are not logged, they contain secrets.
+// On a TCP endpoint, pass ServerTLS or other credentials authenticating the caller, see the package documentation.
+func Serve(ctx context.Context, endpoint string, backend Backend, opts ...grpc.ServerOption) error {
+	listener, err := Listen(endpoint)
+	if err != nil {
+		return err
+	}
+
+	grpcServer := grpc.NewServer(opts...)
+	pluginv1alpha1.RegisterBackendServer(grpcServer, NewServer(backend))
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+
+	go func() {
+		<-ctx.Done()
+		logger.Info("stopping backend plugin", "endpoint", endpoint)
+		healthServer.Shutdown()
+		grpcServer.GracefulStop()
+	}()
+
+	logger.Info("serving backend plugin", "endpoint", endpoint)
+	return grpcServer.Serve(listener)
+}
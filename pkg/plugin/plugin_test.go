@@ -0,0 +1,182 @@
+package plugin
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/zncdatadev/secret-operator/internal/csi/backend/ca"
+	pluginv1alpha1 "github.com/zncdatadev/secret-operator/pkg/plugin/v1alpha1"
+	"github.com/zncdatadev/secret-operator/pkg/pod_info"
+)
+
+type testBackend struct {
+	expiresTime *time.Time
+}
+
+func (b *testBackend) GetSecretData(ctx context.Context, request *Request) (*SecretContent, error) {
+	return &SecretContent{
+		Data: map[string][]byte{
+			"password": []byte(request.Parameters["password"]),
+			"pod":      []byte(request.Pod.Namespace + "/" + request.Pod.Name),
+		},
+		ExpiresTime: b.expiresTime,
+	}, nil
+}
+
+func (b *testBackend) GetQualifiedNodeNames(ctx context.Context, request *Request) ([]string, error) {
+	return []string{"node-" + request.VolumeContext.Class}, nil
+}
+
+func TestServe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	expiresTime := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	endpoint := "unix://" + filepath.Join(t.TempDir(), "plugin.sock")
+
+	served := make(chan error, 1)
+	go func() {
+		served <- Serve(ctx, endpoint, &testBackend{expiresTime: &expiresTime})
+	}()
+
+	conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	client := pluginv1alpha1.NewBackendClient(conn)
+
+	callCtx, callCancel := context.WithTimeout(ctx, 10*time.Second)
+	defer callCancel()
+
+	request := &pluginv1alpha1.GetSecretDataRequest{
+		Pod:           &pluginv1alpha1.PodInfo{Name: "pod-a", Namespace: "ns-a"},
+		VolumeContext: &pluginv1alpha1.VolumeContext{Class: "vault"},
+		Parameters:    map[string]string{"password": "secret"},
+	}
+	response, err := client.GetSecretData(callCtx, request, grpc.WaitForReady(true))
+	if err != nil {
+		t.Fatalf("GetSecretData() error = %v", err)
+	}
+	if got := string(response.Data["password"]); got != "secret" {
+		t.Errorf("password = %q, want %q", got, "secret")
+	}
+	if got := string(response.Data["pod"]); got != "ns-a/pod-a" {
+		t.Errorf("pod = %q, want %q", got, "ns-a/pod-a")
+	}
+	if response.ExpiresTime == nil || !response.ExpiresTime.AsTime().Equal(expiresTime) {
+		t.Errorf("expiresTime = %v, want %v", response.ExpiresTime, expiresTime)
+	}
+
+	nodes, err := client.GetQualifiedNodeNames(callCtx, &pluginv1alpha1.GetQualifiedNodeNamesRequest{
+		VolumeContext: &pluginv1alpha1.VolumeContext{Class: "vault"},
+	})
+	if err != nil {
+		t.Fatalf("GetQualifiedNodeNames() error = %v", err)
+	}
+	if len(nodes.NodeNames) != 1 || nodes.NodeNames[0] != "node-vault" {
+		t.Errorf("nodeNames = %v, want [node-vault]", nodes.NodeNames)
+	}
+
+	cancel()
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("Serve() error = %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Serve() did not stop after the context was canceled")
+	}
+}
+
+func TestServeNoExpiry(t *testing.T) {
+	response, err := NewServer(&testBackend{}).GetSecretData(context.Background(), &pluginv1alpha1.GetSecretDataRequest{
+		Pod: &pluginv1alpha1.PodInfo{Name: "pod-a", Namespace: "ns-a"},
+	})
+	if err != nil {
+		t.Fatalf("GetSecretData() error = %v", err)
+	}
+	if response.ExpiresTime != nil {
+		t.Errorf("expiresTime = %v, want nil", response.ExpiresTime)
+	}
+}
+
+func TestServerTLS(t *testing.T) {
+	notAfter := time.Now().Add(time.Hour)
+	authority, err := ca.NewSelfSignedCertificateAuthority(notAfter, nil, nil, 2048)
+	if err != nil {
+		t.Fatalf("NewSelfSignedCertificateAuthority() error = %v", err)
+	}
+	serverCert, err := authority.SignServerCertificate([]pod_info.Address{{IP: net.ParseIP("127.0.0.1")}}, notAfter)
+	if err != nil {
+		t.Fatalf("SignServerCertificate() error = %v", err)
+	}
+	clientCert, err := authority.SignClientCertificate([]pod_info.Address{{Hostname: "csi"}}, notAfter)
+	if err != nil {
+		t.Fatalf("SignClientCertificate() error = %v", err)
+	}
+
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"tls.crt":       serverCert.CertificatePEM(),
+		"tls.key":       serverCert.PrivateKeyPEM(),
+		"client-ca.crt": authority.CertificatePEM(),
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	creds, err := ServerTLS(filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "client-ca.crt"))
+	if err != nil {
+		t.Fatalf("ServerTLS() error = %v", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcServer := grpc.NewServer(creds)
+	pluginv1alpha1.RegisterBackendServer(grpcServer, NewServer(&testBackend{}))
+	go func() { _ = grpcServer.Serve(listener) }()
+	defer grpcServer.Stop()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(authority.Certificate)
+	getSecretData := func(certificates []tls.Certificate) error {
+		tlsConfig := &tls.Config{RootCAs: roots, Certificates: certificates, MinVersion: tls.VersionTLS12}
+		conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+		if err != nil {
+			return err
+		}
+		defer func() { _ = conn.Close() }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_, err = pluginv1alpha1.NewBackendClient(conn).GetSecretData(ctx, &pluginv1alpha1.GetSecretDataRequest{
+			Pod: &pluginv1alpha1.PodInfo{Name: "pod-a", Namespace: "ns-a"},
+		})
+		return err
+	}
+
+	keyPair, err := tls.X509KeyPair(clientCert.CertificatePEM(), clientCert.PrivateKeyPEM())
+	if err != nil {
+		t.Fatalf("X509KeyPair() error = %v", err)
+	}
+	if err := getSecretData([]tls.Certificate{keyPair}); err != nil {
+		t.Errorf("GetSecretData() with client certificate error = %v", err)
+	}
+	if err := getSecretData(nil); err == nil {
+		t.Error("GetSecretData() expected error without client certificate")
+	}
+}
@@ -0,0 +1,705 @@
+// Backend plugin protocol of the secret-operator.
+//
+// A plugin serves the Backend service, on a unix socket shared with the csi pods or on a service endpoint.
+// The csi node plugin calls GetSecretData when a volume is published, the csi controller calls
+// GetQualifiedNodeNames when a volume is provisioned. Breaking changes get a new version of the package.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: pkg/plugin/v1alpha1/backend.proto
+
+package v1alpha1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Address is a hostname or ip address of the scope of a volume.
+type Address struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hostname      string                 `protobuf:"bytes,1,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Ip            string                 `protobuf:"bytes,2,opt,name=ip,proto3" json:"ip,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Address) Reset() {
+	*x = Address{}
+	mi := &file_pkg_plugin_v1alpha1_backend_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Address) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Address) ProtoMessage() {}
+
+func (x *Address) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_plugin_v1alpha1_backend_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Address.ProtoReflect.Descriptor instead.
+func (*Address) Descriptor() ([]byte, []int) {
+	return file_pkg_plugin_v1alpha1_backend_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Address) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *Address) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+// PodInfo describes the pod a volume is mounted in.
+type PodInfo struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Name               string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Namespace          string                 `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Uid                string                 `protobuf:"bytes,3,opt,name=uid,proto3" json:"uid,omitempty"`
+	ServiceAccountName string                 `protobuf:"bytes,4,opt,name=service_account_name,json=serviceAccountName,proto3" json:"service_account_name,omitempty"`
+	// Empty when the volume is provisioned before the pod is scheduled.
+	NodeName    string            `protobuf:"bytes,5,opt,name=node_name,json=nodeName,proto3" json:"node_name,omitempty"`
+	Labels      map[string]string `protobuf:"bytes,6,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Annotations map[string]string `protobuf:"bytes,7,rep,name=annotations,proto3" json:"annotations,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	PodIps      []string          `protobuf:"bytes,8,rep,name=pod_ips,json=podIps,proto3" json:"pod_ips,omitempty"`
+	// Addresses of the scope of the volume, e.g. the pod fqdn, service names, listener and node addresses.
+	// Only set in GetSecretDataRequest.
+	ScopedAddresses []*Address `protobuf:"bytes,9,rep,name=scoped_addresses,json=scopedAddresses,proto3" json:"scoped_addresses,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *PodInfo) Reset() {
+	*x = PodInfo{}
+	mi := &file_pkg_plugin_v1alpha1_backend_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PodInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PodInfo) ProtoMessage() {}
+
+func (x *PodInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_plugin_v1alpha1_backend_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PodInfo.ProtoReflect.Descriptor instead.
+func (*PodInfo) Descriptor() ([]byte, []int) {
+	return file_pkg_plugin_v1alpha1_backend_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PodInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PodInfo) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *PodInfo) GetUid() string {
+	if x != nil {
+		return x.Uid
+	}
+	return ""
+}
+
+func (x *PodInfo) GetServiceAccountName() string {
+	if x != nil {
+		return x.ServiceAccountName
+	}
+	return ""
+}
+
+func (x *PodInfo) GetNodeName() string {
+	if x != nil {
+		return x.NodeName
+	}
+	return ""
+}
+
+func (x *PodInfo) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *PodInfo) GetAnnotations() map[string]string {
+	if x != nil {
+		return x.Annotations
+	}
+	return nil
+}
+
+func (x *PodInfo) GetPodIps() []string {
+	if x != nil {
+		return x.PodIps
+	}
+	return nil
+}
+
+func (x *PodInfo) GetScopedAddresses() []*Address {
+	if x != nil {
+		return x.ScopedAddresses
+	}
+	return nil
+}
+
+// Scope of a volume, from the `secrets.kubedoop.dev/scope` volume attribute.
+type Scope struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Pod             bool                   `protobuf:"varint,1,opt,name=pod,proto3" json:"pod,omitempty"`
+	Node            bool                   `protobuf:"varint,2,opt,name=node,proto3" json:"node,omitempty"`
+	Services        []string               `protobuf:"bytes,3,rep,name=services,proto3" json:"services,omitempty"`
+	ListenerVolumes []string               `protobuf:"bytes,4,rep,name=listener_volumes,json=listenerVolumes,proto3" json:"listener_volumes,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Scope) Reset() {
+	*x = Scope{}
+	mi := &file_pkg_plugin_v1alpha1_backend_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Scope) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Scope) ProtoMessage() {}
+
+func (x *Scope) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_plugin_v1alpha1_backend_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Scope.ProtoReflect.Descriptor instead.
+func (*Scope) Descriptor() ([]byte, []int) {
+	return file_pkg_plugin_v1alpha1_backend_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Scope) GetPod() bool {
+	if x != nil {
+		return x.Pod
+	}
+	return false
+}
+
+func (x *Scope) GetNode() bool {
+	if x != nil {
+		return x.Node
+	}
+	return false
+}
+
+func (x *Scope) GetServices() []string {
+	if x != nil {
+		return x.Services
+	}
+	return nil
+}
+
+func (x *Scope) GetListenerVolumes() []string {
+	if x != nil {
+		return x.ListenerVolumes
+	}
+	return nil
+}
+
+// VolumeContext describes the volume.
+type VolumeContext struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the SecretClass.
+	Class string `protobuf:"bytes,1,opt,name=class,proto3" json:"class,omitempty"`
+	Scope *Scope `protobuf:"bytes,2,opt,name=scope,proto3" json:"scope,omitempty"`
+	// Format of the secrets, from the `secrets.kubedoop.dev/format` volume attribute.
+	Format string `protobuf:"bytes,3,opt,name=format,proto3" json:"format,omitempty"`
+	// Attributes of the volume known to the operator, e.g. `secrets.kubedoop.dev/kerberosServiceNames`.
+	Attributes    map[string]string `protobuf:"bytes,4,rep,name=attributes,proto3" json:"attributes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VolumeContext) Reset() {
+	*x = VolumeContext{}
+	mi := &file_pkg_plugin_v1alpha1_backend_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VolumeContext) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VolumeContext) ProtoMessage() {}
+
+func (x *VolumeContext) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_plugin_v1alpha1_backend_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VolumeContext.ProtoReflect.Descriptor instead.
+func (*VolumeContext) Descriptor() ([]byte, []int) {
+	return file_pkg_plugin_v1alpha1_backend_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *VolumeContext) GetClass() string {
+	if x != nil {
+		return x.Class
+	}
+	return ""
+}
+
+func (x *VolumeContext) GetScope() *Scope {
+	if x != nil {
+		return x.Scope
+	}
+	return nil
+}
+
+func (x *VolumeContext) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *VolumeContext) GetAttributes() map[string]string {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+type GetSecretDataRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pod           *PodInfo               `protobuf:"bytes,1,opt,name=pod,proto3" json:"pod,omitempty"`
+	VolumeContext *VolumeContext         `protobuf:"bytes,2,opt,name=volume_context,json=volumeContext,proto3" json:"volume_context,omitempty"`
+	// Parameters of the plugin from the SecretClass.
+	Parameters    map[string]string `protobuf:"bytes,3,rep,name=parameters,proto3" json:"parameters,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSecretDataRequest) Reset() {
+	*x = GetSecretDataRequest{}
+	mi := &file_pkg_plugin_v1alpha1_backend_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSecretDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSecretDataRequest) ProtoMessage() {}
+
+func (x *GetSecretDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_plugin_v1alpha1_backend_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSecretDataRequest.ProtoReflect.Descriptor instead.
+func (*GetSecretDataRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_plugin_v1alpha1_backend_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetSecretDataRequest) GetPod() *PodInfo {
+	if x != nil {
+		return x.Pod
+	}
+	return nil
+}
+
+func (x *GetSecretDataRequest) GetVolumeContext() *VolumeContext {
+	if x != nil {
+		return x.VolumeContext
+	}
+	return nil
+}
+
+func (x *GetSecretDataRequest) GetParameters() map[string]string {
+	if x != nil {
+		return x.Parameters
+	}
+	return nil
+}
+
+type GetSecretDataResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Files of the volume by file name.
+	Data map[string][]byte `protobuf:"bytes,1,rep,name=data,proto3" json:"data,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// The pod is restarted before this time to get new secrets, unset when the secrets do not expire.
+	ExpiresTime   *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=expires_time,json=expiresTime,proto3" json:"expires_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSecretDataResponse) Reset() {
+	*x = GetSecretDataResponse{}
+	mi := &file_pkg_plugin_v1alpha1_backend_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSecretDataResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSecretDataResponse) ProtoMessage() {}
+
+func (x *GetSecretDataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_plugin_v1alpha1_backend_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSecretDataResponse.ProtoReflect.Descriptor instead.
+func (*GetSecretDataResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_plugin_v1alpha1_backend_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetSecretDataResponse) GetData() map[string][]byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *GetSecretDataResponse) GetExpiresTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresTime
+	}
+	return nil
+}
+
+type GetQualifiedNodeNamesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pod           *PodInfo               `protobuf:"bytes,1,opt,name=pod,proto3" json:"pod,omitempty"`
+	VolumeContext *VolumeContext         `protobuf:"bytes,2,opt,name=volume_context,json=volumeContext,proto3" json:"volume_context,omitempty"`
+	// Parameters of the plugin from the SecretClass.
+	Parameters    map[string]string `protobuf:"bytes,3,rep,name=parameters,proto3" json:"parameters,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetQualifiedNodeNamesRequest) Reset() {
+	*x = GetQualifiedNodeNamesRequest{}
+	mi := &file_pkg_plugin_v1alpha1_backend_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetQualifiedNodeNamesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQualifiedNodeNamesRequest) ProtoMessage() {}
+
+func (x *GetQualifiedNodeNamesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_plugin_v1alpha1_backend_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQualifiedNodeNamesRequest.ProtoReflect.Descriptor instead.
+func (*GetQualifiedNodeNamesRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_plugin_v1alpha1_backend_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetQualifiedNodeNamesRequest) GetPod() *PodInfo {
+	if x != nil {
+		return x.Pod
+	}
+	return nil
+}
+
+func (x *GetQualifiedNodeNamesRequest) GetVolumeContext() *VolumeContext {
+	if x != nil {
+		return x.VolumeContext
+	}
+	return nil
+}
+
+func (x *GetQualifiedNodeNamesRequest) GetParameters() map[string]string {
+	if x != nil {
+		return x.Parameters
+	}
+	return nil
+}
+
+type GetQualifiedNodeNamesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Names of the nodes the volume can be mounted on, empty when the volume can be mounted on any node.
+	NodeNames     []string `protobuf:"bytes,1,rep,name=node_names,json=nodeNames,proto3" json:"node_names,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetQualifiedNodeNamesResponse) Reset() {
+	*x = GetQualifiedNodeNamesResponse{}
+	mi := &file_pkg_plugin_v1alpha1_backend_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetQualifiedNodeNamesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQualifiedNodeNamesResponse) ProtoMessage() {}
+
+func (x *GetQualifiedNodeNamesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_plugin_v1alpha1_backend_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQualifiedNodeNamesResponse.ProtoReflect.Descriptor instead.
+func (*GetQualifiedNodeNamesResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_plugin_v1alpha1_backend_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetQualifiedNodeNamesResponse) GetNodeNames() []string {
+	if x != nil {
+		return x.NodeNames
+	}
+	return nil
+}
+
+var File_pkg_plugin_v1alpha1_backend_proto protoreflect.FileDescriptor
+
+const file_pkg_plugin_v1alpha1_backend_proto_rawDesc = "" +
+	"\n" +
+	"!pkg/plugin/v1alpha1/backend.proto\x12$secrets.kubedoop.dev.plugin.v1alpha1\x1a\x1fgoogle/protobuf/timestamp.proto\"5\n" +
+	"\aAddress\x12\x1a\n" +
+	"\bhostname\x18\x01 \x01(\tR\bhostname\x12\x0e\n" +
+	"\x02ip\x18\x02 \x01(\tR\x02ip\"\xbf\x04\n" +
+	"\aPodInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1c\n" +
+	"\tnamespace\x18\x02 \x01(\tR\tnamespace\x12\x10\n" +
+	"\x03uid\x18\x03 \x01(\tR\x03uid\x120\n" +
+	"\x14service_account_name\x18\x04 \x01(\tR\x12serviceAccountName\x12\x1b\n" +
+	"\tnode_name\x18\x05 \x01(\tR\bnodeName\x12Q\n" +
+	"\x06labels\x18\x06 \x03(\v29.secrets.kubedoop.dev.plugin.v1alpha1.PodInfo.LabelsEntryR\x06labels\x12`\n" +
+	"\vannotations\x18\a \x03(\v2>.secrets.kubedoop.dev.plugin.v1alpha1.PodInfo.AnnotationsEntryR\vannotations\x12\x17\n" +
+	"\apod_ips\x18\b \x03(\tR\x06podIps\x12X\n" +
+	"\x10scoped_addresses\x18\t \x03(\v2-.secrets.kubedoop.dev.plugin.v1alpha1.AddressR\x0fscopedAddresses\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a>\n" +
+	"\x10AnnotationsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"t\n" +
+	"\x05Scope\x12\x10\n" +
+	"\x03pod\x18\x01 \x01(\bR\x03pod\x12\x12\n" +
+	"\x04node\x18\x02 \x01(\bR\x04node\x12\x1a\n" +
+	"\bservices\x18\x03 \x03(\tR\bservices\x12)\n" +
+	"\x10listener_volumes\x18\x04 \x03(\tR\x0flistenerVolumes\"\xa4\x02\n" +
+	"\rVolumeContext\x12\x14\n" +
+	"\x05class\x18\x01 \x01(\tR\x05class\x12A\n" +
+	"\x05scope\x18\x02 \x01(\v2+.secrets.kubedoop.dev.plugin.v1alpha1.ScopeR\x05scope\x12\x16\n" +
+	"\x06format\x18\x03 \x01(\tR\x06format\x12c\n" +
+	"\n" +
+	"attributes\x18\x04 \x03(\v2C.secrets.kubedoop.dev.plugin.v1alpha1.VolumeContext.AttributesEntryR\n" +
+	"attributes\x1a=\n" +
+	"\x0fAttributesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xde\x02\n" +
+	"\x14GetSecretDataRequest\x12?\n" +
+	"\x03pod\x18\x01 \x01(\v2-.secrets.kubedoop.dev.plugin.v1alpha1.PodInfoR\x03pod\x12Z\n" +
+	"\x0evolume_context\x18\x02 \x01(\v23.secrets.kubedoop.dev.plugin.v1alpha1.VolumeContextR\rvolumeContext\x12j\n" +
+	"\n" +
+	"parameters\x18\x03 \x03(\v2J.secrets.kubedoop.dev.plugin.v1alpha1.GetSecretDataRequest.ParametersEntryR\n" +
+	"parameters\x1a=\n" +
+	"\x0fParametersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xea\x01\n" +
+	"\x15GetSecretDataResponse\x12Y\n" +
+	"\x04data\x18\x01 \x03(\v2E.secrets.kubedoop.dev.plugin.v1alpha1.GetSecretDataResponse.DataEntryR\x04data\x12=\n" +
+	"\fexpires_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\vexpiresTime\x1a7\n" +
+	"\tDataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value:\x028\x01\"\xee\x02\n" +
+	"\x1cGetQualifiedNodeNamesRequest\x12?\n" +
+	"\x03pod\x18\x01 \x01(\v2-.secrets.kubedoop.dev.plugin.v1alpha1.PodInfoR\x03pod\x12Z\n" +
+	"\x0evolume_context\x18\x02 \x01(\v23.secrets.kubedoop.dev.plugin.v1alpha1.VolumeContextR\rvolumeContext\x12r\n" +
+	"\n" +
+	"parameters\x18\x03 \x03(\v2R.secrets.kubedoop.dev.plugin.v1alpha1.GetQualifiedNodeNamesRequest.ParametersEntryR\n" +
+	"parameters\x1a=\n" +
+	"\x0fParametersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\">\n" +
+	"\x1dGetQualifiedNodeNamesResponse\x12\x1d\n" +
+	"\n" +
+	"node_names\x18\x01 \x03(\tR\tnodeNames2\xb7\x02\n" +
+	"\aBackend\x12\x88\x01\n" +
+	"\rGetSecretData\x12:.secrets.kubedoop.dev.plugin.v1alpha1.GetSecretDataRequest\x1a;.secrets.kubedoop.dev.plugin.v1alpha1.GetSecretDataResponse\x12\xa0\x01\n" +
+	"\x15GetQualifiedNodeNames\x12B.secrets.kubedoop.dev.plugin.v1alpha1.GetQualifiedNodeNamesRequest\x1aC.secrets.kubedoop.dev.plugin.v1alpha1.GetQualifiedNodeNamesResponseB;Z9github.com/zncdatadev/secret-operator/pkg/plugin/v1alpha1b\x06proto3"
+
+var (
+	file_pkg_plugin_v1alpha1_backend_proto_rawDescOnce sync.Once
+	file_pkg_plugin_v1alpha1_backend_proto_rawDescData []byte
+)
+
+func file_pkg_plugin_v1alpha1_backend_proto_rawDescGZIP() []byte {
+	file_pkg_plugin_v1alpha1_backend_proto_rawDescOnce.Do(func() {
+		file_pkg_plugin_v1alpha1_backend_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_pkg_plugin_v1alpha1_backend_proto_rawDesc), len(file_pkg_plugin_v1alpha1_backend_proto_rawDesc)))
+	})
+	return file_pkg_plugin_v1alpha1_backend_proto_rawDescData
+}
+
+var file_pkg_plugin_v1alpha1_backend_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_pkg_plugin_v1alpha1_backend_proto_goTypes = []any{
+	(*Address)(nil),                       // 0: secrets.kubedoop.dev.plugin.v1alpha1.Address
+	(*PodInfo)(nil),                       // 1: secrets.kubedoop.dev.plugin.v1alpha1.PodInfo
+	(*Scope)(nil),                         // 2: secrets.kubedoop.dev.plugin.v1alpha1.Scope
+	(*VolumeContext)(nil),                 // 3: secrets.kubedoop.dev.plugin.v1alpha1.VolumeContext
+	(*GetSecretDataRequest)(nil),          // 4: secrets.kubedoop.dev.plugin.v1alpha1.GetSecretDataRequest
+	(*GetSecretDataResponse)(nil),         // 5: secrets.kubedoop.dev.plugin.v1alpha1.GetSecretDataResponse
+	(*GetQualifiedNodeNamesRequest)(nil),  // 6: secrets.kubedoop.dev.plugin.v1alpha1.GetQualifiedNodeNamesRequest
+	(*GetQualifiedNodeNamesResponse)(nil), // 7: secrets.kubedoop.dev.plugin.v1alpha1.GetQualifiedNodeNamesResponse
+	nil,                                   // 8: secrets.kubedoop.dev.plugin.v1alpha1.PodInfo.LabelsEntry
+	nil,                                   // 9: secrets.kubedoop.dev.plugin.v1alpha1.PodInfo.AnnotationsEntry
+	nil,                                   // 10: secrets.kubedoop.dev.plugin.v1alpha1.VolumeContext.AttributesEntry
+	nil,                                   // 11: secrets.kubedoop.dev.plugin.v1alpha1.GetSecretDataRequest.ParametersEntry
+	nil,                                   // 12: secrets.kubedoop.dev.plugin.v1alpha1.GetSecretDataResponse.DataEntry
+	nil,                                   // 13: secrets.kubedoop.dev.plugin.v1alpha1.GetQualifiedNodeNamesRequest.ParametersEntry
+	(*timestamppb.Timestamp)(nil),         // 14: google.protobuf.Timestamp
+}
+var file_pkg_plugin_v1alpha1_backend_proto_depIdxs = []int32{
+	8,  // 0: secrets.kubedoop.dev.plugin.v1alpha1.PodInfo.labels:type_name -> secrets.kubedoop.dev.plugin.v1alpha1.PodInfo.LabelsEntry
+	9,  // 1: secrets.kubedoop.dev.plugin.v1alpha1.PodInfo.annotations:type_name -> secrets.kubedoop.dev.plugin.v1alpha1.PodInfo.AnnotationsEntry
+	0,  // 2: secrets.kubedoop.dev.plugin.v1alpha1.PodInfo.scoped_addresses:type_name -> secrets.kubedoop.dev.plugin.v1alpha1.Address
+	2,  // 3: secrets.kubedoop.dev.plugin.v1alpha1.VolumeContext.scope:type_name -> secrets.kubedoop.dev.plugin.v1alpha1.Scope
+	10, // 4: secrets.kubedoop.dev.plugin.v1alpha1.VolumeContext.attributes:type_name -> secrets.kubedoop.dev.plugin.v1alpha1.VolumeContext.AttributesEntry
+	1,  // 5: secrets.kubedoop.dev.plugin.v1alpha1.GetSecretDataRequest.pod:type_name -> secrets.kubedoop.dev.plugin.v1alpha1.PodInfo
+	3,  // 6: secrets.kubedoop.dev.plugin.v1alpha1.GetSecretDataRequest.volume_context:type_name -> secrets.kubedoop.dev.plugin.v1alpha1.VolumeContext
+	11, // 7: secrets.kubedoop.dev.plugin.v1alpha1.GetSecretDataRequest.parameters:type_name -> secrets.kubedoop.dev.plugin.v1alpha1.GetSecretDataRequest.ParametersEntry
+	12, // 8: secrets.kubedoop.dev.plugin.v1alpha1.GetSecretDataResponse.data:type_name -> secrets.kubedoop.dev.plugin.v1alpha1.GetSecretDataResponse.DataEntry
+	14, // 9: secrets.kubedoop.dev.plugin.v1alpha1.GetSecretDataResponse.expires_time:type_name -> google.protobuf.Timestamp
+	1,  // 10: secrets.kubedoop.dev.plugin.v1alpha1.GetQualifiedNodeNamesRequest.pod:type_name -> secrets.kubedoop.dev.plugin.v1alpha1.PodInfo
+	3,  // 11: secrets.kubedoop.dev.plugin.v1alpha1.GetQualifiedNodeNamesRequest.volume_context:type_name -> secrets.kubedoop.dev.plugin.v1alpha1.VolumeContext
+	13, // 12: secrets.kubedoop.dev.plugin.v1alpha1.GetQualifiedNodeNamesRequest.parameters:type_name -> secrets.kubedoop.dev.plugin.v1alpha1.GetQualifiedNodeNamesRequest.ParametersEntry
+	4,  // 13: secrets.kubedoop.dev.plugin.v1alpha1.Backend.GetSecretData:input_type -> secrets.kubedoop.dev.plugin.v1alpha1.GetSecretDataRequest
+	6,  // 14: secrets.kubedoop.dev.plugin.v1alpha1.Backend.GetQualifiedNodeNames:input_type -> secrets.kubedoop.dev.plugin.v1alpha1.GetQualifiedNodeNamesRequest
+	5,  // 15: secrets.kubedoop.dev.plugin.v1alpha1.Backend.GetSecretData:output_type -> secrets.kubedoop.dev.plugin.v1alpha1.GetSecretDataResponse
+	7,  // 16: secrets.kubedoop.dev.plugin.v1alpha1.Backend.GetQualifiedNodeNames:output_type -> secrets.kubedoop.dev.plugin.v1alpha1.GetQualifiedNodeNamesResponse
+	15, // [15:17] is the sub-list for method output_type
+	13, // [13:15] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_pkg_plugin_v1alpha1_backend_proto_init() }
+func file_pkg_plugin_v1alpha1_backend_proto_init() {
+	if File_pkg_plugin_v1alpha1_backend_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pkg_plugin_v1alpha1_backend_proto_rawDesc), len(file_pkg_plugin_v1alpha1_backend_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_pkg_plugin_v1alpha1_backend_proto_goTypes,
+		DependencyIndexes: file_pkg_plugin_v1alpha1_backend_proto_depIdxs,
+		MessageInfos:      file_pkg_plugin_v1alpha1_backend_proto_msgTypes,
+	}.Build()
+	File_pkg_plugin_v1alpha1_backend_proto = out.File
+	file_pkg_plugin_v1alpha1_backend_proto_goTypes = nil
+	file_pkg_plugin_v1alpha1_backend_proto_depIdxs = nil
+}
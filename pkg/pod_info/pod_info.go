@@ -8,6 +8,7 @@ import (
 
 	operatorlistenersv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/listeners/v1alpha1"
 	"github.com/zncdatadev/operator-go/pkg/constants"
+	"github.com/zncdatadev/secret-operator/pkg/util"
 	"github.com/zncdatadev/secret-operator/pkg/volume"
 	corev1 "k8s.io/api/core/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -81,9 +82,9 @@ func (p *PodInfo) getNodeAddresses(ctx context.Context) ([]Address, error) {
 	return addresses, nil
 }
 
-// TODO: Dynamic get cluster domain, currently hard code to cluster.local
+// getFQDN returns the service dns name in the pod namespace, with the discovered cluster domain
 func (p *PodInfo) getFQDN(subdomain string) string {
-	return fmt.Sprintf("%s.%s.svc.cluster.local", subdomain, p.getPodNamespace())
+	return fmt.Sprintf("%s.%s.svc.%s", subdomain, p.getPodNamespace(), util.GetClusterDomain())
 }
 
 func (p *PodInfo) getFQDNAddress(subdomain string) Address {
@@ -0,0 +1,60 @@
+package pod_info
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/zncdatadev/secret-operator/pkg/util"
+)
+
+func TestGetFQDNClusterDomain(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "apps"},
+		Spec:       corev1.PodSpec{Subdomain: "web"},
+	}
+	p := NewPodInfo(nil, pod, nil)
+
+	tests := []struct {
+		name   string
+		domain string
+		want   []string
+	}{
+		{
+			name: "default cluster domain",
+			want: []string{"web.apps.svc.cluster.local", "web-0.web.apps.svc.cluster.local"},
+		},
+		{
+			name:   "custom cluster domain",
+			domain: "k8s.example.com.",
+			want:   []string{"web.apps.svc.k8s.example.com", "web-0.web.apps.svc.k8s.example.com"},
+		},
+	}
+
+	defer util.SetClusterDomain("")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			util.SetClusterDomain(tt.domain)
+
+			if got := p.getFQDN("web"); got != tt.want[0] {
+				t.Errorf("getFQDN() = %s, want %s", got, tt.want[0])
+			}
+			addresses, err := p.getPodAddresses()
+			if err != nil {
+				t.Fatalf("getPodAddresses() error = %v", err)
+			}
+			if len(addresses) != len(tt.want) {
+				t.Fatalf("getPodAddresses() = %v, want %v", addresses, tt.want)
+			}
+			for i, address := range addresses {
+				if address.Hostname != tt.want[i] {
+					t.Errorf("getPodAddresses()[%d] = %s, want %s", i, address.Hostname, tt.want[i])
+				}
+			}
+			if got := p.getServiceAddresses([]string{"web"}); got[0].Hostname != tt.want[0] {
+				t.Errorf("getServiceAddresses() = %v, want %s", got, tt.want[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package util
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	DefaultClusterDomain = "cluster.local"
+
+	// ClusterDomainEnv is the environment variable to set the cluster domain, when the flag is not set.
+	ClusterDomainEnv = "KUBERNETES_CLUSTER_DOMAIN"
+
+	resolvConfPath = "/etc/resolv.conf"
+)
+
+var clusterDomain = DefaultClusterDomain
+
+// SetClusterDomain sets the domain of the kubernetes cluster dns, e.g. `cluster.local`.
+// It must be called before any secret is provisioned.
+func SetClusterDomain(domain string) {
+	domain = strings.Trim(domain, ".")
+	if domain == "" {
+		domain = DefaultClusterDomain
+	}
+	clusterDomain = domain
+}
+
+// GetClusterDomain returns the domain of the kubernetes cluster dns, services are resolved as
+// `<service>.<namespace>.svc.<cluster domain>`.
+func GetClusterDomain() string {
+	return clusterDomain
+}
+
+// DiscoverClusterDomain returns the first cluster domain found in:
+//   - the given domain, e.g. from a command line flag
+//   - the KUBERNETES_CLUSTER_DOMAIN environment variable
+//   - the search domains in /etc/resolv.conf, kubelet adds `svc.<cluster domain>` for pods using the cluster dns
+//   - the default `cluster.local`
+func DiscoverClusterDomain(domain string) string {
+	if domain != "" {
+		return domain
+	}
+	if domain := os.Getenv(ClusterDomainEnv); domain != "" {
+		return domain
+	}
+
+	f, err := os.Open(resolvConfPath)
+	if err != nil {
+		return DefaultClusterDomain
+	}
+	defer func() { _ = f.Close() }()
+
+	if domain, ok := parseClusterDomain(f); ok {
+		return domain
+	}
+	return DefaultClusterDomain
+}
+
+// parseClusterDomain finds the `svc.<cluster domain>` search domain in a resolv.conf.
+func parseClusterDomain(resolvConf io.Reader) (string, bool) {
+	scanner := bufio.NewScanner(resolvConf)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] != "search" {
+			continue
+		}
+		for _, search := range fields[1:] {
+			if domain, ok := strings.CutPrefix(strings.TrimSuffix(search, "."), "svc."); ok && domain != "" {
+				return domain, true
+			}
+		}
+	}
+	return "", false
+}
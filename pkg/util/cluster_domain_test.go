@@ -0,0 +1,41 @@
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseClusterDomain(t *testing.T) {
+	tests := []struct {
+		name       string
+		resolvConf string
+		want       string
+		wantOK     bool
+	}{
+		{
+			name:       "default cluster domain",
+			resolvConf: "search default.svc.cluster.local svc.cluster.local cluster.local\nnameserver 10.96.0.10\noptions ndots:5\n",
+			want:       "cluster.local",
+			wantOK:     true,
+		},
+		{
+			name:       "custom cluster domain",
+			resolvConf: "nameserver 10.96.0.10\nsearch kubedoop.svc.k8s.example.com. svc.k8s.example.com. k8s.example.com. ec2.internal\n",
+			want:       "k8s.example.com",
+			wantOK:     true,
+		},
+		{
+			name:       "host network",
+			resolvConf: "nameserver 8.8.8.8\nsearch ec2.internal\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseClusterDomain(strings.NewReader(tt.resolvConf))
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("parseClusterDomain() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -15,6 +15,11 @@ var (
 
 const (
 	KerberosServiceNamesSplitter string = ","
+	KerberosPrincipalsSplitter   string = ","
+
+	// AnnotationSecretsKerberosPrincipals replaces the principal templates of the SecretClass,
+	// when the SecretClass allows volumes to override them.
+	AnnotationSecretsKerberosPrincipals string = "secrets.kubedoop.dev/kerberosPrincipals"
 )
 
 type SecretFormat string
@@ -23,6 +28,8 @@ const (
 	SecretFormatTLSPEM   SecretFormat = "tls-pem"
 	SecretFormatTLSP12   SecretFormat = "tls-p12"
 	SecretFormatKerberos SecretFormat = "kerberos"
+	// SecretFormatTLSTruststore only writes the trust anchors of an AutoTls SecretClass, for clients.
+	SecretFormatTLSTruststore SecretFormat = "tls-truststore"
 )
 
 const (
@@ -61,6 +68,7 @@ type SecretVolumeContext struct {
 	AutoTlsCertRestartBuffer time.Duration `json:"secrets.kubedoop.dev/autoTlsCertRestartBuffer"`
 
 	KerberosServiceNames []string `json:"secrets.kubedoop.dev/kerberosServiceNames"`
+	KerberosPrincipals   []string `json:"secrets.kubedoop.dev/kerberosPrincipals"`
 }
 
 type ListScope string
@@ -114,6 +122,9 @@ func (v SecretVolumeContext) ToMap() map[string]string {
 		out[constants.AnnotationSecretsKerberosServiceNames] =
 			strings.Join(v.KerberosServiceNames, KerberosServiceNamesSplitter)
 	}
+	if len(v.KerberosPrincipals) > 0 {
+		out[AnnotationSecretsKerberosPrincipals] = strings.Join(v.KerberosPrincipals, KerberosPrincipalsSplitter)
+	}
 	if v.TlsPKCS12Password != "" {
 		out[constants.AnnotationSecretsPKCS12Password] = v.TlsPKCS12Password
 	}
@@ -205,6 +216,8 @@ func NewvolumeContextFromMap(parameters map[string]string) (*SecretVolumeContext
 			v.Format = SecretFormat(value)
 		case constants.AnnotationSecretsKerberosServiceNames:
 			v.KerberosServiceNames = strings.Split(value, KerberosServiceNamesSplitter)
+		case AnnotationSecretsKerberosPrincipals:
+			v.KerberosPrincipals = strings.Split(value, KerberosPrincipalsSplitter)
 		case constants.AnnotationSecretsPKCS12Password:
 			v.TlsPKCS12Password = value
 		case constants.AnnotationSecretCertLifeTime:
@@ -51,6 +51,7 @@ func TestSecretVolumeContextToMap(t *testing.T) {
 				Format:                   SecretFormatTLSPEM,
 				TlsPKCS12Password:        testPassword,
 				KerberosServiceNames:     []string{"realm1", "realm2"},
+				KerberosPrincipals:       []string{"${service}/${hostname}", "${serviceAccount}"},
 				AutoTlsCertLifetime:      24 * time.Hour,
 				AutoTlsCertJitterFactor:  0.1,
 				AutoTlsCertRestartBuffer: 5 * time.Minute,
@@ -67,6 +68,7 @@ func TestSecretVolumeContextToMap(t *testing.T) {
 				constants.AnnotationSecretsFormat:               string(SecretFormatTLSPEM),
 				constants.AnnotationSecretsPKCS12Password:       testPassword,
 				constants.AnnotationSecretsKerberosServiceNames: "realm1,realm2",
+				AnnotationSecretsKerberosPrincipals:             "${service}/${hostname},${serviceAccount}",
 				constants.AnnotationSecretCertLifeTime:          "24h0m0s",
 				constants.AnnotationSecretsCertJitterFactor:     "0.100000",
 				constants.AnnotationSecretsCertRestartBuffer:    "5m0s",
@@ -123,6 +125,7 @@ func TestNewvolumeContextFromMap(t *testing.T) {
 				constants.AnnotationSecretsFormat:               string(SecretFormatTLSPEM),
 				constants.AnnotationSecretsPKCS12Password:       testPassword,
 				constants.AnnotationSecretsKerberosServiceNames: "realm1,realm2",
+				AnnotationSecretsKerberosPrincipals:             "${service}/${hostname},${serviceAccount}",
 				constants.AnnotationSecretCertLifeTime:          "24h0m0s",
 				constants.AnnotationSecretsCertJitterFactor:     "0.100000",
 				constants.AnnotationSecretsCertRestartBuffer:    "5m0s",
@@ -144,6 +147,7 @@ func TestNewvolumeContextFromMap(t *testing.T) {
 				Format:                   SecretFormatTLSPEM,
 				TlsPKCS12Password:        testPassword,
 				KerberosServiceNames:     []string{"realm1", "realm2"},
+				KerberosPrincipals:       []string{"${service}/${hostname}", "${serviceAccount}"},
 				AutoTlsCertLifetime:      24 * time.Hour,
 				AutoTlsCertJitterFactor:  0.1,
 				AutoTlsCertRestartBuffer: 5 * time.Minute,